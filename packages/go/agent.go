@@ -0,0 +1,235 @@
+package aikit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type AgentStopReason string
+
+const (
+	AgentStopCompleted     AgentStopReason = "completed"
+	AgentStopMaxIterations AgentStopReason = "max_iterations"
+	AgentStopMaxCost       AgentStopReason = "max_cost"
+)
+
+type AgentEventType string
+
+const (
+	AgentEventStepStart     AgentEventType = "step_start"
+	AgentEventModelResponse AgentEventType = "model_response"
+	AgentEventToolStart     AgentEventType = "tool_start"
+	AgentEventToolEnd       AgentEventType = "tool_end"
+	AgentEventFinish        AgentEventType = "finish"
+)
+
+type AgentToolResult struct {
+	CallID   string        `json:"callId"`
+	Name     string        `json:"name"`
+	Output   string        `json:"output,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+type AgentStep struct {
+	Index       int               `json:"index"`
+	Output      GenerateOutput    `json:"output"`
+	ToolResults []AgentToolResult `json:"toolResults,omitempty"`
+}
+
+type AgentEvent struct {
+	Type       AgentEventType   `json:"type"`
+	Step       int              `json:"step"`
+	Output     *GenerateOutput  `json:"output,omitempty"`
+	Call       *ToolCall        `json:"call,omitempty"`
+	Result     *AgentToolResult `json:"result,omitempty"`
+	StopReason AgentStopReason  `json:"stopReason,omitempty"`
+}
+
+type AgentResult struct {
+	Output     GenerateOutput  `json:"output"`
+	Messages   []Message       `json:"messages"`
+	Steps      []AgentStep     `json:"steps"`
+	Usage      Usage           `json:"usage"`
+	CostUSD    float64         `json:"costUsd,omitempty"`
+	StopReason AgentStopReason `json:"stopReason"`
+}
+
+type Agent struct {
+	Kit             KitAPI
+	Tools           *ToolRegistry
+	MaxIterations   int
+	MaxCostUSD      float64
+	ToolConcurrency int
+	OnEvent         func(AgentEvent)
+
+	emitMu sync.Mutex
+}
+
+func (a *Agent) Run(ctx context.Context, in GenerateInput) (AgentResult, error) {
+	if a.Kit == nil {
+		return AgentResult{}, &KitError{Kind: ErrorValidation, Message: "agent requires a kit"}
+	}
+	maxIterations := a.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = 10
+	}
+	if a.Tools != nil {
+		in.Tools = mergeToolDefinitions(in.Tools, a.Tools.Definitions())
+	}
+	in.Stream = false
+	messages := append([]Message(nil), in.Messages...)
+	result := AgentResult{}
+	for step := 1; ; step++ {
+		if step > maxIterations {
+			result.StopReason = AgentStopMaxIterations
+			break
+		}
+		a.emit(AgentEvent{Type: AgentEventStepStart, Step: step})
+		stepInput := in
+		stepInput.Messages = messages
+		output, err := a.Kit.Generate(ctx, stepInput)
+		if err != nil {
+			result.Messages = messages
+			return result, err
+		}
+		result.Output = output
+		addUsage(&result.Usage, output.Usage)
+		if output.Cost != nil {
+			result.CostUSD = roundUsd(result.CostUSD + output.Cost.TotalCostUSD)
+		}
+		a.emit(AgentEvent{Type: AgentEventModelResponse, Step: step, Output: &output})
+		messages = append(messages, assistantMessage(output))
+		if len(output.ToolCalls) == 0 {
+			result.Steps = append(result.Steps, AgentStep{Index: step, Output: output})
+			result.StopReason = AgentStopCompleted
+			break
+		}
+		// Over budget, the turn's tool calls are not run: they may have side
+		// effects the caller is no longer paying for.
+		if a.MaxCostUSD > 0 && result.CostUSD >= a.MaxCostUSD {
+			result.Steps = append(result.Steps, AgentStep{Index: step, Output: output})
+			result.StopReason = AgentStopMaxCost
+			break
+		}
+		toolResults := a.runTools(ctx, step, output.ToolCalls)
+		if err := ctx.Err(); err != nil {
+			result.Messages = messages
+			return result, err
+		}
+		for _, toolResult := range toolResults {
+			messages = append(messages, toolResultMessage(toolResult))
+		}
+		result.Steps = append(result.Steps, AgentStep{Index: step, Output: output, ToolResults: toolResults})
+	}
+	result.Messages = messages
+	a.emit(AgentEvent{Type: AgentEventFinish, Step: len(result.Steps), StopReason: result.StopReason})
+	return result, nil
+}
+
+func (a *Agent) runTools(ctx context.Context, step int, calls []ToolCall) []AgentToolResult {
+	concurrency := a.ToolConcurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	results := make([]AgentToolResult, len(calls))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for idx := range calls {
+		call := calls[idx]
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(idx int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			a.emit(AgentEvent{Type: AgentEventToolStart, Step: step, Call: &call})
+			results[idx] = a.runTool(ctx, call)
+			a.emit(AgentEvent{Type: AgentEventToolEnd, Step: step, Call: &call, Result: &results[idx]})
+		}(idx)
+	}
+	wg.Wait()
+	return results
+}
+
+func (a *Agent) runTool(ctx context.Context, call ToolCall) (result AgentToolResult) {
+	result = AgentToolResult{CallID: call.ID, Name: call.Name}
+	start := time.Now()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			result.Error = fmt.Sprintf("tool %s panicked: %v", call.Name, recovered)
+		}
+		result.Duration = time.Since(start)
+	}()
+	var tool RegisteredTool
+	var ok bool
+	if a.Tools != nil {
+		tool, ok = a.Tools.Lookup(call.Name)
+	}
+	if !ok {
+		result.Error = fmt.Sprintf("tool %s is not registered", call.Name)
+		return result
+	}
+	output, err := tool.Handler(ctx, call.ArgumentsJSON)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Output = output
+	return result
+}
+
+func (a *Agent) emit(event AgentEvent) {
+	if a.OnEvent == nil {
+		return
+	}
+	a.emitMu.Lock()
+	defer a.emitMu.Unlock()
+	a.OnEvent(event)
+}
+
+func mergeToolDefinitions(base []ToolDefinition, extra []ToolDefinition) []ToolDefinition {
+	seen := make(map[string]struct{}, len(base)+len(extra))
+	merged := make([]ToolDefinition, 0, len(base)+len(extra))
+	for _, list := range [][]ToolDefinition{base, extra} {
+		for _, def := range list {
+			if _, ok := seen[def.Name]; ok {
+				continue
+			}
+			seen[def.Name] = struct{}{}
+			merged = append(merged, def)
+		}
+	}
+	return merged
+}
+
+func assistantMessage(output GenerateOutput) Message {
+	message := Message{Role: "assistant", ToolCalls: output.ToolCalls}
+	if output.Text != "" {
+		message.Content = []ContentPart{{Type: "text", Text: output.Text}}
+	}
+	return message
+}
+
+func toolResultMessage(result AgentToolResult) Message {
+	content := result.Output
+	if result.Error != "" {
+		content = toJSONString(map[string]string{"error": result.Error})
+	}
+	return Message{
+		Role:       "tool",
+		ToolCallID: result.CallID,
+		Name:       result.Name,
+		Content:    []ContentPart{{Type: "text", Text: content}},
+	}
+}
+
+func addUsage(total *Usage, usage *Usage) {
+	if usage == nil {
+		return
+	}
+	total.InputTokens += usage.InputTokens
+	total.OutputTokens += usage.OutputTokens
+	total.TotalTokens += usage.TotalTokens
+}
@@ -0,0 +1,208 @@
+package aikit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+)
+
+type scriptedAdapter struct {
+	mu      sync.Mutex
+	outputs []GenerateOutput
+	inputs  []GenerateInput
+}
+
+func (s *scriptedAdapter) ListModels(ctx context.Context) ([]ModelMetadata, error) {
+	return nil, nil
+}
+
+func (s *scriptedAdapter) Generate(ctx context.Context, in GenerateInput) (GenerateOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inputs = append(s.inputs, in)
+	if len(s.outputs) == 0 {
+		return GenerateOutput{}, errors.New("no scripted output left")
+	}
+	out := s.outputs[0]
+	if len(s.outputs) > 1 {
+		s.outputs = s.outputs[1:]
+	}
+	return out, nil
+}
+
+func (s *scriptedAdapter) GenerateImage(ctx context.Context, in ImageGenerateInput) (ImageGenerateOutput, error) {
+	return ImageGenerateOutput{}, nil
+}
+
+func (s *scriptedAdapter) GenerateMesh(ctx context.Context, in MeshGenerateInput) (MeshGenerateOutput, error) {
+	return MeshGenerateOutput{}, nil
+}
+
+func (s *scriptedAdapter) Transcribe(ctx context.Context, in TranscribeInput) (TranscribeOutput, error) {
+	return TranscribeOutput{}, nil
+}
+
+func (s *scriptedAdapter) Stream(ctx context.Context, in GenerateInput) (<-chan StreamChunk, error) {
+	return nil, errors.New("stream not scripted")
+}
+
+func newScriptedKit(t *testing.T, adapter *scriptedAdapter) *Kit {
+	t.Helper()
+	kit, err := New(Config{Adapters: map[Provider]ProviderAdapter{ProviderOpenAI: adapter}})
+	if err != nil {
+		t.Fatalf("new kit: %v", err)
+	}
+	return kit
+}
+
+func newWeatherRegistry(t *testing.T) *ToolRegistry {
+	t.Helper()
+	registry := NewToolRegistry()
+	type weatherArgs struct {
+		City string `json:"city"`
+	}
+	err := RegisterTypedTool(registry, ToolDefinition{Name: "weather"}, func(ctx context.Context, args weatherArgs) (map[string]string, error) {
+		return map[string]string{"city": args.City, "forecast": "sunny"}, nil
+	})
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	return registry
+}
+
+func TestAgentRunsToolLoopUntilDone(t *testing.T) {
+	adapter := &scriptedAdapter{outputs: []GenerateOutput{
+		{
+			ToolCalls: []ToolCall{
+				{ID: "call_1", Name: "weather", ArgumentsJSON: `{"city":"Paris"}`},
+				{ID: "call_2", Name: "weather", ArgumentsJSON: `{"city":"Rome"}`},
+				{ID: "call_3", Name: "missing", ArgumentsJSON: `{}`},
+			},
+			Usage: &Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+		},
+		{Text: "Both sunny.", Usage: &Usage{InputTokens: 20, OutputTokens: 3, TotalTokens: 23}},
+	}}
+	var events []AgentEvent
+	agent := &Agent{
+		Kit:             newScriptedKit(t, adapter),
+		Tools:           newWeatherRegistry(t),
+		ToolConcurrency: 2,
+		OnEvent: func(event AgentEvent) {
+			events = append(events, event)
+		},
+	}
+	result, err := agent.Run(context.Background(), GenerateInput{
+		Provider: ProviderOpenAI,
+		Model:    "test-model",
+		Messages: []Message{{Role: "user", Content: []ContentPart{{Type: "text", Text: "weather?"}}}},
+	})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if result.StopReason != AgentStopCompleted || result.Output.Text != "Both sunny." {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if result.Usage.TotalTokens != 38 {
+		t.Fatalf("expected aggregated usage, got %+v", result.Usage)
+	}
+	if len(adapter.inputs) != 2 || len(adapter.inputs[0].Tools) != 1 {
+		t.Fatalf("expected registry tools on each call: %+v", adapter.inputs)
+	}
+	second := adapter.inputs[1].Messages
+	if len(second) != 5 {
+		t.Fatalf("expected user, assistant and three tool messages, got %d", len(second))
+	}
+	if second[1].Role != "assistant" || len(second[1].ToolCalls) != 3 {
+		t.Fatalf("expected assistant tool calls to be replayed: %+v", second[1])
+	}
+	var payload map[string]string
+	if err := json.Unmarshal([]byte(second[3].Content[0].Text), &payload); err != nil || payload["city"] != "Rome" {
+		t.Fatalf("expected ordered tool results, got %+v", second[3])
+	}
+	if second[4].ToolCallID != "call_3" || !json.Valid([]byte(second[4].Content[0].Text)) {
+		t.Fatalf("expected unknown tool error to be fed back: %+v", second[4])
+	}
+	if len(result.Steps) != 2 || result.Steps[0].ToolResults[2].Error == "" {
+		t.Fatalf("unexpected steps: %+v", result.Steps)
+	}
+	toolEnds := 0
+	for _, event := range events {
+		if event.Type == AgentEventToolEnd {
+			toolEnds++
+		}
+	}
+	if toolEnds != 3 || events[len(events)-1].Type != AgentEventFinish {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+}
+
+func TestAgentStopsAtLimits(t *testing.T) {
+	loop := GenerateOutput{
+		ToolCalls: []ToolCall{{ID: "call", Name: "weather", ArgumentsJSON: `{"city":"Oslo"}`}},
+		Cost:      &CostBreakdown{TotalCostUSD: 0.4},
+	}
+	adapter := &scriptedAdapter{outputs: []GenerateOutput{loop}}
+	agent := &Agent{Kit: newScriptedKit(t, adapter), Tools: newWeatherRegistry(t), MaxIterations: 3}
+	result, err := agent.Run(context.Background(), GenerateInput{Provider: ProviderOpenAI, Model: "test-model"})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if result.StopReason != AgentStopMaxIterations || len(adapter.inputs) != 3 {
+		t.Fatalf("expected max iterations stop after 3 calls, got %s with %d calls", result.StopReason, len(adapter.inputs))
+	}
+
+	adapter = &scriptedAdapter{outputs: []GenerateOutput{loop}}
+	toolRuns := 0
+	agent = &Agent{Kit: newScriptedKit(t, adapter), Tools: newWeatherRegistry(t), MaxCostUSD: 1, OnEvent: func(event AgentEvent) {
+		if event.Type == AgentEventToolStart {
+			toolRuns++
+		}
+	}}
+	result, err = agent.Run(context.Background(), GenerateInput{Provider: ProviderOpenAI, Model: "test-model"})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if result.StopReason != AgentStopMaxCost || len(adapter.inputs) != 3 {
+		t.Fatalf("expected cost cap stop after 3 calls, got %s with %d calls", result.StopReason, len(adapter.inputs))
+	}
+	if toolRuns != 2 || len(result.Steps) != 3 || len(result.Steps[2].ToolResults) != 0 {
+		t.Fatalf("expected the over-budget turn's tools not to run, got %d runs and %+v", toolRuns, result.Steps)
+	}
+}
+
+func TestToolCallMessagesMapPerProvider(t *testing.T) {
+	messages := []Message{
+		{Role: "user", Content: []ContentPart{{Type: "text", Text: "hi"}}},
+		{Role: "assistant", ToolCalls: []ToolCall{
+			{ID: "a", Name: "weather", ArgumentsJSON: `{"city":"Paris"}`},
+			{ID: "b", Name: "weather", ArgumentsJSON: `{"city":"Rome"}`},
+		}},
+		{Role: "tool", ToolCallID: "a", Name: "weather", Content: []ContentPart{{Type: "text", Text: `{"ok":true}`}}},
+		{Role: "tool", ToolCallID: "b", Name: "weather", Content: []ContentPart{{Type: "text", Text: "plain"}}},
+	}
+
+	chat := mapMessagesToChat(messages)
+	if calls, ok := chat[1]["tool_calls"].([]map[string]interface{}); !ok || len(calls) != 2 {
+		t.Fatalf("expected openai tool_calls on assistant message: %+v", chat[1])
+	}
+
+	_, anthropic := splitAnthropicMessages(messages)
+	if len(anthropic) != 3 {
+		t.Fatalf("expected tool results to share one anthropic turn, got %d turns", len(anthropic))
+	}
+	if blocks := anthropic[2]["content"].([]map[string]interface{}); len(blocks) != 2 {
+		t.Fatalf("expected two tool_result blocks: %+v", blocks)
+	}
+
+	_, gemini := buildGeminiMessages(messages)
+	if len(gemini) != 3 {
+		t.Fatalf("expected tool responses to share one gemini turn, got %d turns", len(gemini))
+	}
+	parts := gemini[2]["parts"].([]map[string]interface{})
+	response := parts[1]["functionResponse"].(map[string]interface{})["response"].(map[string]interface{})
+	if response["content"] != "plain" {
+		t.Fatalf("expected plain text response to be wrapped: %+v", response)
+	}
+}
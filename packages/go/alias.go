@@ -0,0 +1,189 @@
+package aikit
+
+import (
+	"regexp"
+	"strings"
+)
+
+// CanonicalModel is what a requested model name resolves to. ID is the
+// canonical "provider:model" form sent upstream; CatalogID names the curated
+// entry used for metadata and pricing, which differs from Model for catalog
+// aliases, dated snapshots and fine-tunes and is empty when the catalog has
+// no entry. Alias is set when a Config.Aliases entry rewrote the name.
+type CanonicalModel struct {
+	ID        string   `json:"id"`
+	Provider  Provider `json:"provider"`
+	Model     string   `json:"model"`
+	Alias     string   `json:"alias,omitempty"`
+	CatalogID string   `json:"catalogId,omitempty"`
+	Snapshot  string   `json:"snapshot,omitempty"`
+	BaseModel string   `json:"baseModel,omitempty"`
+}
+
+// snapshotSuffix matches the version suffixes providers append to a model
+// family: -2024-08-06, -20241022, @20240620, -12-2025, -0613, -002 and
+// -latest. Dates must be plausible and numbered revisions start with 00, so
+// sizes and versions such as -8192 or -405 stay part of the name.
+var snapshotSuffix = regexp.MustCompile(`(-20\d{2}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01])|[-@]20\d{2}(0[1-9]|1[0-2])(0[1-9]|[12]\d|3[01])|-(0[1-9]|1[0-2])-20\d{2}|-(0[1-9]|1[0-2])(0[1-9]|[12]\d|3[01])|-00\d|-latest)$`)
+
+// canonicalID joins a provider and model the way ModelRecord.ID does.
+func canonicalID(provider Provider, model string) string {
+	return string(provider) + ":" + model
+}
+
+// splitCanonicalID splits "provider:model". Model names may contain colons
+// themselves (ft:..., llama3:8b), so only the first one separates.
+func splitCanonicalID(value string) (Provider, string, bool) {
+	provider, model, ok := strings.Cut(value, ":")
+	if !ok || provider == "" || model == "" {
+		return "", "", false
+	}
+	return Provider(provider), model, true
+}
+
+// resolve maps provider and model through the user's aliases, then finds
+// the curated entry for the result: the entry declaring it as an alias, an
+// exact id, then a fine-tune's base model, then the model without its
+// snapshot suffix. Catalog aliases are names the provider serves itself, so
+// they only pick metadata and never change the model sent upstream. A bare
+// alias key applies to any provider; "provider:alias" keys only to that
+// provider. An empty provider takes the alias target's.
+func (c *catalog) resolve(aliases map[string]string, provider Provider, model string) (CanonicalModel, CuratedModel, bool) {
+	model = strings.TrimSpace(model)
+	if provider != "" {
+		model = normalizeModelID(provider, model)
+		model = strings.TrimPrefix(model, string(provider)+":")
+	}
+	resolved := CanonicalModel{Provider: provider, Model: model}
+	if target, ok := userAlias(aliases, provider, model); ok {
+		resolved.Alias = model
+		resolved.Provider, resolved.Model = target.Provider, target.Model
+	}
+	lookup := resolved.Model
+	if target, ok := c.alias(resolved.Provider, resolved.Model); ok {
+		resolved.Provider = target.Provider
+		lookup = target.Model
+	}
+	resolved.ID = resolved.Model
+	if resolved.Provider != "" {
+		resolved.ID = canonicalID(resolved.Provider, resolved.Model)
+	}
+
+	if base, ok := fineTuneBase(lookup); ok {
+		resolved.BaseModel = base
+		lookup = base
+	}
+	for {
+		if curated, ok := c.exact(resolved.Provider, lookup); ok {
+			resolved.CatalogID = curated.ID
+			return resolved, curated, true
+		}
+		suffix := snapshotSuffix.FindString(lookup)
+		if suffix == "" {
+			return resolved, CuratedModel{}, false
+		}
+		lookup = strings.TrimSuffix(lookup, suffix)
+		if resolved.Snapshot == "" {
+			resolved.Snapshot = strings.TrimLeft(suffix, "-@")
+		}
+	}
+}
+
+func userAlias(aliases map[string]string, provider Provider, model string) (CanonicalModel, bool) {
+	target, ok := "", false
+	if provider != "" {
+		target, ok = aliases[canonicalID(provider, model)]
+	}
+	if !ok {
+		target, ok = aliases[model]
+	}
+	if !ok {
+		return CanonicalModel{}, false
+	}
+	targetProvider, targetModel, ok := splitCanonicalID(target)
+	if !ok || (provider != "" && targetProvider != provider) {
+		return CanonicalModel{}, false
+	}
+	return CanonicalModel{Provider: targetProvider, Model: targetModel}, true
+}
+
+// alias finds the catalog entry that declares name as an alias.
+func (c *catalog) alias(provider Provider, name string) (CanonicalModel, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, model := range c.models {
+		if provider != "" && model.Provider != provider {
+			continue
+		}
+		for _, alias := range model.Aliases {
+			if alias == name {
+				return CanonicalModel{Provider: model.Provider, Model: model.ID}, true
+			}
+		}
+	}
+	return CanonicalModel{}, false
+}
+
+func (c *catalog) exact(provider Provider, id string) (CuratedModel, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if idx := c.indexOf(provider, id); idx >= 0 {
+		return c.models[idx], true
+	}
+	return CuratedModel{}, false
+}
+
+// fineTuneBase extracts the base model of an OpenAI-style fine-tune id,
+// ft:<base>:<org>:<suffix>:<id>.
+func fineTuneBase(model string) (string, bool) {
+	rest, ok := strings.CutPrefix(model, "ft:")
+	if !ok {
+		return "", false
+	}
+	base, _, _ := strings.Cut(rest, ":")
+	return base, base != ""
+}
+
+// aliasesFor lists the user and catalog aliases that resolve to model.
+func (c *catalog) aliasesFor(aliases map[string]string, provider Provider, model string) []string {
+	var names []string
+	if curated, ok := c.exact(provider, model); ok {
+		names = append(names, curated.Aliases...)
+	}
+	id := canonicalID(provider, model)
+	for name, target := range aliases {
+		if target == id {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ResolveModel reports what a model name resolves to under the Kit's
+// aliases and curated catalog. provider may be empty when model is an alias
+// or a "provider:model" id for a configured provider.
+func (h *Kit) ResolveModel(provider Provider, model string) CanonicalModel {
+	return h.resolveModel(h.current(), provider, model)
+}
+
+func (h *Kit) resolveModel(state *kitState, provider Provider, model string) CanonicalModel {
+	if provider == "" {
+		if p, m, ok := splitCanonicalID(model); ok && state.adapters[p] != nil {
+			provider, model = p, m
+		}
+	}
+	resolved, _, _ := h.catalog.resolve(state.config.Aliases, provider, model)
+	return resolved
+}
+
+// resolveInput rewrites a request named by a Config.Aliases entry or as
+// "provider:model" to the canonical provider and model before it is routed.
+// Catalog aliases, snapshots and fine-tunes keep the requested model name.
+func (h *Kit) resolveInput(state *kitState, in GenerateInput) GenerateInput {
+	resolved := h.resolveModel(state, in.Provider, in.Model)
+	if resolved.Provider == "" {
+		return in
+	}
+	in.Provider, in.Model = resolved.Provider, resolved.Model
+	return in
+}
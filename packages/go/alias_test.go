@@ -0,0 +1,98 @@
+package aikit
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func aliasKit(t *testing.T, adapter ProviderAdapter) *Kit {
+	t.Helper()
+	kit, err := New(Config{
+		Adapters: map[Provider]ProviderAdapter{ProviderOpenAI: adapter, ProviderAnthropic: adapter, ProviderXAI: adapter, ProviderGoogle: adapter},
+		Aliases: map[string]string{
+			"sonnet-latest": "anthropic:claude-sonnet-4-5",
+			"openai:fast":   "openai:gpt-3.5-turbo",
+		},
+	})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	return kit
+}
+
+func TestResolveModel(t *testing.T) {
+	kit := aliasKit(t, &scriptedAdapter{})
+	cases := []struct {
+		provider Provider
+		model    string
+		want     CanonicalModel
+	}{
+		// Catalog aliases pick the entry but keep the provider's own name.
+		{ProviderXAI, "grok-4", CanonicalModel{ID: "xai:grok-4", Provider: ProviderXAI, Model: "grok-4", CatalogID: "grok-4-0709"}},
+		{"", "grok-4", CanonicalModel{ID: "xai:grok-4", Provider: ProviderXAI, Model: "grok-4", CatalogID: "grok-4-0709"}},
+		{"", "sonnet-latest", CanonicalModel{ID: "anthropic:claude-sonnet-4-5", Provider: ProviderAnthropic, Model: "claude-sonnet-4-5", Alias: "sonnet-latest", CatalogID: "claude-sonnet-4-5-20250929"}},
+		{ProviderOpenAI, "fast", CanonicalModel{ID: "openai:gpt-3.5-turbo", Provider: ProviderOpenAI, Model: "gpt-3.5-turbo", Alias: "fast", CatalogID: "gpt-3.5-turbo"}},
+		{ProviderAnthropic, "fast", CanonicalModel{ID: "anthropic:fast", Provider: ProviderAnthropic, Model: "fast"}},
+		{"", "openai:gpt-4", CanonicalModel{ID: "openai:gpt-4", Provider: ProviderOpenAI, Model: "gpt-4", CatalogID: "gpt-4"}},
+		{ProviderOpenAI, "gpt-4-0613", CanonicalModel{ID: "openai:gpt-4-0613", Provider: ProviderOpenAI, Model: "gpt-4-0613", CatalogID: "gpt-4", Snapshot: "0613"}},
+		// A different family that merely shares a prefix is not priced as gpt-4.
+		{ProviderOpenAI, "gpt-4o", CanonicalModel{ID: "openai:gpt-4o", Provider: ProviderOpenAI, Model: "gpt-4o"}},
+		{ProviderOpenAI, "ft:gpt-3.5-turbo-0125:acme:support:abc123", CanonicalModel{
+			ID: "openai:ft:gpt-3.5-turbo-0125:acme:support:abc123", Provider: ProviderOpenAI, Model: "ft:gpt-3.5-turbo-0125:acme:support:abc123",
+			CatalogID: "gpt-3.5-turbo", Snapshot: "0125", BaseModel: "gpt-3.5-turbo-0125",
+		}},
+		{ProviderGoogle, "google/gemini-2.0-flash-001", CanonicalModel{ID: "google:gemini-2.0-flash-001", Provider: ProviderGoogle, Model: "gemini-2.0-flash-001", CatalogID: "gemini-2.0-flash", Snapshot: "001"}},
+		{"", "claude-sonnet-4-5", CanonicalModel{ID: "anthropic:claude-sonnet-4-5", Provider: ProviderAnthropic, Model: "claude-sonnet-4-5", CatalogID: "claude-sonnet-4-5-20250929"}},
+		// Floating upstream names are not pinned to whichever model they once meant.
+		{ProviderGoogle, "gemini-flash-latest", CanonicalModel{ID: "google:gemini-flash-latest", Provider: ProviderGoogle, Model: "gemini-flash-latest", Snapshot: "latest"}},
+		// Numbers that are not dates or 00n revisions are part of the name.
+		{ProviderOpenAI, "gpt-4-8192", CanonicalModel{ID: "openai:gpt-4-8192", Provider: ProviderOpenAI, Model: "gpt-4-8192"}},
+		{ProviderOpenAI, "gpt-4-1399", CanonicalModel{ID: "openai:gpt-4-1399", Provider: ProviderOpenAI, Model: "gpt-4-1399"}},
+		{ProviderOpenAI, "gpt-4-405", CanonicalModel{ID: "openai:gpt-4-405", Provider: ProviderOpenAI, Model: "gpt-4-405"}},
+		{ProviderOpenAI, "gpt-4-13-2025", CanonicalModel{ID: "openai:gpt-4-13-2025", Provider: ProviderOpenAI, Model: "gpt-4-13-2025"}},
+		{ProviderOpenAI, "gpt-4-20241399", CanonicalModel{ID: "openai:gpt-4-20241399", Provider: ProviderOpenAI, Model: "gpt-4-20241399"}},
+		{ProviderGoogle, "gemini-2.0-flash-123", CanonicalModel{ID: "google:gemini-2.0-flash-123", Provider: ProviderGoogle, Model: "gemini-2.0-flash-123"}},
+	}
+	for _, tc := range cases {
+		if got := kit.ResolveModel(tc.provider, tc.model); got != tc.want {
+			t.Errorf("ResolveModel(%q, %q) = %+v, want %+v", tc.provider, tc.model, got, tc.want)
+		}
+	}
+}
+
+func TestGenerateUsesResolvedModel(t *testing.T) {
+	adapter := &scriptedAdapter{outputs: []GenerateOutput{{Text: "ok", Usage: &Usage{InputTokens: 1_000_000}}}}
+	kit := aliasKit(t, adapter)
+	out, err := kit.Generate(context.Background(), GenerateInput{Model: "grok-4"})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if in := adapter.inputs[0]; in.Provider != ProviderXAI || in.Model != "grok-4" {
+		t.Fatalf("expected the catalog alias upstream as requested, got %s/%s", in.Provider, in.Model)
+	}
+	if out.Cost == nil || out.Cost.PricingPerMillion.Input != 3 {
+		t.Fatalf("expected grok-4-0709 pricing, got %+v", out.Cost)
+	}
+	out, err = kit.Generate(context.Background(), GenerateInput{Provider: ProviderOpenAI, Model: "gpt-4o"})
+	if err != nil || out.Cost != nil {
+		t.Fatalf("gpt-4o has no catalog entry and should not be priced: %+v %v", out.Cost, err)
+	}
+
+	_, err = New(Config{Adapters: map[Provider]ProviderAdapter{ProviderOpenAI: adapter}, Aliases: map[string]string{"fast": "gpt-4o-mini"}})
+	var cfgErr *ConfigError
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("expected an alias target problem, got %v", err)
+	}
+}
+
+func TestRouterMatchesAliases(t *testing.T) {
+	models := []ModelRecord{
+		{ID: "xai:grok-3", ProviderModelID: "grok-3", Availability: ModelAvailability{Entitled: true}},
+		{ID: "xai:grok-4-0709", ProviderModelID: "grok-4-0709", Aliases: []string{"grok-4"}, Availability: ModelAvailability{Entitled: true}},
+	}
+	resolved, err := (&ModelRouter{}).Resolve(models, ModelResolutionRequest{PreferredModels: []string{"grok-4"}})
+	if err != nil || resolved.Primary.ID != "xai:grok-4-0709" {
+		t.Fatalf("expected the aliased model, got %+v %v", resolved.Primary, err)
+	}
+}
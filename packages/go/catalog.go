@@ -0,0 +1,227 @@
+package aikit
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"sync"
+)
+
+// The curated catalogs live in the repository's top-level models directory;
+// this copy is what gets embedded. Run go generate after changing them.
+// scraped_models.json is rewritten by the refresh script, so hand-maintained
+// entries and overrides go in curated_models.json next to it.
+//go:generate rm -rf models
+//go:generate mkdir models
+//go:generate cp -R ../../models/anthropic ../../models/google ../../models/openai ../../models/xai models/
+
+//go:embed models/*/scraped_models.json models/*/curated_models.json
+var embeddedCatalog embed.FS
+
+const (
+	catalogFileName = "scraped_models.json"
+	curatedFileName = "curated_models.json"
+)
+
+// CuratedModel is one catalog entry: the display names, capabilities,
+// limits and prices that provider model listings do not report. Capabilities
+// left out of an entry stay unknown.
+type CuratedModel struct {
+	ID              string            `json:"id"`
+	Aliases         []string          `json:"aliases,omitempty"`
+	DisplayName     string            `json:"displayName"`
+	Provider        Provider          `json:"provider"`
+	Family          string            `json:"family"`
+	Capabilities    ModelCapabilities `json:"capabilities"`
+	ContextWindow   int               `json:"contextWindow"`
+	MaxOutputTokens int               `json:"maxOutputTokens,omitempty"`
+	TokenPrices     *TokenPrices      `json:"tokenPrices"`
+	Deprecated      bool              `json:"deprecated"`
+	InPreview       bool              `json:"inPreview"`
+}
+
+type catalog struct {
+	mu     sync.RWMutex
+	models []CuratedModel
+}
+
+var embeddedOnce sync.Once
+var embeddedModels []CuratedModel
+var embeddedErr error
+
+// newCatalog starts from the embedded catalog and merges each override in
+// order. A broken embedded catalog fails every call rather than leaving the
+// Kit without pricing.
+func newCatalog(overrides ...fs.FS) (*catalog, error) {
+	embeddedOnce.Do(func() {
+		embeddedModels, embeddedErr = loadEmbeddedCatalog()
+	})
+	if embeddedErr != nil {
+		return nil, embeddedErr
+	}
+	c := &catalog{models: make([]CuratedModel, len(embeddedModels))}
+	copy(c.models, embeddedModels)
+	for _, fsys := range overrides {
+		if err := c.merge(fsys); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func loadEmbeddedCatalog() ([]CuratedModel, error) {
+	sub, err := fs.Sub(embeddedCatalog, "models")
+	if err != nil {
+		return nil, fmt.Errorf("embedded catalog: %w", err)
+	}
+	base := &catalog{}
+	if err := base.merge(sub); err != nil {
+		return nil, fmt.Errorf("embedded catalog: %w", err)
+	}
+	return base.models, nil
+}
+
+// merge reads <provider>/scraped_models.json and then
+// <provider>/curated_models.json from every directory at the root of fsys.
+// An entry matching an existing provider and id is decoded on top of it, so
+// overrides only need the fields they change. The catalog is only replaced
+// once every file has merged cleanly.
+func (c *catalog) merge(fsys fs.FS) error {
+	dirs, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	staged := &catalog{models: slices.Clone(c.models)}
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		for _, file := range []string{catalogFileName, curatedFileName} {
+			if err := staged.mergeFile(fsys, dir.Name(), file); err != nil {
+				return err
+			}
+		}
+	}
+	c.models = staged.models
+	return nil
+}
+
+// mergeFile merges one provider's catalog file into c; a missing file is
+// skipped.
+func (c *catalog) mergeFile(fsys fs.FS, provider, file string) error {
+	name := path.Join(provider, file)
+	raw, err := fs.ReadFile(fsys, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var entries []json.RawMessage
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return fmt.Errorf("catalog %s: %w", name, err)
+	}
+	for _, entry := range entries {
+		var model CuratedModel
+		if err := json.Unmarshal(entry, &model); err != nil {
+			return fmt.Errorf("catalog %s: %w", name, err)
+		}
+		if model.Provider == "" {
+			model.Provider = Provider(provider)
+		}
+		idx := c.indexOf(model.Provider, model.ID)
+		if idx < 0 {
+			c.models = append(c.models, model)
+			continue
+		}
+		merged := c.models[idx]
+		merged.Aliases = slices.Clone(merged.Aliases)
+		merged.Capabilities = merged.Capabilities.clone()
+		if merged.TokenPrices != nil {
+			prices := *merged.TokenPrices
+			merged.TokenPrices = &prices
+		}
+		if err := json.Unmarshal(entry, &merged); err != nil {
+			return fmt.Errorf("catalog %s: %w", name, err)
+		}
+		merged.Provider = model.Provider
+		c.models[idx] = merged
+	}
+	return nil
+}
+
+// clone copies every known capability, so decoding an override on top of
+// the result cannot write through to the entry it came from.
+func (c ModelCapabilities) clone() ModelCapabilities {
+	return ModelCapabilities{}.withKnown(c)
+}
+
+// withKnown returns c with every capability other knows replaced by a copy
+// of other's value.
+func (c ModelCapabilities) withKnown(other ModelCapabilities) ModelCapabilities {
+	fields := []struct{ dst, src **bool }{
+		{&c.Text, &other.Text},
+		{&c.Vision, &other.Vision},
+		{&c.Image, &other.Image},
+		{&c.AudioIn, &other.AudioIn},
+		{&c.AudioOut, &other.AudioOut},
+		{&c.ToolUse, &other.ToolUse},
+		{&c.JSONMode, &other.JSONMode},
+		{&c.StructuredOutput, &other.StructuredOutput},
+		{&c.Streaming, &other.Streaming},
+		{&c.Batch, &other.Batch},
+		{&c.Reasoning, &other.Reasoning},
+	}
+	for _, field := range fields {
+		if *field.src != nil {
+			*field.dst = boolPtr(**field.src)
+		}
+	}
+	return c
+}
+
+func boolPtr(value bool) *bool {
+	return &value
+}
+
+// isTrue reports whether a capability is known to be supported.
+func isTrue(value *bool) bool {
+	return value != nil && *value
+}
+
+func (c *catalog) indexOf(provider Provider, id string) int {
+	for i := range c.models {
+		if c.models[i].Provider == provider && c.models[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// MergeCatalog merges curated model entries into the Kit's catalog at
+// runtime. fsys uses the layout of the repository's models directory,
+// <provider>/scraped_models.json and curated_models.json; entries matching an existing provider and
+// id only replace the fields they set. Use os.DirFS for a directory.
+func (h *Kit) MergeCatalog(fsys fs.FS) error {
+	return h.catalog.merge(fsys)
+}
+
+// catalogOverrides returns the configured overrides in the order they are
+// merged.
+func (c Config) catalogOverrides() []fs.FS {
+	var overrides []fs.FS
+	if c.Catalog != nil {
+		overrides = append(overrides, c.Catalog)
+	}
+	if c.CatalogDir != "" {
+		overrides = append(overrides, os.DirFS(c.CatalogDir))
+	}
+	return overrides
+}
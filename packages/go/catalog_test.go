@@ -0,0 +1,157 @@
+package aikit
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestEmbeddedCatalogMatchesSource(t *testing.T) {
+	sources, err := filepath.Glob("../../models/*/" + catalogFileName)
+	if err != nil || len(sources) == 0 {
+		t.Skipf("source catalogs not available: %v", err)
+	}
+	curated, _ := filepath.Glob("../../models/*/" + curatedFileName)
+	for _, source := range append(sources, curated...) {
+		want, err := os.ReadFile(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := embeddedCatalog.ReadFile("models/" + filepath.Base(filepath.Dir(source)) + "/" + filepath.Base(source))
+		if err != nil || !bytes.Equal(got, want) {
+			t.Fatalf("embedded copy of %s is stale; run go generate", source)
+		}
+	}
+}
+
+func TestEmbeddedCatalogParses(t *testing.T) {
+	models, err := loadEmbeddedCatalog()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(models) == 0 {
+		t.Fatalf("expected curated models in the embedded catalog")
+	}
+	for _, model := range models {
+		if model.ID == "" || model.Provider == "" {
+			t.Fatalf("entry without an id or provider: %+v", model)
+		}
+	}
+}
+
+func catalogKit(t *testing.T, config Config) *Kit {
+	t.Helper()
+	config.Adapters = map[Provider]ProviderAdapter{ProviderOpenAI: &scriptedAdapter{outputs: []GenerateOutput{{Usage: &Usage{InputTokens: 1_000_000, OutputTokens: 1_000_000}}}}}
+	kit, err := New(config)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	return kit
+}
+
+func generatedCost(t *testing.T, kit *Kit, model string) float64 {
+	t.Helper()
+	out, err := kit.Generate(context.Background(), GenerateInput{Provider: ProviderOpenAI, Model: model})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if out.Cost == nil {
+		return 0
+	}
+	return out.Cost.TotalCostUSD
+}
+
+func TestCatalogOverridesMergeFields(t *testing.T) {
+	overrides := fstest.MapFS{
+		"openai/scraped_models.json": {Data: []byte(`[
+  {"id": "gpt-4", "tokenPrices": {"input": 1, "output": 2}},
+  {"id": "ft-support", "displayName": "Support fine-tune", "tokenPrices": {"input": 3, "output": 4}}
+]`)},
+	}
+	kit := catalogKit(t, Config{Catalog: overrides})
+	if cost := generatedCost(t, kit, "gpt-4"); cost != 3 {
+		t.Fatalf("expected the overridden price, got %v", cost)
+	}
+	if cost := generatedCost(t, kit, "ft-support-2024-06-01"); cost != 7 {
+		t.Fatalf("expected the added model's price, got %v", cost)
+	}
+	merged, ok := kit.catalog.exact(ProviderOpenAI, "gpt-4")
+	if !ok || merged.DisplayName != "GPT-4" || merged.ContextWindow != 8192 {
+		t.Fatalf("override should keep the fields it does not set: %+v", merged)
+	}
+	if cost := generatedCost(t, catalogKit(t, Config{}), "gpt-4"); cost != 0.09 {
+		t.Fatalf("overrides leaked into another Kit's catalog: %v", cost)
+	}
+
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "openai"), 0o755)
+	os.WriteFile(filepath.Join(dir, "openai", catalogFileName), []byte(`[{"id": "gpt-4", "tokenPrices": {"input": 5, "output": 5}}]`), 0o600)
+	if err := kit.MergeCatalog(os.DirFS(dir)); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if cost := generatedCost(t, kit, "gpt-4"); cost != 10 {
+		t.Fatalf("expected the runtime merge to apply, got %v", cost)
+	}
+
+	_, err := New(Config{OpenAI: &OpenAIConfig{APIKey: "k"}, Catalog: fstest.MapFS{"openai/scraped_models.json": {Data: []byte(`{`)}}})
+	if err == nil || !strings.Contains(err.Error(), "openai/scraped_models.json") {
+		t.Fatalf("expected a catalog parse error, got %v", err)
+	}
+}
+
+func TestCatalogMergeIsolatesAndRollsBack(t *testing.T) {
+	const sonnet = "claude-sonnet-4-5-20250929"
+	embedded, ok := catalogKit(t, Config{}).catalog.exact(ProviderAnthropic, sonnet)
+	if !ok || len(embedded.Aliases) == 0 {
+		t.Fatalf("expected %s with aliases in the embedded catalog", sonnet)
+	}
+	kit := catalogKit(t, Config{Catalog: fstest.MapFS{
+		"anthropic/scraped_models.json": {Data: []byte(`[{"id": "` + sonnet + `", "aliases": ["sonnet"]}]`)},
+	}})
+	if merged, _ := kit.catalog.exact(ProviderAnthropic, sonnet); strings.Join(merged.Aliases, ",") != "sonnet" {
+		t.Fatalf("expected the override's aliases, got %v", merged.Aliases)
+	}
+	if other, _ := catalogKit(t, Config{}).catalog.exact(ProviderAnthropic, sonnet); strings.Join(other.Aliases, ",") != strings.Join(embedded.Aliases, ",") {
+		t.Fatalf("override aliases leaked into the embedded catalog: %v", other.Aliases)
+	}
+
+	err := kit.MergeCatalog(fstest.MapFS{
+		"anthropic/scraped_models.json": {Data: []byte(`[{"id": "` + sonnet + `", "displayName": "Renamed"}]`)},
+		"openai/scraped_models.json":    {Data: []byte(`{`)},
+	})
+	if err == nil {
+		t.Fatalf("expected the broken openai catalog to fail the merge")
+	}
+	if merged, _ := kit.catalog.exact(ProviderAnthropic, sonnet); merged.DisplayName == "Renamed" {
+		t.Fatalf("a failed merge left part of its entries applied")
+	}
+}
+
+func TestCostsInBuiltBinary(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a binary")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not available")
+	}
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "costcheck")
+	// -trimpath drops source paths from the binary, so the catalog can only
+	// come from the embedded copy.
+	build := exec.Command(goTool, "build", "-trimpath", "-o", binary, "./testdata/costcheck")
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("build: %v\n%s", err, out)
+	}
+	run := exec.Command(binary)
+	run.Dir = dir
+	out, err := run.CombinedOutput()
+	if err != nil || strings.TrimSpace(string(out)) != "9e-05" {
+		t.Fatalf("expected a cost from the embedded catalog, got %q %v", out, err)
+	}
+}
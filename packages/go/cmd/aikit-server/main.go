@@ -0,0 +1,197 @@
+// Command aikit-server serves the ai-kit HTTP API described in
+// docs/http-api.md over a Kit built from a config file and the environment.
+//
+//	aikit-server -config aikit.yaml -addr :8080
+package main
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+
+	aikit "github.com/Volpestyle/ai-kit/packages/go"
+	"github.com/Volpestyle/ai-kit/packages/go/internal/cmdconfig"
+)
+
+//go:generate cp ../../../../servers/openapi.yaml openapi.yaml
+
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+func main() {
+	configPath := flag.String("config", os.Getenv("AI_KIT_CONFIG"), "path to a YAML or JSON config file")
+	addr := flag.String("addr", "", "listen address (overrides server.addr)")
+	flag.Parse()
+
+	cfg, err := cmdconfig.Load(*configPath)
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+	if *addr != "" {
+		cfg.Server.Addr = *addr
+	}
+	cfg.Kit.OnReload = logReload
+	kit, err := aikit.New(cfg.Kit)
+	if err != nil {
+		log.Fatalf("create kit: %v", err)
+	}
+	go reloadOnHangup(kit, *configPath)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := run(ctx, kit, cfg.Server); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// reloadOnHangup re-reads the config on SIGHUP and swaps the Kit's
+// providers and keys in place. Server settings only change on restart.
+func reloadOnHangup(kit *aikit.Kit, configPath string) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	for range hangup {
+		cfg, err := cmdconfig.Load(configPath)
+		if err != nil {
+			log.Printf("reload: %v", err)
+			continue
+		}
+		kit.Reload(cfg.Kit)
+	}
+}
+
+func logReload(event aikit.ReloadEvent) {
+	if event.Err != nil {
+		log.Printf("reload rejected: %v", event.Err)
+		return
+	}
+	log.Printf("reloaded config: added %v, removed %v, changed %v, %d registry entries invalidated",
+		event.Added, event.Removed, event.Changed, event.Invalidated)
+}
+
+// run serves until ctx is cancelled, then drains in-flight requests for up
+// to ShutdownTimeout.
+func run(ctx context.Context, kit aikit.KitAPI, cfg cmdconfig.Server) error {
+	var ready atomic.Bool
+	srv := &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           newHandler(kit, cfg, &ready),
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+	listener, err := net.Listen("tcp", cfg.Addr)
+	if err != nil {
+		return err
+	}
+	log.Printf("aikit-server listening on %s", listener.Addr())
+	errs := make(chan error, 1)
+	go func() { errs <- srv.Serve(listener) }()
+	ready.Store(true)
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+	ready.Store(false)
+	log.Printf("shutting down (waiting up to %s)", cfg.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func newHandler(kit aikit.KitAPI, cfg cmdconfig.Server, ready *atomic.Bool) http.Handler {
+	mux := http.NewServeMux()
+	bounded := func(h http.Handler) http.Handler {
+		return http.TimeoutHandler(h, cfg.RequestTimeout, `{"error":{"kind":"timeout","message":"request timed out"}}`)
+	}
+
+	mux.Handle("GET /provider-models", aikit.ModelsHandler(kit, nil))
+	mux.Handle("POST /generate", bounded(aikit.GenerateHandler(kit)))
+	mux.Handle("POST /image", bounded(aikit.ImageHandler(kit)))
+	mux.Handle("POST /mesh", bounded(aikit.MeshHandler(kit)))
+	mux.Handle("POST /transcribe", bounded(aikit.TranscribeHandler(kit)))
+
+	sseOpts := &aikit.SSEHandlerOptions{}
+	if cfg.SSEReplayTTL > 0 {
+		sseOpts.Replay = aikit.NewSSEReplayBuffer(cfg.SSEReplayTTL)
+	}
+	mux.Handle("POST /generate/stream", aikit.GenerateSSEHandlerWithOptions(kit, sseOpts))
+	mux.Handle("GET /generate/ws", aikit.GenerateWebSocketHandler(kit, &aikit.WebSocketHandlerOptions{
+		OriginPatterns: cfg.WebSocketOrigins,
+	}))
+
+	mux.Handle("POST /v1/chat/completions", boundedUnlessStreaming(aikit.OpenAIChatCompletionsHandler(kit), bounded))
+	mux.Handle("GET /v1/models", aikit.OpenAIModelsHandler(kit))
+	mux.Handle("POST /v1/messages", boundedUnlessStreaming(aikit.AnthropicMessagesHandler(kit), bounded))
+
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok"}`))
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if !ready.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"status":"unavailable"}`))
+			return
+		}
+		w.Write([]byte(`{"status":"ready"}`))
+	})
+	mux.HandleFunc("GET /openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Write(openAPISpec)
+	})
+	return limitBody(mux, cfg.MaxBodyBytes)
+}
+
+// boundedUnlessStreaming applies the request timeout to the proxy routes'
+// non-streaming requests. Both wire formats choose streaming with a "stream"
+// field in the body, which is read here and handed on untouched.
+func boundedUnlessStreaming(h http.Handler, bounded func(http.Handler) http.Handler) http.Handler {
+	timed := bounded(h)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		// On a read error the rest of the body fails the same way, so the
+		// handler still reports it in its own format.
+		r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+		var peek struct {
+			Stream bool `json:"stream"`
+		}
+		if err == nil && json.Unmarshal(body, &peek) == nil && peek.Stream {
+			h.ServeHTTP(w, r)
+			return
+		}
+		timed.ServeHTTP(w, r)
+	})
+}
+
+// limitBody rejects declared oversize bodies up front and caps the rest, so
+// a chunked upload fails its JSON decode instead of exhausting memory.
+func limitBody(next http.Handler, limit int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > limit {
+			http.Error(w, "request body exceeds "+strconv.FormatInt(limit, 10)+" bytes", http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	aikit "github.com/Volpestyle/ai-kit/packages/go"
+	"github.com/Volpestyle/ai-kit/packages/go/internal/cmdconfig"
+)
+
+type stubKit struct{}
+
+func (stubKit) ListModels(ctx context.Context, opts *aikit.ListModelsOptions) ([]aikit.ModelMetadata, error) {
+	return []aikit.ModelMetadata{{ID: "gpt-4o-mini", Provider: aikit.ProviderOpenAI}}, nil
+}
+
+func (stubKit) Generate(ctx context.Context, in aikit.GenerateInput) (aikit.GenerateOutput, error) {
+	return aikit.GenerateOutput{Text: "hello"}, nil
+}
+
+func (stubKit) GenerateImage(ctx context.Context, in aikit.ImageGenerateInput) (aikit.ImageGenerateOutput, error) {
+	return aikit.ImageGenerateOutput{}, nil
+}
+
+func (stubKit) GenerateMesh(ctx context.Context, in aikit.MeshGenerateInput) (aikit.MeshGenerateOutput, error) {
+	return aikit.MeshGenerateOutput{}, nil
+}
+
+func (stubKit) Transcribe(ctx context.Context, in aikit.TranscribeInput) (aikit.TranscribeOutput, error) {
+	return aikit.TranscribeOutput{}, nil
+}
+
+func (stubKit) StreamGenerate(ctx context.Context, in aikit.GenerateInput) (<-chan aikit.StreamChunk, error) {
+	ch := make(chan aikit.StreamChunk, 1)
+	ch <- aikit.StreamChunk{Type: aikit.StreamChunkMessageEnd}
+	close(ch)
+	return ch, nil
+}
+
+// slowKit answers streams only after a delay and never finishes a
+// non-streaming generation before its context ends.
+type slowKit struct {
+	stubKit
+	delay time.Duration
+}
+
+func (k slowKit) Generate(ctx context.Context, in aikit.GenerateInput) (aikit.GenerateOutput, error) {
+	<-ctx.Done()
+	return aikit.GenerateOutput{}, ctx.Err()
+}
+
+func (k slowKit) StreamGenerate(ctx context.Context, in aikit.GenerateInput) (<-chan aikit.StreamChunk, error) {
+	time.Sleep(k.delay)
+	return k.stubKit.StreamGenerate(ctx, in)
+}
+
+func testServer(t *testing.T, ready bool) *httptest.Server {
+	t.Helper()
+	cfg := cmdconfig.Server{RequestTimeout: time.Second, MaxBodyBytes: 1024}
+	var flag atomic.Bool
+	flag.Store(ready)
+	server := httptest.NewServer(newHandler(stubKit{}, cfg, &flag))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestServerMountsDocumentedRoutes(t *testing.T) {
+	server := testServer(t, true)
+	cases := []struct {
+		method, path, body string
+		want               string
+	}{
+		{http.MethodGet, "/provider-models", "", `"gpt-4o-mini"`},
+		{http.MethodPost, "/generate", `{"provider":"openai","model":"gpt-4o-mini"}`, `"hello"`},
+		{http.MethodPost, "/generate/stream", `{"provider":"openai","model":"gpt-4o-mini"}`, "event: done"},
+		{http.MethodGet, "/v1/models", "", `"openai/gpt-4o-mini"`},
+		{http.MethodPost, "/v1/chat/completions", `{"model":"openai/gpt-4o-mini","messages":[]}`, `"chat.completion"`},
+		{http.MethodPost, "/v1/messages", `{"model":"claude","messages":[]}`, `"message"`},
+		{http.MethodGet, "/healthz", "", `"ok"`},
+		{http.MethodGet, "/readyz", "", `"ready"`},
+		{http.MethodGet, "/openapi.yaml", "", "openapi: 3.1.0"},
+	}
+	for _, tc := range cases {
+		req, _ := http.NewRequest(tc.method, server.URL+tc.path, strings.NewReader(tc.body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", tc.method, tc.path, err)
+		}
+		var buf bytes.Buffer
+		buf.ReadFrom(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || !strings.Contains(buf.String(), tc.want) {
+			t.Fatalf("%s %s: status %d body %s", tc.method, tc.path, resp.StatusCode, buf.String())
+		}
+	}
+}
+
+func TestProxyRoutesTimeOutUnlessStreaming(t *testing.T) {
+	cfg := cmdconfig.Server{RequestTimeout: 50 * time.Millisecond, MaxBodyBytes: 1024}
+	var ready atomic.Bool
+	server := httptest.NewServer(newHandler(slowKit{delay: 150 * time.Millisecond}, cfg, &ready))
+	defer server.Close()
+	for _, tc := range []struct{ path, model string }{
+		{"/v1/chat/completions", "openai/gpt-4o-mini"},
+		{"/v1/messages", "claude"},
+	} {
+		resp, err := http.Post(server.URL+tc.path, "application/json", strings.NewReader(`{"model":"`+tc.model+`","messages":[]}`))
+		if err != nil {
+			t.Fatalf("post %s: %v", tc.path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("%s: expected the request timeout, got %d", tc.path, resp.StatusCode)
+		}
+		resp, err = http.Post(server.URL+tc.path, "application/json", strings.NewReader(`{"model":"`+tc.model+`","messages":[],"stream":true}`))
+		if err != nil {
+			t.Fatalf("post %s: %v", tc.path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: a stream should outlive the request timeout, got %d", tc.path, resp.StatusCode)
+		}
+	}
+}
+
+func TestServerRejectsOversizeBodies(t *testing.T) {
+	server := testServer(t, true)
+	body := `{"provider":"openai","model":"` + strings.Repeat("x", 2048) + `"}`
+	resp, err := http.Post(server.URL+"/generate", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d", resp.StatusCode)
+	}
+}
+
+func TestReadinessReflectsShutdown(t *testing.T) {
+	server := testServer(t, false)
+	resp, err := http.Get(server.URL + "/readyz")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 while not ready, got %d", resp.StatusCode)
+	}
+}
+
+func TestRunShutsDownGracefully(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- run(ctx, stubKit{}, cmdconfig.Server{Addr: "127.0.0.1:0", ShutdownTimeout: time.Second, MaxBodyBytes: 1024})
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("run: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("server did not shut down")
+	}
+}
+
+func TestEmbeddedSpecMatchesSource(t *testing.T) {
+	source, err := os.ReadFile("../../../../servers/openapi.yaml")
+	if err != nil {
+		t.Skipf("source spec not available: %v", err)
+	}
+	if !bytes.Equal(source, openAPISpec) {
+		t.Fatalf("openapi.yaml is stale; run go generate ./cmd/aikit-server")
+	}
+}
@@ -0,0 +1,416 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	aikit "github.com/Volpestyle/ai-kit/packages/go"
+)
+
+func parseProviderList(value string) []aikit.Provider {
+	var providers []aikit.Provider
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(strings.ToLower(part)); part != "" {
+			providers = append(providers, aikit.Provider(part))
+		}
+	}
+	return providers
+}
+
+func (c *cli) models(ctx context.Context, kit kitClient, args []string) error {
+	fs := c.flags("models")
+	providers := fs.String("providers", "", "comma-separated providers to list (default: all configured)")
+	refresh := fs.Bool("refresh", false, "bypass the registry cache")
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	models, err := kit.ListModels(ctx, &aikit.ListModelsOptions{
+		Providers: parseProviderList(*providers),
+		Refresh:   *refresh,
+	})
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return c.printJSON(models)
+	}
+	tw := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROVIDER\tMODEL\tCONTEXT\tINPUT $/1M\tOUTPUT $/1M\tAVAILABLE")
+	for _, model := range models {
+		input, output := "-", "-"
+		if model.TokenPrices != nil {
+			input = strconv.FormatFloat(model.TokenPrices.Input, 'f', -1, 64)
+			output = strconv.FormatFloat(model.TokenPrices.Output, 'f', -1, 64)
+		}
+		contextWindow := "-"
+		if model.ContextWindow > 0 {
+			contextWindow = strconv.Itoa(model.ContextWindow)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%t\n", model.Provider, model.ID, contextWindow, input, output, model.Available)
+	}
+	return tw.Flush()
+}
+
+// generateFlags holds the request options shared by generate and stream.
+type generateFlags struct {
+	modelFlags
+	system      string
+	images      listFlag
+	maxTokens   int
+	temperature float64
+	asJSON      bool
+}
+
+func (c *cli) parseGenerate(name string, args []string) (*generateFlags, aikit.GenerateInput, error) {
+	fs := c.flags(name)
+	opts := &generateFlags{}
+	opts.register(fs)
+	fs.StringVar(&opts.system, "system", "", "system prompt")
+	fs.Var(&opts.images, "image", "attach an image file or URL (repeatable)")
+	fs.IntVar(&opts.maxTokens, "max-tokens", 0, "maximum output tokens")
+	fs.Float64Var(&opts.temperature, "temperature", -1, "sampling temperature")
+	fs.BoolVar(&opts.asJSON, "json", false, "print JSON output")
+	if err := fs.Parse(args); err != nil {
+		return nil, aikit.GenerateInput{}, err
+	}
+	provider, model, err := opts.resolve()
+	if err != nil {
+		return nil, aikit.GenerateInput{}, err
+	}
+	text, err := c.prompt(fs.Args())
+	if err != nil {
+		return nil, aikit.GenerateInput{}, err
+	}
+	input := aikit.GenerateInput{Provider: provider, Model: model}
+	if opts.system != "" {
+		input.Messages = append(input.Messages, aikit.Message{
+			Role:    "system",
+			Content: []aikit.ContentPart{{Type: "text", Text: opts.system}},
+		})
+	}
+	user := aikit.Message{Role: "user", Content: []aikit.ContentPart{{Type: "text", Text: text}}}
+	for _, ref := range opts.images {
+		image, err := loadImage(ref)
+		if err != nil {
+			return nil, aikit.GenerateInput{}, err
+		}
+		user.Content = append(user.Content, aikit.ContentPart{
+			Type:  "image",
+			Image: &aikit.ImageContent{URL: image.URL, Base64: image.Base64, MediaType: image.MediaType},
+		})
+	}
+	input.Messages = append(input.Messages, user)
+	if opts.maxTokens > 0 {
+		input.MaxTokens = &opts.maxTokens
+	}
+	if opts.temperature >= 0 {
+		input.Temperature = &opts.temperature
+	}
+	return opts, input, nil
+}
+
+func (c *cli) generate(ctx context.Context, kit kitClient, args []string) error {
+	opts, input, err := c.parseGenerate("generate", args)
+	if err != nil {
+		return err
+	}
+	output, err := kit.Generate(ctx, input)
+	if err != nil {
+		return err
+	}
+	if opts.asJSON {
+		output.Raw = nil
+		return c.printJSON(output)
+	}
+	if output.Text != "" {
+		fmt.Fprintln(c.stdout, output.Text)
+	}
+	for _, call := range output.ToolCalls {
+		fmt.Fprintf(c.stdout, "tool call %s(%s)\n", call.Name, call.ArgumentsJSON)
+	}
+	c.printUsage(output.Usage, output.Cost)
+	return nil
+}
+
+func (c *cli) stream(ctx context.Context, kit kitClient, args []string) error {
+	opts, input, err := c.parseGenerate("stream", args)
+	if err != nil {
+		return err
+	}
+	input.Stream = true
+	stream, err := kit.StreamGenerate(ctx, input)
+	if err != nil {
+		return err
+	}
+	acc := aikit.NewStreamAccumulator()
+	enc := json.NewEncoder(c.stdout)
+	for chunk := range acc.Tee(ctx, stream) {
+		if opts.asJSON {
+			if err := enc.Encode(chunk); err != nil {
+				return err
+			}
+			continue
+		}
+		switch chunk.Type {
+		case aikit.StreamChunkDelta:
+			fmt.Fprint(c.stdout, chunk.TextDelta)
+		case aikit.StreamChunkToolCallEnd:
+			if chunk.Call != nil {
+				fmt.Fprintf(c.stdout, "\ntool call %s(%s)\n", chunk.Call.Name, chunk.Call.ArgumentsJSON)
+			}
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if !opts.asJSON {
+		fmt.Fprintln(c.stdout)
+		output := acc.Output()
+		c.printUsage(output.Usage, output.Cost)
+	}
+	return acc.Err()
+}
+
+// printUsage reports token counts and cost on stderr so stdout stays
+// pipeable.
+func (c *cli) printUsage(usage *aikit.Usage, cost *aikit.CostBreakdown) {
+	if usage == nil {
+		return
+	}
+	line := fmt.Sprintf("tokens: %d in, %d out", usage.InputTokens, usage.OutputTokens)
+	if cost != nil && cost.TotalCostUSD > 0 {
+		line += fmt.Sprintf(", $%.6f", cost.TotalCostUSD)
+	}
+	fmt.Fprintln(c.stderr, line)
+}
+
+func (c *cli) image(ctx context.Context, kit kitClient, args []string) error {
+	fs := c.flags("image")
+	var model modelFlags
+	model.register(fs)
+	size := fs.String("size", "", "image size, e.g. 1024x1024")
+	out := fs.String("o", "image.png", "output file; extra images get -2, -3, ... suffixes")
+	var inputs listFlag
+	fs.Var(&inputs, "input-image", "reference image file or URL (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	provider, modelID, err := model.resolve()
+	if err != nil {
+		return err
+	}
+	prompt, err := c.prompt(fs.Args())
+	if err != nil {
+		return err
+	}
+	input := aikit.ImageGenerateInput{Provider: provider, Model: modelID, Prompt: prompt, Size: *size}
+	for _, ref := range inputs {
+		image, err := loadImage(ref)
+		if err != nil {
+			return err
+		}
+		input.InputImages = append(input.InputImages, image)
+	}
+	output, err := kit.GenerateImage(ctx, input)
+	if err != nil {
+		return err
+	}
+	images := output.Images
+	if len(images) == 0 {
+		images = []aikit.ImageOutput{{Mime: output.Mime, Data: output.Data}}
+	}
+	for idx, image := range images {
+		path := *out
+		if idx > 0 {
+			ext := filepath.Ext(path)
+			path = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(path, ext), idx+1, ext)
+		}
+		if err := c.writeOutput(path, image.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *cli) mesh(ctx context.Context, kit kitClient, args []string) error {
+	fs := c.flags("mesh")
+	var model modelFlags
+	model.register(fs)
+	format := fs.String("format", "glb", "mesh format")
+	out := fs.String("o", "", "output file (default mesh.<format>)")
+	var inputs listFlag
+	fs.Var(&inputs, "input-image", "reference image file or URL (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	provider, modelID, err := model.resolve()
+	if err != nil {
+		return err
+	}
+	prompt, err := c.prompt(fs.Args())
+	if err != nil {
+		return err
+	}
+	input := aikit.MeshGenerateInput{Provider: provider, Model: modelID, Prompt: prompt, Format: *format}
+	for _, ref := range inputs {
+		image, err := loadImage(ref)
+		if err != nil {
+			return err
+		}
+		input.InputImages = append(input.InputImages, image)
+	}
+	output, err := kit.GenerateMesh(ctx, input)
+	if err != nil {
+		return err
+	}
+	path := *out
+	if path == "" {
+		ext := output.Format
+		if ext == "" {
+			ext = *format
+		}
+		path = "mesh." + ext
+	}
+	return c.writeOutput(path, output.Data)
+}
+
+func (c *cli) transcribe(ctx context.Context, kit kitClient, args []string) error {
+	fs := c.flags("transcribe")
+	var model modelFlags
+	model.register(fs)
+	language := fs.String("language", "", "spoken language hint (ISO-639-1)")
+	prompt := fs.String("prompt", "", "context prompt for the transcriber")
+	format := fs.String("format", "", "provider response format, e.g. verbose_json")
+	out := fs.String("o", "", "write the transcript to this file instead of stdout")
+	asJSON := fs.Bool("json", false, "write the full result as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	provider, modelID, err := model.resolve()
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected one audio file, got %d arguments", fs.NArg())
+	}
+	output, err := kit.Transcribe(ctx, aikit.TranscribeInput{
+		Provider:       provider,
+		Model:          modelID,
+		Audio:          aikit.AudioInput{Path: fs.Arg(0)},
+		Language:       *language,
+		Prompt:         *prompt,
+		ResponseFormat: *format,
+	})
+	if err != nil {
+		return err
+	}
+	result := []byte(output.Text + "\n")
+	if *asJSON {
+		output.Raw = nil
+		if result, err = json.MarshalIndent(output, "", "  "); err != nil {
+			return err
+		}
+		result = append(result, '\n')
+	}
+	if *out == "" {
+		_, err := c.stdout.Write(result)
+		return err
+	}
+	if err := os.WriteFile(*out, result, 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "wrote %s\n", *out)
+	return nil
+}
+
+func (c *cli) route(ctx context.Context, kit kitClient, args []string) error {
+	fs := c.flags("route")
+	providers := fs.String("providers", "", "comma-separated providers to consider")
+	refresh := fs.Bool("refresh", false, "bypass the registry cache")
+	var constraints aikit.ModelConstraints
+	fs.BoolVar(&constraints.RequireTools, "require-tools", false, "require tool use")
+	fs.BoolVar(&constraints.RequireJSON, "require-json", false, "require JSON mode or JSON schema output")
+	fs.BoolVar(&constraints.RequireVision, "require-vision", false, "require image input")
+	fs.Float64Var(&constraints.MaxCostUSD, "max-cost", 0, "maximum input or output price per 1M tokens in USD")
+	noPreview := fs.Bool("no-preview", false, "exclude preview models")
+	prefer := fs.String("prefer", "", "comma-separated preferred model ids, in order")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *noPreview {
+		allow := false
+		constraints.AllowPreview = &allow
+	}
+	records, err := kit.ListModelRecords(ctx, &aikit.ListModelsOptions{
+		Providers: parseProviderList(*providers),
+		Refresh:   *refresh,
+	})
+	if err != nil {
+		return err
+	}
+	req := aikit.ModelResolutionRequest{Constraints: constraints}
+	for _, id := range strings.Split(*prefer, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			req.PreferredModels = append(req.PreferredModels, id)
+		}
+	}
+	resolved, err := (&aikit.ModelRouter{}).Resolve(records, req)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return c.printJSON(resolved)
+	}
+	fmt.Fprintf(c.stdout, "primary:  %s\n", resolved.Primary.ID)
+	for _, fallback := range resolved.Fallback {
+		fmt.Fprintf(c.stdout, "fallback: %s\n", fallback.ID)
+	}
+	return nil
+}
+
+// catalog reports curated entries that drifted from the live listings,
+// either the Kit's own catalog or a models directory given with -dir.
+func (c *cli) catalog(ctx context.Context, kit kitClient, args []string) error {
+	fs := c.flags("catalog")
+	providers := fs.String("providers", "", "comma-separated providers to compare (default: all configured)")
+	dir := fs.String("dir", "", "models directory with <provider>/scraped_models.json and curated_models.json files (default: the built-in catalog)")
+	refresh := fs.Bool("refresh", false, "bypass the registry cache")
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	opts := &aikit.CatalogReportOptions{Providers: parseProviderList(*providers), Refresh: *refresh}
+	if *dir != "" {
+		opts.Catalog = os.DirFS(*dir)
+	}
+	report, err := kit.CatalogReport(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return c.printJSON(report)
+	}
+	tw := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tPROVIDER\tMODEL\tNAME")
+	for _, entry := range report.Missing {
+		fmt.Fprintf(tw, "missing\t%s\t%s\t%s\n", entry.Provider, entry.Model, entry.DisplayName)
+	}
+	for _, entry := range report.Stale {
+		fmt.Fprintf(tw, "stale\t%s\t%s\t%s\n", entry.Provider, entry.Model, entry.DisplayName)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for _, failure := range report.Errors {
+		fmt.Fprintf(c.stderr, "aikit catalog: %s not compared: %s\n", failure.Provider, failure.Message)
+	}
+	return nil
+}
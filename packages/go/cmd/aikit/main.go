@@ -0,0 +1,202 @@
+// Command aikit is a terminal client for Kit: list models, try prompts
+// across providers, stream, generate media and check routing decisions. It
+// reads the same config file and environment variables as aikit-server.
+//
+//	aikit models -providers openai,anthropic
+//	echo "Say hi" | aikit generate -model anthropic/claude-sonnet-4-5
+//	aikit stream -model openai/gpt-4o-mini -image photo.jpg "Describe this"
+//	aikit catalog -dir models
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	aikit "github.com/Volpestyle/ai-kit/packages/go"
+	"github.com/Volpestyle/ai-kit/packages/go/internal/cmdconfig"
+)
+
+// kitClient is the part of *aikit.Kit the CLI uses; tests substitute a stub.
+type kitClient interface {
+	aikit.KitAPI
+	ListModelRecords(ctx context.Context, opts *aikit.ListModelsOptions) ([]aikit.ModelRecord, error)
+	CatalogReport(ctx context.Context, opts *aikit.CatalogReportOptions) (aikit.CatalogReport, error)
+}
+
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	newKit func(configPath string) (kitClient, error)
+}
+
+type command struct {
+	name    string
+	summary string
+	run     func(c *cli, ctx context.Context, kit kitClient, args []string) error
+}
+
+var commands = []command{
+	{"models", "list models visible to the configured keys", (*cli).models},
+	{"generate", "run one completion and print the result", (*cli).generate},
+	{"stream", "stream a completion as it is generated", (*cli).stream},
+	{"image", "generate an image and write it to a file", (*cli).image},
+	{"mesh", "generate a 3D mesh and write it to a file", (*cli).mesh},
+	{"transcribe", "transcribe an audio file", (*cli).transcribe},
+	{"route", "pick a model with ModelRouter.Resolve", (*cli).route},
+	{"catalog", "compare live model lists with the curated catalog", (*cli).catalog},
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	c := &cli{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr, newKit: loadKit}
+	os.Exit(c.main(ctx, os.Args[1:]))
+}
+
+func loadKit(configPath string) (kitClient, error) {
+	cfg, err := cmdconfig.Load(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return aikit.New(cfg.Kit)
+}
+
+func (c *cli) main(ctx context.Context, args []string) int {
+	global := flag.NewFlagSet("aikit", flag.ContinueOnError)
+	global.SetOutput(c.stderr)
+	configPath := global.String("config", os.Getenv("AI_KIT_CONFIG"), "path to a YAML or JSON config file")
+	global.Usage = func() {
+		fmt.Fprintln(c.stderr, "usage: aikit [-config file] <command> [flags]\n\ncommands:")
+		for _, cmd := range commands {
+			fmt.Fprintf(c.stderr, "  %-11s %s\n", cmd.name, cmd.summary)
+		}
+	}
+	if err := global.Parse(args); err != nil {
+		return 2
+	}
+	if global.NArg() == 0 {
+		global.Usage()
+		return 2
+	}
+	name := global.Arg(0)
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		kit, err := c.newKit(*configPath)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "aikit: %v\n", err)
+			return 1
+		}
+		if err := cmd.run(c, ctx, kit, global.Args()[1:]); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return 0
+			}
+			fmt.Fprintf(c.stderr, "aikit %s: %v\n", name, err)
+			return 1
+		}
+		return 0
+	}
+	fmt.Fprintf(c.stderr, "aikit: unknown command %q\n", name)
+	global.Usage()
+	return 2
+}
+
+func (c *cli) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("aikit "+name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	return fs
+}
+
+// modelFlags registers the provider/model selection shared by commands.
+type modelFlags struct {
+	provider string
+	model    string
+}
+
+func (m *modelFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&m.provider, "provider", "", "provider id (optional when -model is provider/model)")
+	fs.StringVar(&m.model, "model", "", "model id, or provider/model")
+}
+
+func (m *modelFlags) resolve() (aikit.Provider, string, error) {
+	provider, model := m.provider, m.model
+	if prefix, rest, found := strings.Cut(model, "/"); found && provider == "" {
+		provider, model = prefix, rest
+	}
+	if provider == "" || model == "" {
+		return "", "", errors.New("-model provider/model (or -provider and -model) is required")
+	}
+	return aikit.Provider(strings.ToLower(provider)), model, nil
+}
+
+// prompt joins positional arguments, or reads stdin when there are none or
+// the only argument is "-".
+func (c *cli) prompt(args []string) (string, error) {
+	if len(args) > 0 && !(len(args) == 1 && args[0] == "-") {
+		return strings.Join(args, " "), nil
+	}
+	data, err := io.ReadAll(c.stdin)
+	if err != nil {
+		return "", err
+	}
+	text := strings.TrimSpace(string(data))
+	if text == "" {
+		return "", errors.New("no prompt given on the command line or stdin")
+	}
+	return text, nil
+}
+
+// listFlag collects a repeatable string flag.
+type listFlag []string
+
+func (l *listFlag) String() string     { return strings.Join(*l, ",") }
+func (l *listFlag) Set(v string) error { *l = append(*l, v); return nil }
+
+// loadImage reads a local image into base64, or passes an http(s) URL through.
+func loadImage(ref string) (aikit.ImageInput, error) {
+	if strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") {
+		return aikit.ImageInput{URL: ref}, nil
+	}
+	data, err := os.ReadFile(ref)
+	if err != nil {
+		return aikit.ImageInput{}, err
+	}
+	mediaType := mime.TypeByExtension(strings.ToLower(filepath.Ext(ref)))
+	if mediaType == "" {
+		mediaType = "application/octet-stream"
+	}
+	return aikit.ImageInput{Base64: base64.StdEncoding.EncodeToString(data), MediaType: mediaType}, nil
+}
+
+func (c *cli) printJSON(value interface{}) error {
+	enc := json.NewEncoder(c.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(value)
+}
+
+// writeOutput writes base64 data to path, falling back to the raw bytes when
+// the payload is not base64.
+func (c *cli) writeOutput(path, data string) error {
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		raw = []byte(data)
+	}
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "wrote %s (%d bytes)\n", path, len(raw))
+	return nil
+}
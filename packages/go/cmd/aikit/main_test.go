@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	aikit "github.com/Volpestyle/ai-kit/packages/go"
+)
+
+type stubKit struct {
+	generate   aikit.GenerateInput
+	image      aikit.ImageGenerateInput
+	transcribe aikit.TranscribeInput
+	listOpts   *aikit.ListModelsOptions
+	reportOpts *aikit.CatalogReportOptions
+}
+
+func (k *stubKit) ListModels(ctx context.Context, opts *aikit.ListModelsOptions) ([]aikit.ModelMetadata, error) {
+	k.listOpts = opts
+	return []aikit.ModelMetadata{{
+		ID:            "gpt-4o-mini",
+		Provider:      aikit.ProviderOpenAI,
+		ContextWindow: 128000,
+		TokenPrices:   &aikit.TokenPrices{Input: 0.15, Output: 0.6},
+		Available:     true,
+	}}, nil
+}
+
+func (k *stubKit) ListModelRecords(ctx context.Context, opts *aikit.ListModelsOptions) ([]aikit.ModelRecord, error) {
+	tools := true
+	return []aikit.ModelRecord{
+		{ID: "openai:gpt-4o", Features: aikit.ModelFeatures{Tools: &tools}, Availability: aikit.ModelAvailability{Entitled: true}, Pricing: &aikit.ModelPricing{InputPer1M: 2.5}},
+		{ID: "openai:gpt-4o-mini", Features: aikit.ModelFeatures{Tools: &tools}, Availability: aikit.ModelAvailability{Entitled: true}, Pricing: &aikit.ModelPricing{InputPer1M: 0.15}},
+		{ID: "local:tiny", Availability: aikit.ModelAvailability{Entitled: true}},
+	}, nil
+}
+
+func (k *stubKit) CatalogReport(ctx context.Context, opts *aikit.CatalogReportOptions) (aikit.CatalogReport, error) {
+	k.reportOpts = opts
+	return aikit.CatalogReport{
+		Missing: []aikit.CatalogReportEntry{{Provider: aikit.ProviderOpenAI, Model: "gpt-5", DisplayName: "gpt-5"}},
+		Stale:   []aikit.CatalogReportEntry{{Provider: aikit.ProviderOpenAI, Model: "gpt-3.5-turbo", DisplayName: "GPT-3.5 Turbo"}},
+		Errors:  []aikit.ProviderListError{{Provider: aikit.ProviderAnthropic, Message: "invalid x-api-key"}},
+	}, nil
+}
+
+func (k *stubKit) Generate(ctx context.Context, in aikit.GenerateInput) (aikit.GenerateOutput, error) {
+	k.generate = in
+	return aikit.GenerateOutput{Text: "hello there", Usage: &aikit.Usage{InputTokens: 2, OutputTokens: 3}}, nil
+}
+
+func (k *stubKit) GenerateImage(ctx context.Context, in aikit.ImageGenerateInput) (aikit.ImageGenerateOutput, error) {
+	k.image = in
+	data := base64.StdEncoding.EncodeToString([]byte("png-bytes"))
+	return aikit.ImageGenerateOutput{Images: []aikit.ImageOutput{{Mime: "image/png", Data: data}, {Mime: "image/png", Data: data}}}, nil
+}
+
+func (k *stubKit) GenerateMesh(ctx context.Context, in aikit.MeshGenerateInput) (aikit.MeshGenerateOutput, error) {
+	return aikit.MeshGenerateOutput{Data: base64.StdEncoding.EncodeToString([]byte("glb")), Format: "glb"}, nil
+}
+
+func (k *stubKit) Transcribe(ctx context.Context, in aikit.TranscribeInput) (aikit.TranscribeOutput, error) {
+	k.transcribe = in
+	return aikit.TranscribeOutput{Text: "spoken words"}, nil
+}
+
+func (k *stubKit) StreamGenerate(ctx context.Context, in aikit.GenerateInput) (<-chan aikit.StreamChunk, error) {
+	k.generate = in
+	ch := make(chan aikit.StreamChunk, 4)
+	ch <- aikit.StreamChunk{Type: aikit.StreamChunkMessageStart}
+	ch <- aikit.StreamChunk{Type: aikit.StreamChunkDelta, TextDelta: "str"}
+	ch <- aikit.StreamChunk{Type: aikit.StreamChunkDelta, TextDelta: "eamed"}
+	ch <- aikit.StreamChunk{Type: aikit.StreamChunkMessageEnd, Usage: &aikit.Usage{InputTokens: 1, OutputTokens: 2}}
+	close(ch)
+	return ch, nil
+}
+
+func runCLI(t *testing.T, kit *stubKit, stdin string, args ...string) (string, string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	c := &cli{
+		stdin:  strings.NewReader(stdin),
+		stdout: &stdout,
+		stderr: &stderr,
+		newKit: func(string) (kitClient, error) { return kit, nil },
+	}
+	code := c.main(context.Background(), args)
+	return stdout.String(), stderr.String(), code
+}
+
+func TestModelsTableAndFilters(t *testing.T) {
+	kit := &stubKit{}
+	out, _, code := runCLI(t, kit, "", "models", "-providers", "openai, anthropic", "-refresh")
+	if code != 0 || !strings.Contains(out, "PROVIDER") || !strings.Contains(out, "gpt-4o-mini") || !strings.Contains(out, "128000") {
+		t.Fatalf("unexpected table (code %d):\n%s", code, out)
+	}
+	if len(kit.listOpts.Providers) != 2 || !kit.listOpts.Refresh {
+		t.Fatalf("filters not forwarded: %+v", kit.listOpts)
+	}
+	out, _, _ = runCLI(t, kit, "", "models", "-json")
+	if !strings.Contains(out, `"id": "gpt-4o-mini"`) {
+		t.Fatalf("unexpected JSON:\n%s", out)
+	}
+}
+
+func TestGenerateReadsStdinAndAttachesImages(t *testing.T) {
+	image := filepath.Join(t.TempDir(), "photo.png")
+	if err := os.WriteFile(image, []byte("img"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	kit := &stubKit{}
+	out, errOut, code := runCLI(t, kit, "describe this\n", "generate", "-model", "anthropic/claude-sonnet-4-5", "-system", "be brief", "-image", image)
+	if code != 0 || out != "hello there\n" || !strings.Contains(errOut, "tokens: 2 in, 3 out") {
+		t.Fatalf("unexpected output (code %d): %q %q", code, out, errOut)
+	}
+	in := kit.generate
+	if in.Provider != aikit.ProviderAnthropic || in.Model != "claude-sonnet-4-5" || len(in.Messages) != 2 {
+		t.Fatalf("unexpected input: %+v", in)
+	}
+	user := in.Messages[1]
+	if user.Content[0].Text != "describe this" || user.Content[1].Image.MediaType != "image/png" || user.Content[1].Image.Base64 == "" {
+		t.Fatalf("unexpected user message: %+v", user)
+	}
+}
+
+func TestStreamPrintsDeltas(t *testing.T) {
+	out, _, code := runCLI(t, &stubKit{}, "", "stream", "-provider", "openai", "-model", "gpt-4o-mini", "hi")
+	if code != 0 || out != "streamed\n" {
+		t.Fatalf("unexpected stream output (code %d): %q", code, out)
+	}
+}
+
+func TestImageWritesFiles(t *testing.T) {
+	dir := t.TempDir()
+	kit := &stubKit{}
+	_, _, code := runCLI(t, kit, "", "image", "-model", "openai/gpt-image-1", "-o", filepath.Join(dir, "cat.png"), "a cat")
+	if code != 0 || kit.image.Prompt != "a cat" {
+		t.Fatalf("image failed (code %d): %+v", code, kit.image)
+	}
+	for _, name := range []string{"cat.png", "cat-2.png"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(data) != "png-bytes" {
+			t.Fatalf("%s: %q %v", name, data, err)
+		}
+	}
+}
+
+func TestTranscribeWritesTranscript(t *testing.T) {
+	dir := t.TempDir()
+	kit := &stubKit{}
+	target := filepath.Join(dir, "out.txt")
+	_, _, code := runCLI(t, kit, "", "transcribe", "-model", "openai/whisper-1", "-language", "en", "-o", target, "talk.mp3")
+	if code != 0 || kit.transcribe.Audio.Path != "talk.mp3" || kit.transcribe.Language != "en" {
+		t.Fatalf("transcribe failed (code %d): %+v", code, kit.transcribe)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "spoken words\n" {
+		t.Fatalf("unexpected transcript %q", data)
+	}
+}
+
+func TestRouteAppliesConstraints(t *testing.T) {
+	out, _, code := runCLI(t, &stubKit{}, "", "route", "-require-tools")
+	if code != 0 || !strings.HasPrefix(out, "primary:  openai:gpt-4o-mini\nfallback: openai:gpt-4o\n") {
+		t.Fatalf("unexpected route (code %d):\n%s", code, out)
+	}
+	_, errOut, code := runCLI(t, &stubKit{}, "", "route", "-require-vision")
+	if code != 1 || !strings.Contains(errOut, "no models match") {
+		t.Fatalf("expected routing failure, got %d %q", code, errOut)
+	}
+}
+
+func TestCatalogReportsDrift(t *testing.T) {
+	kit := &stubKit{}
+	dir := t.TempDir()
+	out, errOut, code := runCLI(t, kit, "", "catalog", "-dir", dir, "-providers", "openai,anthropic")
+	if code != 0 || !strings.Contains(out, "missing  openai    gpt-5") || !strings.Contains(out, "stale    openai    gpt-3.5-turbo") {
+		t.Fatalf("unexpected report (code %d):\n%s", code, out)
+	}
+	if !strings.Contains(errOut, "anthropic not compared") {
+		t.Fatalf("expected the failed provider on stderr, got %q", errOut)
+	}
+	if kit.reportOpts.Catalog == nil || len(kit.reportOpts.Providers) != 2 {
+		t.Fatalf("options not forwarded: %+v", kit.reportOpts)
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	if _, _, code := runCLI(t, &stubKit{}, ""); code != 2 {
+		t.Fatalf("expected usage exit code, got %d", code)
+	}
+	if _, errOut, code := runCLI(t, &stubKit{}, "", "bogus"); code != 2 || !strings.Contains(errOut, "unknown command") {
+		t.Fatalf("unexpected result %d %q", code, errOut)
+	}
+	if _, errOut, code := runCLI(t, &stubKit{}, "", "generate", "hi"); code != 1 || !strings.Contains(errOut, "-model") {
+		t.Fatalf("expected missing model error, got %d %q", code, errOut)
+	}
+}
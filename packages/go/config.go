@@ -0,0 +1,461 @@
+package aikit
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	compatibleAPIOpenAI    = "openai"
+	compatibleAPIAnthropic = "anthropic"
+)
+
+// ConfigError lists every problem found while loading or validating a Config,
+// so a misconfigured deployment can be fixed in one pass.
+type ConfigError struct {
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+	if len(e.Problems) == 1 {
+		return "invalid aikit config: " + e.Problems[0]
+	}
+	return fmt.Sprintf("invalid aikit config (%d problems): %s", len(e.Problems), strings.Join(e.Problems, "; "))
+}
+
+type configProblems []string
+
+func (p *configProblems) add(format string, args ...interface{}) {
+	*p = append(*p, fmt.Sprintf(format, args...))
+}
+
+func (p configProblems) err() error {
+	if len(p) == 0 {
+		return nil
+	}
+	return &ConfigError{Problems: p}
+}
+
+// Validate reports every missing key, malformed setting and conflicting
+// compatible provider in one *ConfigError. New calls it before building any
+// adapter; providers supplied through Adapters need no keys.
+func (c Config) Validate() error {
+	var problems configProblems
+	c.validate(&problems)
+	return problems.err()
+}
+
+func (c Config) validate(problems *configProblems) {
+	configured := 0
+	for _, adapter := range c.Adapters {
+		if adapter != nil {
+			configured++
+		}
+	}
+	requireKey := func(provider Provider, primary string, extras []string) {
+		if c.Adapters[provider] == nil && len(normalizeKeys(primary, extras)) == 0 {
+			problems.add("%s api key is required", provider)
+		}
+	}
+	checkTimeout := func(name string, timeout time.Duration) {
+		if timeout < 0 {
+			problems.add("%s timeout must not be negative", name)
+		}
+	}
+	if c.RegistryTTL < 0 {
+		problems.add("registryTTL must not be negative")
+	}
+	aliasNames := make([]string, 0, len(c.Aliases))
+	for name := range c.Aliases {
+		aliasNames = append(aliasNames, name)
+	}
+	sort.Strings(aliasNames)
+	for _, name := range aliasNames {
+		if _, _, ok := splitCanonicalID(c.Aliases[name]); !ok || strings.TrimSpace(name) == "" {
+			problems.add("alias %q target %q must be provider:model", name, c.Aliases[name])
+		}
+	}
+	if c.CatalogDir != "" {
+		if info, err := os.Stat(c.CatalogDir); err != nil {
+			problems.add("catalogDir: %v", err)
+		} else if !info.IsDir() {
+			problems.add("catalogDir %s is not a directory", c.CatalogDir)
+		}
+	}
+	if p := c.OpenAI; p != nil {
+		configured++
+		requireKey(ProviderOpenAI, p.APIKey, p.APIKeys)
+		checkTimeout("openai", p.Timeout)
+		p.Transport.validate("openai", problems)
+	}
+	if p := c.Anthropic; p != nil {
+		configured++
+		requireKey(ProviderAnthropic, p.APIKey, p.APIKeys)
+		checkTimeout("anthropic", p.Timeout)
+		p.Transport.validate("anthropic", problems)
+	}
+	if p := c.XAI; p != nil {
+		configured++
+		requireKey(ProviderXAI, p.APIKey, p.APIKeys)
+		checkTimeout("xai", p.Timeout)
+		p.Transport.validate("xai", problems)
+		switch p.CompatibilityMode {
+		case "", "openai", "anthropic":
+		default:
+			problems.add("xai compatibilityMode %q must be openai or anthropic", p.CompatibilityMode)
+		}
+	}
+	if p := c.Google; p != nil {
+		configured++
+		requireKey(ProviderGoogle, p.APIKey, p.APIKeys)
+		checkTimeout("google", p.Timeout)
+		p.Transport.validate("google", problems)
+	}
+	if p := c.Ollama; p != nil {
+		configured++
+		checkTimeout("ollama", p.Timeout)
+		p.Transport.validate("ollama", problems)
+	}
+	seen := make(map[Provider]bool, len(c.Compatible))
+	for idx, compat := range c.Compatible {
+		configured++
+		name := strings.TrimSpace(string(compat.Name))
+		if name == "" {
+			problems.add("compatible[%d] name is required", idx)
+			continue
+		}
+		label := "compatible provider " + name
+		if _, builtin := parseProvider(name); builtin {
+			problems.add("%s clashes with a built-in provider", label)
+		}
+		if seen[compat.Name] {
+			problems.add("%s is listed more than once", label)
+		}
+		seen[compat.Name] = true
+		switch compat.API {
+		case "", compatibleAPIOpenAI, compatibleAPIAnthropic:
+		default:
+			problems.add("%s api %q must be openai or anthropic", label, compat.API)
+		}
+		if strings.TrimSpace(compat.BaseURL) == "" && c.Adapters[compat.Name] == nil {
+			problems.add("%s baseURL is required", label)
+		}
+		checkTimeout(label, compat.Timeout)
+		compat.Transport.validate(label, problems)
+	}
+	if configured == 0 && c.AdapterFactory == nil {
+		problems.add("at least one provider config or adapter is required")
+	}
+}
+
+type configFile struct {
+	RegistryTTL       time.Duration     `yaml:"registryTTL"`
+	RegistryStaleTTL  time.Duration     `yaml:"registryStaleTTL"`
+	RegistryCacheFile string            `yaml:"registryCacheFile"`
+	CatalogDir        string            `yaml:"catalogDir"`
+	Aliases           map[string]string `yaml:"aliases"`
+	OpenAI            *providerSection  `yaml:"openai"`
+	Anthropic         *providerSection  `yaml:"anthropic"`
+	XAI               *providerSection  `yaml:"xai"`
+	Google            *providerSection  `yaml:"google"`
+	Ollama            *providerSection  `yaml:"ollama"`
+	Compatible        []providerSection `yaml:"compatible"`
+}
+
+// providerSection is the file form of every provider config; fields that do
+// not apply to a provider are ignored.
+type providerSection struct {
+	Name                string           `yaml:"name"`
+	API                 string           `yaml:"api"`
+	APIKey              string           `yaml:"apiKey"`
+	APIKeys             []string         `yaml:"apiKeys"`
+	BaseURL             string           `yaml:"baseURL"`
+	Organization        string           `yaml:"organization"`
+	Version             string           `yaml:"version"`
+	CompatibilityMode   string           `yaml:"compatibilityMode"`
+	DefaultUseResponses bool             `yaml:"defaultUseResponses"`
+	Timeout             time.Duration    `yaml:"timeout"`
+	Transport           transportSection `yaml:"transport"`
+}
+
+// transportSection mirrors TransportConfig field for field so one converts
+// to the other.
+type transportSection struct {
+	ConnectTimeout        time.Duration     `yaml:"connectTimeout"`
+	ResponseHeaderTimeout time.Duration     `yaml:"responseHeaderTimeout"`
+	IdleConnTimeout       time.Duration     `yaml:"idleConnTimeout"`
+	StreamTimeout         time.Duration     `yaml:"streamTimeout"`
+	StreamIdleTimeout     time.Duration     `yaml:"streamIdleTimeout"`
+	ProxyURL              string            `yaml:"proxyURL"`
+	CAFile                string            `yaml:"caFile"`
+	MaxConnsPerHost       int               `yaml:"maxConnsPerHost"`
+	Headers               map[string]string `yaml:"headers"`
+}
+
+// envReference matches ${NAME} and ${NAME:-default}.
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// LoadConfig reads a YAML or JSON config file. String values may reference
+// environment variables as ${NAME} or ${NAME:-default}; an unset variable
+// without a default is an error. Providers the file does not mention are
+// configured from the environment exactly as ConfigFromEnv would, and the
+// result is validated. Keys other than the provider sections and the
+// registry settings are ignored, so the file can be shared with aikit-server
+// settings. registryCacheFile persists model listings through a
+// FileRegistryStore, and catalogDir is merged over the embedded catalog.
+//
+//	registryTTL: 10m
+//	registryCacheFile: /var/cache/aikit/models.json
+//	openai:
+//	  apiKeys: ["${OPENAI_KEY_A}", "${OPENAI_KEY_B:-}"]
+//	  timeout: 60s
+//	compatible:
+//	  - name: groq
+//	    baseURL: https://api.groq.com/openai
+//	    apiKey: ${GROQ_API_KEY}
+func LoadConfig(path string) (Config, error) {
+	if path == "" {
+		return ConfigFromEnv()
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	var root yaml.Node
+	// YAML is a superset of JSON, so one decoder serves both formats.
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return Config{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	var problems configProblems
+	expandEnvNodes(&root, &problems)
+	var file configFile
+	if err := root.Decode(&file); err != nil {
+		return Config{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	cfg := file.config()
+	env := envConfig(&problems)
+	if cfg.RegistryTTL == 0 {
+		cfg.RegistryTTL = env.RegistryTTL
+	}
+	if cfg.RegistryStaleTTL == 0 {
+		cfg.RegistryStaleTTL = env.RegistryStaleTTL
+	}
+	if cfg.CatalogDir == "" {
+		cfg.CatalogDir = env.CatalogDir
+	}
+	cachePath := file.RegistryCacheFile
+	if cachePath == "" {
+		cachePath = envValue("AI_KIT_REGISTRY_CACHE_FILE")
+	}
+	cfg.RegistryStore = openRegistryCache(cachePath, &problems)
+	if cfg.OpenAI == nil {
+		cfg.OpenAI = env.OpenAI
+	}
+	if cfg.Anthropic == nil {
+		cfg.Anthropic = env.Anthropic
+	}
+	if cfg.XAI == nil {
+		cfg.XAI = env.XAI
+	}
+	if cfg.Google == nil {
+		cfg.Google = env.Google
+	}
+	if cfg.Ollama == nil {
+		cfg.Ollama = env.Ollama
+	}
+	if len(cfg.Compatible) == 0 {
+		cfg.Compatible = env.Compatible
+	}
+	cfg.validate(&problems)
+	return cfg, problems.err()
+}
+
+// expandEnvNodes substitutes environment references in scalar values only,
+// so comments and keys are left alone.
+func expandEnvNodes(node *yaml.Node, problems *configProblems) {
+	if node.Kind == yaml.ScalarNode {
+		expanded := envReference.ReplaceAllStringFunc(node.Value, func(ref string) string {
+			match := envReference.FindStringSubmatch(ref)
+			if value, ok := os.LookupEnv(match[1]); ok {
+				return value
+			}
+			if match[2] != "" {
+				return match[3]
+			}
+			problems.add("environment variable %s is not set", match[1])
+			return ""
+		})
+		if expanded != node.Value && node.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle) == 0 {
+			// Let unquoted references resolve to numbers and booleans.
+			node.Tag = ""
+		}
+		node.Value = expanded
+		return
+	}
+	for _, child := range node.Content {
+		expandEnvNodes(child, problems)
+	}
+}
+
+func (f configFile) config() Config {
+	cfg := Config{RegistryTTL: f.RegistryTTL, RegistryStaleTTL: f.RegistryStaleTTL, CatalogDir: f.CatalogDir, Aliases: f.Aliases}
+	if p := f.OpenAI; p != nil {
+		cfg.OpenAI = &OpenAIConfig{
+			APIKey:              p.APIKey,
+			APIKeys:             p.APIKeys,
+			BaseURL:             p.BaseURL,
+			Organization:        p.Organization,
+			DefaultUseResponses: p.DefaultUseResponses,
+			Timeout:             p.Timeout,
+			Transport:           TransportConfig(p.Transport),
+		}
+	}
+	if p := f.Anthropic; p != nil {
+		cfg.Anthropic = &AnthropicConfig{APIKey: p.APIKey, APIKeys: p.APIKeys, BaseURL: p.BaseURL, Version: p.Version, Timeout: p.Timeout, Transport: TransportConfig(p.Transport)}
+	}
+	if p := f.XAI; p != nil {
+		cfg.XAI = &XAIConfig{APIKey: p.APIKey, APIKeys: p.APIKeys, BaseURL: p.BaseURL, CompatibilityMode: p.CompatibilityMode, Timeout: p.Timeout, Transport: TransportConfig(p.Transport)}
+	}
+	if p := f.Google; p != nil {
+		cfg.Google = &GoogleConfig{APIKey: p.APIKey, APIKeys: p.APIKeys, BaseURL: p.BaseURL, Timeout: p.Timeout, Transport: TransportConfig(p.Transport)}
+	}
+	if p := f.Ollama; p != nil {
+		cfg.Ollama = &OllamaConfig{APIKey: p.APIKey, APIKeys: p.APIKeys, BaseURL: p.BaseURL, DefaultUseResponses: p.DefaultUseResponses, Timeout: p.Timeout, Transport: TransportConfig(p.Transport)}
+	}
+	for _, p := range f.Compatible {
+		cfg.Compatible = append(cfg.Compatible, CompatibleConfig{
+			Name:      Provider(strings.ToLower(strings.TrimSpace(p.Name))),
+			API:       strings.ToLower(p.API),
+			APIKey:    p.APIKey,
+			APIKeys:   p.APIKeys,
+			BaseURL:   p.BaseURL,
+			Timeout:   p.Timeout,
+			Transport: TransportConfig(p.Transport),
+		})
+	}
+	return cfg
+}
+
+// ConfigFromEnv builds a Config from the conventional environment variables
+// and validates it. A provider is configured when its key variable is set;
+// key variables accept a comma-separated list for key rotation.
+//
+//	OPENAI_API_KEY, OPENAI_BASE_URL, OPENAI_ORGANIZATION
+//	ANTHROPIC_API_KEY, ANTHROPIC_BASE_URL, ANTHROPIC_VERSION
+//	GOOGLE_API_KEY (or GEMINI_API_KEY), GOOGLE_BASE_URL
+//	XAI_API_KEY, XAI_BASE_URL, XAI_COMPATIBILITY_MODE
+//	OLLAMA_BASE_URL, OLLAMA_API_KEY
+//	AI_KIT_REGISTRY_TTL, AI_KIT_REGISTRY_STALE_TTL, AI_KIT_<PROVIDER>_TIMEOUT (Go durations)
+//	AI_KIT_REGISTRY_CACHE_FILE, AI_KIT_CATALOG_DIR
+//
+// AI_KIT_COMPATIBLE lists compatible provider names (e.g. "groq,together");
+// each reads <NAME>_BASE_URL, <NAME>_API_KEY and <NAME>_API.
+func ConfigFromEnv() (Config, error) {
+	var problems configProblems
+	cfg := envConfig(&problems)
+	cfg.RegistryStore = openRegistryCache(envValue("AI_KIT_REGISTRY_CACHE_FILE"), &problems)
+	cfg.validate(&problems)
+	return cfg, problems.err()
+}
+
+// envConfig reads everything but the registry cache, whose file LoadConfig
+// may take from its own settings instead.
+func envConfig(problems *configProblems) Config {
+	duration := func(name string) time.Duration {
+		value := strings.TrimSpace(os.Getenv(name))
+		if value == "" {
+			return 0
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			problems.add("%s: invalid duration %q", name, value)
+		}
+		return d
+	}
+	timeout := func(provider string) time.Duration {
+		return duration("AI_KIT_" + envName(provider) + "_TIMEOUT")
+	}
+	cfg := Config{
+		RegistryTTL:      duration("AI_KIT_REGISTRY_TTL"),
+		RegistryStaleTTL: duration("AI_KIT_REGISTRY_STALE_TTL"),
+		CatalogDir:       envValue("AI_KIT_CATALOG_DIR"),
+	}
+	if keys := envKeys("OPENAI_API_KEY"); len(keys) > 0 {
+		organization := envValue("OPENAI_ORGANIZATION")
+		if organization == "" {
+			organization = envValue("OPENAI_ORG_ID")
+		}
+		cfg.OpenAI = &OpenAIConfig{APIKeys: keys, BaseURL: envValue("OPENAI_BASE_URL"), Organization: organization, Timeout: timeout("openai")}
+	}
+	if keys := envKeys("ANTHROPIC_API_KEY"); len(keys) > 0 {
+		cfg.Anthropic = &AnthropicConfig{APIKeys: keys, BaseURL: envValue("ANTHROPIC_BASE_URL"), Version: envValue("ANTHROPIC_VERSION"), Timeout: timeout("anthropic")}
+	}
+	keys := envKeys("GOOGLE_API_KEY")
+	if len(keys) == 0 {
+		keys = envKeys("GEMINI_API_KEY")
+	}
+	if len(keys) > 0 {
+		cfg.Google = &GoogleConfig{APIKeys: keys, BaseURL: envValue("GOOGLE_BASE_URL"), Timeout: timeout("google")}
+	}
+	if keys := envKeys("XAI_API_KEY"); len(keys) > 0 {
+		cfg.XAI = &XAIConfig{APIKeys: keys, BaseURL: envValue("XAI_BASE_URL"), CompatibilityMode: envValue("XAI_COMPATIBILITY_MODE"), Timeout: timeout("xai")}
+	}
+	if base, keys := envValue("OLLAMA_BASE_URL"), envKeys("OLLAMA_API_KEY"); base != "" || len(keys) > 0 {
+		cfg.Ollama = &OllamaConfig{APIKeys: keys, BaseURL: base, Timeout: timeout("ollama")}
+	}
+	for _, name := range envKeys("AI_KIT_COMPATIBLE") {
+		name = strings.ToLower(name)
+		prefix := envName(name)
+		cfg.Compatible = append(cfg.Compatible, CompatibleConfig{
+			Name:    Provider(name),
+			API:     strings.ToLower(envValue(prefix + "_API")),
+			APIKeys: envKeys(prefix + "_API_KEY"),
+			BaseURL: envValue(prefix + "_BASE_URL"),
+			Timeout: timeout(name),
+		})
+	}
+	return cfg
+}
+
+// openRegistryCache opens the file store behind registryCacheFile; an empty
+// path keeps the default in-memory store.
+func openRegistryCache(path string, problems *configProblems) RegistryStore {
+	if path == "" {
+		return nil
+	}
+	store, err := NewFileRegistryStore(path)
+	if err != nil {
+		problems.add("registryCacheFile: %v", err)
+		return nil
+	}
+	return store
+}
+
+func envValue(name string) string {
+	return strings.TrimSpace(os.Getenv(name))
+}
+
+// envKeys splits a single key or a comma-separated key list.
+func envKeys(name string) []string {
+	return normalizeKeys("", strings.Split(os.Getenv(name), ","))
+}
+
+// envName upper-cases a provider name and replaces characters that are not
+// valid in environment variable names, so "together-ai" reads TOGETHER_AI_*.
+func envName(provider string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, provider)
+}
@@ -0,0 +1,188 @@
+package aikit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func clearProviderEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{
+		"OPENAI_API_KEY", "OPENAI_BASE_URL", "OPENAI_ORGANIZATION", "OPENAI_ORG_ID",
+		"ANTHROPIC_API_KEY", "ANTHROPIC_BASE_URL", "ANTHROPIC_VERSION",
+		"GOOGLE_API_KEY", "GEMINI_API_KEY", "GOOGLE_BASE_URL",
+		"XAI_API_KEY", "XAI_BASE_URL", "XAI_COMPATIBILITY_MODE",
+		"OLLAMA_BASE_URL", "OLLAMA_API_KEY",
+		"AI_KIT_REGISTRY_TTL", "AI_KIT_REGISTRY_STALE_TTL", "AI_KIT_REGISTRY_CACHE_FILE", "AI_KIT_CATALOG_DIR", "AI_KIT_COMPATIBLE",
+	} {
+		t.Setenv(name, "")
+	}
+}
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func TestLoadConfigYAML(t *testing.T) {
+	clearProviderEnv(t)
+	t.Setenv("TEST_OPENAI_KEY", "sk-a")
+	t.Setenv("GROQ_KEY", "gsk")
+	t.Setenv("ANTHROPIC_API_KEY", "ant-from-env")
+	path := writeConfigFile(t, "aikit.yaml", `
+# ${COMMENTED_OUT} references are not expanded
+server:
+  addr: ":8080"
+registryTTL: 10m
+openai:
+  apiKeys: ["${TEST_OPENAI_KEY}", "${TEST_OPENAI_SECONDARY:-sk-b}"]
+  timeout: 45s
+compatible:
+  - name: Groq
+    baseURL: https://api.groq.com/openai
+    apiKey: ${GROQ_KEY}
+    timeout: 20s
+`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.RegistryTTL != 10*time.Minute || cfg.OpenAI.Timeout != 45*time.Second {
+		t.Fatalf("unexpected durations: %+v", cfg)
+	}
+	if strings.Join(cfg.OpenAI.APIKeys, ",") != "sk-a,sk-b" {
+		t.Fatalf("unexpected openai keys: %v", cfg.OpenAI.APIKeys)
+	}
+	if cfg.Anthropic == nil || cfg.Anthropic.APIKeys[0] != "ant-from-env" {
+		t.Fatalf("expected anthropic from env: %+v", cfg.Anthropic)
+	}
+	compat := cfg.Compatible
+	if len(compat) != 1 || compat[0].Name != "groq" || compat[0].APIKey != "gsk" || compat[0].Timeout != 20*time.Second {
+		t.Fatalf("unexpected compatible providers: %+v", compat)
+	}
+}
+
+func TestLoadConfigAggregatesProblems(t *testing.T) {
+	clearProviderEnv(t)
+	path := writeConfigFile(t, "aikit.json", `{
+  "openai": {"apiKey": "${TEST_UNSET_KEY}"},
+  "xai": {"apiKey": "x", "compatibilityMode": "grpc"},
+  "compatible": [{"name": "openai", "api": "soap"}]
+}`)
+	_, err := LoadConfig(path)
+	var cfgErr *ConfigError
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("expected ConfigError, got %v", err)
+	}
+	want := []string{
+		"environment variable TEST_UNSET_KEY is not set",
+		"openai api key is required",
+		`xai compatibilityMode "grpc" must be openai or anthropic`,
+		"compatible provider openai clashes with a built-in provider",
+		`compatible provider openai api "soap" must be openai or anthropic`,
+		"compatible provider openai baseURL is required",
+	}
+	if strings.Join(cfgErr.Problems, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected problems:\n%s", strings.Join(cfgErr.Problems, "\n"))
+	}
+}
+
+func TestLoadConfigPrefersFileRegistryCache(t *testing.T) {
+	clearProviderEnv(t)
+	t.Setenv("OPENAI_API_KEY", "k")
+	t.Setenv("AI_KIT_REGISTRY_CACHE_FILE", writeConfigFile(t, "corrupt.json", "not json"))
+	cachePath := filepath.Join(t.TempDir(), "models.json")
+	path := writeConfigFile(t, "aikit.yaml", "registryCacheFile: "+cachePath+"\n")
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("expected the environment cache file to be ignored, got %v", err)
+	}
+	if store, ok := cfg.RegistryStore.(*FileRegistryStore); !ok || store.path != cachePath {
+		t.Fatalf("expected the file's registry cache, got %+v", cfg.RegistryStore)
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	clearProviderEnv(t)
+	t.Setenv("OPENAI_API_KEY", "k1, k2")
+	t.Setenv("GEMINI_API_KEY", "g1")
+	t.Setenv("AI_KIT_REGISTRY_TTL", "5m")
+	t.Setenv("AI_KIT_OPENAI_TIMEOUT", "30s")
+	t.Setenv("AI_KIT_COMPATIBLE", "together-ai")
+	t.Setenv("TOGETHER_AI_BASE_URL", "https://api.together.xyz")
+	t.Setenv("TOGETHER_AI_API_KEY", "tk")
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	if len(cfg.OpenAI.APIKeys) != 2 || cfg.OpenAI.Timeout != 30*time.Second || cfg.RegistryTTL != 5*time.Minute {
+		t.Fatalf("unexpected openai config: %+v", cfg.OpenAI)
+	}
+	if cfg.Google == nil || cfg.Google.APIKeys[0] != "g1" || cfg.Anthropic != nil {
+		t.Fatalf("unexpected providers: %+v", cfg)
+	}
+	if len(cfg.Compatible) != 1 || cfg.Compatible[0].Name != "together-ai" || cfg.Compatible[0].BaseURL != "https://api.together.xyz" {
+		t.Fatalf("unexpected compatible providers: %+v", cfg.Compatible)
+	}
+
+	t.Setenv("AI_KIT_OPENAI_TIMEOUT", "soon")
+	t.Setenv("TOGETHER_AI_BASE_URL", "")
+	_, err = ConfigFromEnv()
+	var cfgErr *ConfigError
+	if !errors.As(err, &cfgErr) || len(cfgErr.Problems) != 2 {
+		t.Fatalf("expected two problems, got %v", err)
+	}
+}
+
+func TestNewReportsAllMissingKeys(t *testing.T) {
+	_, err := New(Config{OpenAI: &OpenAIConfig{}, Anthropic: &AnthropicConfig{APIKeys: []string{" "}}, Ollama: &OllamaConfig{}})
+	var cfgErr *ConfigError
+	if !errors.As(err, &cfgErr) || len(cfgErr.Problems) != 2 {
+		t.Fatalf("expected both missing keys, got %v", err)
+	}
+	if _, err := New(Config{}); err == nil || !strings.Contains(err.Error(), "at least one provider") {
+		t.Fatalf("expected empty config error, got %v", err)
+	}
+}
+
+func TestCompatibleProviderUsesOwnNameAndTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") == "Bearer slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	in := GenerateInput{Provider: "groq", Model: "llama-3.3-70b", Messages: []Message{{Role: "user", Content: []ContentPart{{Type: "text", Text: "hi"}}}}}
+	kit, err := New(Config{Compatible: []CompatibleConfig{{Name: "groq", APIKey: "gsk", BaseURL: server.URL}}})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	if out, err := kit.Generate(context.Background(), in); err != nil || out.Text != "hi" {
+		t.Fatalf("generate: %q %v", out.Text, err)
+	}
+
+	slow, err := New(Config{Compatible: []CompatibleConfig{{Name: "groq", APIKey: "slow", BaseURL: server.URL, Timeout: 50 * time.Millisecond}}})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	if _, err := slow.Generate(context.Background(), in); err == nil {
+		t.Fatalf("expected the provider timeout to fire")
+	}
+}
@@ -12,6 +12,9 @@ const (
 	ErrorProviderUnavailable ErrorKind = "provider_unavailable"
 	ErrorValidation          ErrorKind = "validation_error"
 	ErrorUnsupported         ErrorKind = "unsupported"
+	ErrorStreamInterrupted   ErrorKind = "stream_interrupted"
+	ErrorStreamIncomplete    ErrorKind = "stream_incomplete"
+	ErrorStreamDecode        ErrorKind = "stream_decode_error"
 )
 
 type KitError struct {
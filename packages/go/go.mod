@@ -1,3 +1,8 @@
 module github.com/Volpestyle/ai-kit/packages/go
 
 go 1.22
+
+require (
+	github.com/coder/websocket v1.8.12
+	gopkg.in/yaml.v3 v3.0.1
+)
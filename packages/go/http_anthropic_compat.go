@@ -0,0 +1,472 @@
+package aikit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Anthropic Messages-compatible proxy: the reverse of the anthropic adapter.
+// Models use the same "provider/model" naming as the OpenAI proxy; a bare
+// model ID is routed to Anthropic so unmodified SDK clients keep working.
+
+type anthropicCompatRequest struct {
+	Model       string                   `json:"model"`
+	System      json.RawMessage          `json:"system"`
+	Messages    []anthropicCompatMessage `json:"messages"`
+	Tools       []anthropicCompatTool    `json:"tools"`
+	ToolChoice  *anthropicCompatChoice   `json:"tool_choice"`
+	MaxTokens   *int                     `json:"max_tokens"`
+	Temperature *float64                 `json:"temperature"`
+	TopP        *float64                 `json:"top_p"`
+	Stream      bool                     `json:"stream"`
+	Metadata    *struct {
+		UserID string `json:"user_id"`
+	} `json:"metadata"`
+}
+
+type anthropicCompatMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+type anthropicCompatBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"`
+	Source    *struct {
+		Type      string `json:"type"`
+		MediaType string `json:"media_type"`
+		Data      string `json:"data"`
+		URL       string `json:"url"`
+	} `json:"source"`
+}
+
+type anthropicCompatTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+type anthropicCompatChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+type anthropicCompatOutBlock struct {
+	Type  string      `json:"type"`
+	Text  *string     `json:"text,omitempty"`
+	ID    string      `json:"id,omitempty"`
+	Name  string      `json:"name,omitempty"`
+	Input interface{} `json:"input,omitempty"`
+}
+
+type anthropicCompatUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+type anthropicCompatResponse struct {
+	ID           string                    `json:"id"`
+	Type         string                    `json:"type"`
+	Role         string                    `json:"role"`
+	Model        string                    `json:"model"`
+	Content      []anthropicCompatOutBlock `json:"content"`
+	StopReason   *string                   `json:"stop_reason"`
+	StopSequence *string                   `json:"stop_sequence"`
+	Usage        anthropicCompatUsage      `json:"usage"`
+}
+
+// AnthropicMessagesHandler serves POST /v1/messages. Streaming requests
+// receive Anthropic SSE events (message_start, content_block_start,
+// content_block_delta, content_block_stop, message_delta, message_stop).
+func AnthropicMessagesHandler(h KitAPI) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req anthropicCompatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeAnthropicErrorStatus(w, http.StatusBadRequest, "invalid_request_error", "invalid JSON body")
+			return
+		}
+		input, err := req.toGenerateInput(h)
+		if err != nil {
+			writeAnthropicError(w, err)
+			return
+		}
+		id := newCompatID("msg_")
+		if req.Stream {
+			streamAnthropicCompat(w, r, h, input, id, req.Model)
+			return
+		}
+		output, err := h.Generate(r.Context(), input)
+		if err != nil {
+			writeAnthropicError(w, err)
+			return
+		}
+		content := []anthropicCompatOutBlock{}
+		if output.Text != "" {
+			text := output.Text
+			content = append(content, anthropicCompatOutBlock{Type: "text", Text: &text})
+		}
+		for _, call := range output.ToolCalls {
+			content = append(content, anthropicCompatOutBlock{
+				Type:  "tool_use",
+				ID:    call.ID,
+				Name:  call.Name,
+				Input: parseToolArguments(call.ArgumentsJSON),
+			})
+		}
+		stop := anthropicStopReason(output.FinishReason, len(output.ToolCalls) > 0)
+		resp := anthropicCompatResponse{
+			ID:         id,
+			Type:       "message",
+			Role:       "assistant",
+			Model:      req.Model,
+			Content:    content,
+			StopReason: &stop,
+		}
+		if output.Usage != nil {
+			resp.Usage = anthropicCompatUsage{InputTokens: output.Usage.InputTokens, OutputTokens: output.Usage.OutputTokens}
+		}
+		writeJSON(w, resp)
+	}
+}
+
+func streamAnthropicCompat(w http.ResponseWriter, r *http.Request, h KitAPI, input GenerateInput, id, model string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeAnthropicErrorStatus(w, http.StatusInternalServerError, "api_error", "streaming unsupported")
+		return
+	}
+	stream, err := h.StreamGenerate(r.Context(), input)
+	if err != nil {
+		writeAnthropicError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	send := func(event string, payload map[string]interface{}) bool {
+		payload["type"] = event
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return true
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+
+	// Kit tool calls are keyed by Index; Anthropic numbers every content
+	// block, text included, in order of appearance.
+	nextBlock := 0
+	textBlock := -1
+	toolBlocks := map[int]int{}
+	sent := map[int]string{}
+	sawTools := false
+	stopText := func() bool {
+		if textBlock < 0 {
+			return true
+		}
+		index := textBlock
+		textBlock = -1
+		return send("content_block_stop", map[string]interface{}{"index": index})
+	}
+
+	if !send("message_start", map[string]interface{}{"message": anthropicCompatResponse{
+		ID:      id,
+		Type:    "message",
+		Role:    "assistant",
+		Model:   model,
+		Content: []anthropicCompatOutBlock{},
+	}}) {
+		return
+	}
+	for chunk := range stream {
+		ok := true
+		switch chunk.Type {
+		case StreamChunkDelta:
+			if textBlock < 0 {
+				textBlock = nextBlock
+				nextBlock++
+				empty := ""
+				ok = send("content_block_start", map[string]interface{}{
+					"index":         textBlock,
+					"content_block": anthropicCompatOutBlock{Type: "text", Text: &empty},
+				})
+			}
+			ok = ok && send("content_block_delta", map[string]interface{}{
+				"index": textBlock,
+				"delta": map[string]string{"type": "text_delta", "text": chunk.TextDelta},
+			})
+		case StreamChunkToolCallStart:
+			sawTools = true
+			ok = stopText()
+			block := nextBlock
+			nextBlock++
+			toolBlocks[chunk.Index] = block
+			start := map[string]interface{}{"type": "tool_use", "input": map[string]interface{}{}}
+			if chunk.Call != nil {
+				start["id"] = chunk.Call.ID
+				start["name"] = chunk.Call.Name
+			}
+			ok = ok && send("content_block_start", map[string]interface{}{"index": block, "content_block": start})
+		case StreamChunkToolCallDelta:
+			block, open := toolBlocks[chunk.Index]
+			if !open {
+				continue
+			}
+			sent[chunk.Index] += chunk.Delta
+			ok = send("content_block_delta", map[string]interface{}{
+				"index": block,
+				"delta": map[string]string{"type": "input_json_delta", "partial_json": chunk.Delta},
+			})
+		case StreamChunkToolCallEnd:
+			block, open := toolBlocks[chunk.Index]
+			if !open {
+				continue
+			}
+			delete(toolBlocks, chunk.Index)
+			if rest := unsentArguments(chunk, sent[chunk.Index]); rest != "" {
+				ok = send("content_block_delta", map[string]interface{}{
+					"index": block,
+					"delta": map[string]string{"type": "input_json_delta", "partial_json": rest},
+				})
+			}
+			ok = ok && send("content_block_stop", map[string]interface{}{"index": block})
+		case StreamChunkMessageEnd:
+			ok = stopText()
+			stop := anthropicStopReason(chunk.FinishReason, sawTools)
+			usage := anthropicCompatUsage{}
+			if chunk.Usage != nil {
+				usage = anthropicCompatUsage{InputTokens: chunk.Usage.InputTokens, OutputTokens: chunk.Usage.OutputTokens}
+			}
+			ok = ok && send("message_delta", map[string]interface{}{
+				"delta": map[string]interface{}{"stop_reason": stop, "stop_sequence": nil},
+				"usage": usage,
+			})
+			ok = ok && send("message_stop", map[string]interface{}{})
+		case StreamChunkError:
+			if chunk.Error != nil {
+				_, errType := anthropicErrorShape(ErrorKind(chunk.Error.Kind))
+				send("error", map[string]interface{}{"error": map[string]string{
+					"type":    errType,
+					"message": chunk.Error.Message,
+				}})
+			}
+			return
+		}
+		if !ok {
+			return
+		}
+	}
+}
+
+func (req anthropicCompatRequest) toGenerateInput(h KitAPI) (GenerateInput, error) {
+	provider := ProviderAnthropic
+	model := req.Model
+	if strings.Contains(req.Model, "/") {
+		var err error
+		if provider, model, err = splitProviderModel(h, req.Model); err != nil {
+			return GenerateInput{}, err
+		}
+	}
+	input := GenerateInput{
+		Provider:    provider,
+		Model:       model,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+		TopP:        req.TopP,
+		Stream:      req.Stream,
+	}
+	system, err := decodeAnthropicSystem(req.System)
+	if err != nil {
+		return GenerateInput{}, err
+	}
+	if system != "" {
+		input.Messages = append(input.Messages, Message{Role: "system", Content: []ContentPart{{Type: "text", Text: system}}})
+	}
+	for _, message := range req.Messages {
+		converted, err := message.toMessages()
+		if err != nil {
+			return GenerateInput{}, err
+		}
+		input.Messages = append(input.Messages, converted...)
+	}
+	nameToolResults(input.Messages)
+	for _, tool := range req.Tools {
+		input.Tools = append(input.Tools, ToolDefinition{
+			Name:        tool.Name,
+			Description: tool.Description,
+			Parameters:  tool.InputSchema,
+		})
+	}
+	if choice := req.ToolChoice; choice != nil {
+		switch choice.Type {
+		case "auto", "any", "none":
+			input.ToolChoice = &ToolChoice{Type: choice.Type}
+		case "tool":
+			input.ToolChoice = &ToolChoice{Type: "tool", Name: choice.Name}
+		default:
+			return GenerateInput{}, compatValidation(fmt.Sprintf("unsupported tool_choice type %q", choice.Type))
+		}
+	}
+	if req.Metadata != nil && req.Metadata.UserID != "" {
+		input.Metadata = map[string]string{"user_id": req.Metadata.UserID}
+	}
+	return input, nil
+}
+
+// decodeAnthropicSystem accepts the system prompt as a string or as an array
+// of text blocks.
+func decodeAnthropicSystem(raw json.RawMessage) (string, error) {
+	blocks, text, err := decodeAnthropicContent(raw)
+	if err != nil {
+		return "", err
+	}
+	if text != nil {
+		return *text, nil
+	}
+	var parts []string
+	for _, block := range blocks {
+		if block.Type == "text" {
+			parts = append(parts, block.Text)
+		}
+	}
+	return strings.Join(parts, "\n"), nil
+}
+
+// decodeAnthropicContent returns either the string form of a content field or
+// its blocks.
+func decodeAnthropicContent(raw json.RawMessage) ([]anthropicCompatBlock, *string, error) {
+	trimmed := strings.TrimSpace(string(raw))
+	if trimmed == "" || trimmed == "null" {
+		return nil, nil, nil
+	}
+	if strings.HasPrefix(trimmed, `"`) {
+		var text string
+		if err := json.Unmarshal(raw, &text); err != nil {
+			return nil, nil, compatValidation(err.Error())
+		}
+		return nil, &text, nil
+	}
+	var blocks []anthropicCompatBlock
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return nil, nil, compatValidation("content must be a string or an array of content blocks")
+	}
+	return blocks, nil, nil
+}
+
+// toMessages splits tool_result blocks into Kit "tool" messages, which
+// splitAnthropicMessages folds back into a single user turn.
+func (m anthropicCompatMessage) toMessages() ([]Message, error) {
+	blocks, text, err := decodeAnthropicContent(m.Content)
+	if err != nil {
+		return nil, err
+	}
+	message := Message{Role: m.Role}
+	if text != nil {
+		message.Content = []ContentPart{{Type: "text", Text: *text}}
+		return []Message{message}, nil
+	}
+	var results []Message
+	for _, block := range blocks {
+		switch block.Type {
+		case "text":
+			message.Content = append(message.Content, ContentPart{Type: "text", Text: block.Text})
+		case "image":
+			if block.Source == nil {
+				return nil, compatValidation("image block is missing its source")
+			}
+			image := &ImageContent{URL: block.Source.URL}
+			if block.Source.Type == "base64" {
+				image = &ImageContent{Base64: block.Source.Data, MediaType: block.Source.MediaType}
+			}
+			message.Content = append(message.Content, ContentPart{Type: "image", Image: image})
+		case "tool_use":
+			args := "{}"
+			if len(block.Input) > 0 {
+				args = string(block.Input)
+			}
+			message.ToolCalls = append(message.ToolCalls, ToolCall{ID: block.ID, Name: block.Name, ArgumentsJSON: args})
+		case "tool_result":
+			output, err := decodeAnthropicSystem(block.Content)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, Message{
+				Role:       "tool",
+				ToolCallID: block.ToolUseID,
+				Content:    []ContentPart{{Type: "text", Text: output}},
+			})
+		default:
+			return nil, compatValidation(fmt.Sprintf("unsupported content block type %q", block.Type))
+		}
+	}
+	if len(message.Content) > 0 || len(message.ToolCalls) > 0 || len(results) == 0 {
+		results = append(results, message)
+	}
+	return results, nil
+}
+
+// anthropicStopReason maps provider finish reasons onto Anthropic's
+// stop_reason vocabulary.
+func anthropicStopReason(reason string, toolCalls bool) string {
+	if toolCalls {
+		return "tool_use"
+	}
+	switch strings.ToLower(reason) {
+	case "length", "max_tokens", "max_output_tokens":
+		return "max_tokens"
+	case "stop_sequence":
+		return "stop_sequence"
+	case "content_filter", "safety", "recitation", "blocklist", "prohibited_content", "spii", "refusal":
+		return "refusal"
+	case "tool_calls", "tool_use", "function_call":
+		return "tool_use"
+	}
+	return "end_turn"
+}
+
+// anthropicErrorShape is the inverse of anthropicStreamErrorKind.
+func anthropicErrorShape(kind ErrorKind) (int, string) {
+	switch kind {
+	case ErrorValidation, ErrorUnsupported:
+		return http.StatusBadRequest, "invalid_request_error"
+	case ErrorProviderAuth:
+		return http.StatusUnauthorized, "authentication_error"
+	case ErrorProviderNotFound:
+		return http.StatusNotFound, "not_found_error"
+	case ErrorProviderRateLimit:
+		return http.StatusTooManyRequests, "rate_limit_error"
+	case ErrorProviderUnavailable:
+		return 529, "overloaded_error"
+	}
+	return http.StatusInternalServerError, "api_error"
+}
+
+func writeAnthropicError(w http.ResponseWriter, err error) {
+	var kitErr *KitError
+	if !errors.As(err, &kitErr) {
+		writeAnthropicErrorStatus(w, http.StatusInternalServerError, "api_error", err.Error())
+		return
+	}
+	status, errType := anthropicErrorShape(kitErr.Kind)
+	writeAnthropicErrorStatus(w, status, errType, kitErr.Message)
+}
+
+func writeAnthropicErrorStatus(w http.ResponseWriter, status int, errType, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"type":  "error",
+		"error": map[string]string{"type": errType, "message": message},
+	})
+}
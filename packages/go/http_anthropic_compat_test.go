@@ -0,0 +1,222 @@
+package aikit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func postAnthropicCompat(t *testing.T, kit KitAPI, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	AnthropicMessagesHandler(kit)(rec, req)
+	return rec
+}
+
+func TestAnthropicCompatTranslatesRequest(t *testing.T) {
+	kit := &recordingKit{mockKit: mockKit{generateResp: GenerateOutput{Text: "hi"}}}
+	rec := postAnthropicCompat(t, kit, `{
+		"model": "claude-sonnet-4-5",
+		"max_tokens": 128,
+		"system": [{"type": "text", "text": "be brief"}],
+		"messages": [
+			{"role": "user", "content": [
+				{"type": "text", "text": "look"},
+				{"type": "image", "source": {"type": "base64", "media_type": "image/png", "data": "AAAA"}}
+			]},
+			{"role": "assistant", "content": [
+				{"type": "tool_use", "id": "toolu_1", "name": "lookup", "input": {"q": 1}},
+				{"type": "tool_use", "id": "toolu_2", "name": "lookup", "input": {"q": 2}}
+			]},
+			{"role": "user", "content": [
+				{"type": "tool_result", "tool_use_id": "toolu_1", "content": "one"},
+				{"type": "tool_result", "tool_use_id": "toolu_2", "content": [{"type": "text", "text": "two"}]}
+			]}
+		],
+		"tools": [{"name": "lookup", "input_schema": {"type": "object"}}],
+		"tool_choice": {"type": "tool", "name": "lookup"},
+		"metadata": {"user_id": "u1"}
+	}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	in := kit.inputs[0]
+	if in.Provider != ProviderAnthropic || in.Model != "claude-sonnet-4-5" || in.MaxTokens == nil || *in.MaxTokens != 128 {
+		t.Fatalf("unexpected routing: %+v", in)
+	}
+	if len(in.Messages) != 5 || in.Messages[0].Role != "system" || in.Messages[0].Content[0].Text != "be brief" {
+		t.Fatalf("unexpected messages: %+v", in.Messages)
+	}
+	if image := in.Messages[1].Content[1].Image; image == nil || image.Base64 != "AAAA" || image.MediaType != "image/png" {
+		t.Fatalf("unexpected image: %+v", image)
+	}
+	if calls := in.Messages[2].ToolCalls; len(calls) != 2 || calls[1].ArgumentsJSON != `{"q": 2}` {
+		t.Fatalf("unexpected tool calls: %+v", calls)
+	}
+	if in.Messages[3].Role != "tool" || in.Messages[3].ToolCallID != "toolu_1" || in.Messages[4].Content[0].Text != "two" {
+		t.Fatalf("unexpected tool results: %+v %+v", in.Messages[3], in.Messages[4])
+	}
+	if in.ToolChoice == nil || in.ToolChoice.Type != "tool" || in.ToolChoice.Name != "lookup" || in.Metadata["user_id"] != "u1" {
+		t.Fatalf("unexpected tool choice or metadata: %+v %+v", in.ToolChoice, in.Metadata)
+	}
+
+	// Round-tripping through the anthropic adapter mapping restores one
+	// user turn holding both tool results.
+	_, mapped := splitAnthropicMessages(in.Messages)
+	if len(mapped) != 3 || len(mapped[2]["content"].([]map[string]interface{})) != 2 {
+		t.Fatalf("tool results did not fold back into one turn: %+v", mapped)
+	}
+}
+
+func TestAnthropicCompatNamesToolResultsForGemini(t *testing.T) {
+	var contents []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Contents []map[string]interface{} `json:"contents"`
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		contents = payload.Contents
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"sunny"}]},"finishReason":"STOP"}]}`))
+	}))
+	defer server.Close()
+	kit, err := New(Config{Google: &GoogleConfig{APIKey: "k", BaseURL: server.URL}})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	rec := postAnthropicCompat(t, kit, `{
+		"model": "google/gemini-2.5-flash",
+		"max_tokens": 32,
+		"messages": [
+			{"role": "user", "content": "weather?"},
+			{"role": "assistant", "content": [{"type": "tool_use", "id": "toolu_1", "name": "weather", "input": {"city": "Paris"}}]},
+			{"role": "user", "content": [{"type": "tool_result", "tool_use_id": "toolu_1", "content": "sunny"}]}
+		]
+	}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(contents) != 3 {
+		t.Fatalf("expected three gemini turns, got %+v", contents)
+	}
+	part := contents[2]["parts"].([]interface{})[0].(map[string]interface{})
+	response, _ := part["functionResponse"].(map[string]interface{})
+	if response == nil || response["name"] != "weather" {
+		t.Fatalf("expected the functionResponse to be named after its call, got %+v", part)
+	}
+}
+
+func TestAnthropicCompatRoutesPrefixedModels(t *testing.T) {
+	kit := &recordingKit{mockKit: mockKit{generateResp: GenerateOutput{Text: "hi"}}}
+	postAnthropicCompat(t, kit, `{"model":"google/gemini-2.5-flash","max_tokens":8,"messages":[{"role":"user","content":"hi"}]}`)
+	if in := kit.inputs[0]; in.Provider != ProviderGoogle || in.Model != "gemini-2.5-flash" {
+		t.Fatalf("unexpected routing: %s %s", in.Provider, in.Model)
+	}
+}
+
+func TestAnthropicCompatReturnsMessage(t *testing.T) {
+	kit := &recordingKit{mockKit: mockKit{generateResp: GenerateOutput{
+		Text:         "calling",
+		ToolCalls:    []ToolCall{{ID: "call_1", Name: "lookup", ArgumentsJSON: `{"q":1}`}},
+		FinishReason: "tool_calls",
+		Usage:        &Usage{InputTokens: 3, OutputTokens: 4},
+	}}}
+	rec := postAnthropicCompat(t, kit, `{"model":"openai/gpt-4o","max_tokens":8,"messages":[{"role":"user","content":"hi"}]}`)
+	var payload anthropicCompatResponse
+	readBody(t, rec.Result().Body, &payload)
+	if payload.Type != "message" || payload.Role != "assistant" || payload.Model != "openai/gpt-4o" {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+	if len(payload.Content) != 2 || *payload.Content[0].Text != "calling" || payload.Content[1].Type != "tool_use" {
+		t.Fatalf("unexpected content: %+v", payload.Content)
+	}
+	if input, _ := payload.Content[1].Input.(map[string]interface{}); input["q"] != 1.0 {
+		t.Fatalf("unexpected tool input: %+v", payload.Content[1].Input)
+	}
+	if payload.StopReason == nil || *payload.StopReason != "tool_use" || payload.Usage.OutputTokens != 4 {
+		t.Fatalf("unexpected stop reason or usage: %+v", payload)
+	}
+}
+
+func TestAnthropicCompatStreams(t *testing.T) {
+	kit := &recordingKit{mockKit: mockKit{streamChunks: []StreamChunk{
+		{Type: StreamChunkMessageStart},
+		{Type: StreamChunkDelta, TextDelta: "Hel"},
+		{Type: StreamChunkDelta, TextDelta: "lo"},
+		{Type: StreamChunkToolCallStart, Index: 3, Call: &ToolCall{ID: "call_1", Name: "lookup"}},
+		{Type: StreamChunkToolCallDelta, Index: 3, Delta: `{"q":`, Call: &ToolCall{ID: "call_1", Name: "lookup"}},
+		{Type: StreamChunkToolCallDelta, Index: 3, Delta: `1}`, Call: &ToolCall{ID: "call_1", Name: "lookup"}},
+		{Type: StreamChunkToolCallEnd, Index: 3, Call: &ToolCall{ID: "call_1", Name: "lookup", ArgumentsJSON: `{"q":1}`}},
+		{Type: StreamChunkMessageEnd, FinishReason: "STOP", Usage: &Usage{InputTokens: 1, OutputTokens: 2}},
+	}}}
+	rec := postAnthropicCompat(t, kit, `{"model":"claude-sonnet-4-5","max_tokens":8,"stream":true,"messages":[{"role":"user","content":"hi"}]}`)
+	events := parseSSEEvents(rec.Body.String())
+	var names []string
+	for _, event := range events {
+		names = append(names, event.Event)
+		var payload map[string]interface{}
+		if err := json.Unmarshal([]byte(event.Data), &payload); err != nil || payload["type"] != event.Event {
+			t.Fatalf("event %s has mismatched payload %s", event.Event, event.Data)
+		}
+	}
+	want := []string{
+		"message_start",
+		"content_block_start", "content_block_delta", "content_block_delta", "content_block_stop",
+		"content_block_start", "content_block_delta", "content_block_delta", "content_block_stop",
+		"message_delta", "message_stop",
+	}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected events:\n got %v\nwant %v", names, want)
+	}
+	if !strings.Contains(events[5].Data, `"index":1`) || !strings.Contains(events[5].Data, `"name":"lookup"`) {
+		t.Fatalf("tool block should be content block 1: %s", events[5].Data)
+	}
+	if !strings.Contains(events[9].Data, `"stop_reason":"tool_use"`) || !strings.Contains(events[9].Data, `"output_tokens":2`) {
+		t.Fatalf("unexpected message_delta: %s", events[9].Data)
+	}
+}
+
+func TestAnthropicCompatErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		kit    *recordingKit
+		body   string
+		status int
+		typ    string
+	}{
+		{"invalid json", &recordingKit{}, `{`, http.StatusBadRequest, "invalid_request_error"},
+		{"bad block", &recordingKit{}, `{"model":"claude","messages":[{"role":"user","content":[{"type":"video"}]}]}`, http.StatusBadRequest, "invalid_request_error"},
+		{"unknown provider", &recordingKit{}, `{"model":"acme/x","messages":[]}`, http.StatusNotFound, "not_found_error"},
+		{"overloaded", &recordingKit{err: &KitError{Kind: ErrorProviderUnavailable, Message: "busy"}}, `{"model":"claude","messages":[]}`, 529, "overloaded_error"},
+	}
+	for _, tc := range cases {
+		rec := postAnthropicCompat(t, tc.kit, tc.body)
+		if rec.Code != tc.status {
+			t.Fatalf("%s: expected %d, got %d", tc.name, tc.status, rec.Code)
+		}
+		var payload struct {
+			Type  string `json:"type"`
+			Error struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		readBody(t, rec.Result().Body, &payload)
+		if payload.Type != "error" || payload.Error.Type != tc.typ || payload.Error.Message == "" {
+			t.Fatalf("%s: unexpected error payload %+v", tc.name, payload)
+		}
+	}
+
+	kit := &recordingKit{mockKit: mockKit{streamChunks: []StreamChunk{
+		{Type: StreamChunkError, Error: &ChunkError{Kind: string(ErrorProviderRateLimit), Message: "slow down"}},
+	}}}
+	rec := postAnthropicCompat(t, kit, `{"model":"claude","stream":true,"messages":[]}`)
+	events := parseSSEEvents(rec.Body.String())
+	last := events[len(events)-1]
+	if last.Event != "error" || !strings.Contains(last.Data, `"rate_limit_error"`) {
+		t.Fatalf("expected error event last, got %+v", last)
+	}
+}
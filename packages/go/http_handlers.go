@@ -19,27 +19,64 @@ type KitAPI interface {
 
 type ModelsHandlerOptions struct {
 	Refresh bool
+	// Partial always answers with a ListModelsResult, as ?partial=true does.
+	Partial bool
+	// ProviderTimeout bounds each provider's listing (10s when zero).
+	ProviderTimeout time.Duration
 }
 
+// detailedModelLister is implemented by *Kit. Other KitAPI implementations
+// answer partial listings without per-provider errors.
+type detailedModelLister interface {
+	ListModelsDetailed(ctx context.Context, opts *ListModelsOptions) (ListModelsResult, error)
+}
+
+// ModelsHandler answers with a JSON array of models, failing when any
+// provider cannot be listed. With ?partial=true it answers with a
+// ListModelsResult instead: the providers that answered, plus an error entry
+// for each one that did not. Partial output stays opt-in because it changes
+// the response from the array every ai-kit server returns for
+// /provider-models to an object, and because a client that did not ask for
+// it should see a failing provider as an error, not as a shorter list.
 func ModelsHandler(h KitAPI, opts *ModelsHandlerOptions) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 		defer cancel()
 		query := r.URL.Query()
-		providers := parseProviders(query.Get("providers"))
-		refresh := shouldRefresh(query["refresh"])
-		if opts != nil && opts.Refresh {
-			refresh = true
-		}
-		models, err := h.ListModels(ctx, &ListModelsOptions{
-			Providers: providers,
-			Refresh:   refresh,
-		})
+		listOpts := &ListModelsOptions{
+			Providers:       parseProviders(h, query.Get("providers")),
+			Refresh:         queryFlag(query["refresh"]),
+			Partial:         queryFlag(query["partial"]),
+			ProviderTimeout: 10 * time.Second,
+		}
+		if opts != nil {
+			listOpts.Refresh = listOpts.Refresh || opts.Refresh
+			listOpts.Partial = listOpts.Partial || opts.Partial
+			if opts.ProviderTimeout > 0 {
+				listOpts.ProviderTimeout = opts.ProviderTimeout
+			}
+		}
+		if !listOpts.Partial {
+			models, err := h.ListModels(ctx, listOpts)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			writeJSON(w, models)
+			return
+		}
+		var result ListModelsResult
+		var err error
+		if lister, ok := h.(detailedModelLister); ok {
+			result, err = lister.ListModelsDetailed(ctx, listOpts)
+		} else {
+			result.Models, err = h.ListModels(ctx, listOpts)
+		}
 		if err != nil {
 			writeError(w, err)
 			return
 		}
-		writeJSON(w, models)
+		writeJSON(w, result)
 	}
 }
 
@@ -107,67 +144,64 @@ func TranscribeHandler(h KitAPI) http.HandlerFunc {
 	}
 }
 
-func GenerateSSEHandler(h KitAPI) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		var input GenerateInput
-		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-			http.Error(w, "invalid JSON", http.StatusBadRequest)
-			return
-		}
-		ch, err := h.StreamGenerate(r.Context(), input)
-		if err != nil {
-			writeError(w, err)
-			return
-		}
-		w.Header().Set("Content-Type", "text/event-stream")
-		w.Header().Set("Cache-Control", "no-cache")
-		flusher, ok := w.(http.Flusher)
-		if !ok {
-			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
-			return
-		}
-		encoder := json.NewEncoder(w)
-		for chunk := range ch {
-			w.Write([]byte("event: chunk\n"))
-			w.Write([]byte("data: "))
-			if err := encoder.Encode(chunk); err != nil {
-				break
-			}
-			w.Write([]byte("\n"))
-			flusher.Flush()
-		}
-		w.Write([]byte("event: done\n"))
-		w.Write([]byte("data: {\"ok\":true}\n\n"))
-		flusher.Flush()
-	}
-}
-
-func parseProviders(value string) []Provider {
+func parseProviders(h KitAPI, value string) []Provider {
 	if value == "" {
 		return nil
 	}
 	parts := strings.Split(value, ",")
 	var providers []Provider
 	for _, part := range parts {
-		switch strings.TrimSpace(strings.ToLower(part)) {
-		case string(ProviderOpenAI):
-			providers = append(providers, ProviderOpenAI)
-		case string(ProviderAnthropic):
-			providers = append(providers, ProviderAnthropic)
-		case string(ProviderGoogle):
-			providers = append(providers, ProviderGoogle)
-		case string(ProviderXAI):
-			providers = append(providers, ProviderXAI)
-		case string(ProviderOllama):
-			providers = append(providers, ProviderOllama)
-		case string(ProviderLocal):
-			providers = append(providers, ProviderLocal)
+		if provider, ok := resolveProvider(h, part); ok {
+			providers = append(providers, provider)
 		}
 	}
 	return providers
 }
 
-func shouldRefresh(values []string) bool {
+func parseProvider(value string) (Provider, bool) {
+	switch strings.TrimSpace(strings.ToLower(value)) {
+	case string(ProviderOpenAI):
+		return ProviderOpenAI, true
+	case string(ProviderAnthropic):
+		return ProviderAnthropic, true
+	case string(ProviderGoogle):
+		return ProviderGoogle, true
+	case string(ProviderXAI):
+		return ProviderXAI, true
+	case string(ProviderOllama):
+		return ProviderOllama, true
+	case string(ProviderLocal):
+		return ProviderLocal, true
+	}
+	return "", false
+}
+
+// providerLister is implemented by *Kit. Handlers given one also accept the
+// compatible providers it was configured with; other KitAPI implementations
+// get the built-in providers only.
+type providerLister interface {
+	Providers() []Provider
+}
+
+// resolveProvider is parseProvider extended with h's configured providers.
+func resolveProvider(h KitAPI, value string) (Provider, bool) {
+	if provider, ok := parseProvider(value); ok {
+		return provider, true
+	}
+	lister, ok := h.(providerLister)
+	if !ok {
+		return "", false
+	}
+	name := strings.TrimSpace(value)
+	for _, provider := range lister.Providers() {
+		if strings.EqualFold(string(provider), name) {
+			return provider, true
+		}
+	}
+	return "", false
+}
+
+func queryFlag(values []string) bool {
 	if len(values) == 0 {
 		return false
 	}
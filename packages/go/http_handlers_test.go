@@ -1,13 +1,17 @@
 package aikit
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 type mockKit struct {
@@ -115,6 +119,227 @@ func TestGenerateSSEHandlerStreams(t *testing.T) {
 	if !bytes.Contains([]byte(result), []byte("event: chunk")) {
 		t.Fatalf("expected SSE chunk events, got %s", result)
 	}
+	if !strings.Contains(result, "event: done\ndata: {\"ok\":true}") {
+		t.Fatalf("expected ok done event, got %s", result)
+	}
+}
+
+type sseTestEvent struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+func parseSSEEvents(raw string) []sseTestEvent {
+	var events []sseTestEvent
+	for _, block := range strings.Split(raw, "\n\n") {
+		var event sseTestEvent
+		for _, line := range strings.Split(block, "\n") {
+			switch {
+			case strings.HasPrefix(line, "id: "):
+				event.ID = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				event.Event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				event.Data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+		if event.Event != "" || event.Data != "" {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func TestGenerateSSEHandlerEventIDsAndErrorDone(t *testing.T) {
+	kit := &mockKit{
+		streamChunks: []StreamChunk{
+			{Type: StreamChunkDelta, TextDelta: "hel"},
+			{Type: StreamChunkError, Error: &ChunkError{Kind: string(ErrorStreamInterrupted), Message: "reset"}},
+		},
+	}
+	body := bytes.NewBufferString(`{"provider":"openai","model":"gpt","messages":[]}`)
+	req := httptest.NewRequest(http.MethodPost, "/generate/stream", body)
+	rec := httptest.NewRecorder()
+	GenerateSSEHandler(kit)(rec, req)
+	events := parseSSEEvents(rec.Body.String())
+	if len(events) != 3 || events[0].Event != "chunk" || events[1].Event != "error" || events[2].Event != "done" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	for idx, event := range events {
+		if event.ID != strconv.Itoa(idx+1) {
+			t.Fatalf("expected increasing ids, got %+v", events)
+		}
+	}
+	var done struct {
+		OK    bool        `json:"ok"`
+		Error *ChunkError `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(events[2].Data), &done); err != nil || done.OK || done.Error == nil || done.Error.Kind != string(ErrorStreamInterrupted) {
+		t.Fatalf("expected ok:false done with the error, got %s", events[2].Data)
+	}
+}
+
+// chanKit streams whatever the test sends on chunks and records the stream
+// context so tests can observe cancellation.
+type chanKit struct {
+	mockKit
+	chunks chan StreamChunk
+	ctxs   chan context.Context
+}
+
+func newChanKit() *chanKit {
+	return &chanKit{chunks: make(chan StreamChunk), ctxs: make(chan context.Context, 1)}
+}
+
+func (k *chanKit) StreamGenerate(ctx context.Context, in GenerateInput) (<-chan StreamChunk, error) {
+	k.ctxs <- ctx
+	out := make(chan StreamChunk)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case chunk, ok := <-k.chunks:
+				if !ok {
+					return
+				}
+				select {
+				case out <- chunk:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func TestGenerateSSEHandlerSendsHeartbeats(t *testing.T) {
+	kit := newChanKit()
+	handler := GenerateSSEHandlerWithOptions(kit, &SSEHandlerOptions{HeartbeatInterval: 5 * time.Millisecond})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		kit.chunks <- StreamChunk{Type: StreamChunkDelta, TextDelta: "late"}
+		close(kit.chunks)
+	}()
+	resp, err := http.Post(server.URL, "application/json", strings.NewReader(`{"provider":"openai","model":"gpt"}`))
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(raw), ": heartbeat") || !strings.Contains(string(raw), `"ok":true`) {
+		t.Fatalf("expected heartbeats before the chunk, got %s", raw)
+	}
+}
+
+func TestSSEStreamReleasesDeliveredRecordsWithoutReplay(t *testing.T) {
+	var id uint64
+	nextID := func() uint64 { id++; return id }
+	live := newSSEStream(func() {}, 0, nextID, false)
+	kept := newSSEStream(func() {}, time.Minute, nextID, true)
+	for _, stream := range []*sseStream{live, kept} {
+		for range 3 {
+			stream.append("chunk", []byte(`{}`), false)
+		}
+	}
+	if records, _, _ := live.since(2); len(records) != 1 || records[0].id != 3 {
+		t.Fatalf("expected the record after id 2, got %+v", records)
+	}
+	if records, _, _ := live.since(3); len(records) != 0 || len(live.records) != 0 {
+		t.Fatalf("expected delivered records to be released, still holding %+v", live.records)
+	}
+	if records, _, _ := kept.since(5); len(records) != 1 || records[0].id != 6 || len(kept.records) != 3 {
+		t.Fatalf("expected replayable records to be kept, got %+v of %+v", records, kept.records)
+	}
+}
+
+func TestGenerateSSEHandlerCancelsOnDisconnect(t *testing.T) {
+	kit := newChanKit()
+	server := httptest.NewServer(GenerateSSEHandler(kit))
+	defer server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, strings.NewReader(`{"provider":"openai","model":"gpt"}`))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	streamCtx := <-kit.ctxs
+	cancel()
+	resp.Body.Close()
+	select {
+	case <-streamCtx.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatalf("upstream context was not cancelled after the client disconnected")
+	}
+}
+
+func TestGenerateSSEHandlerResumesFromLastEventID(t *testing.T) {
+	kit := newChanKit()
+	replay := NewSSEReplayBuffer(time.Minute)
+	server := httptest.NewServer(GenerateSSEHandlerWithOptions(kit, &SSEHandlerOptions{Replay: replay}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, strings.NewReader(`{"provider":"openai","model":"gpt"}`))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	streamCtx := <-kit.ctxs
+	kit.chunks <- StreamChunk{Type: StreamChunkDelta, TextDelta: "one"}
+	reader := bufio.NewReader(resp.Body)
+	var lastID string
+	for lastID == "" {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		if strings.HasPrefix(line, "id: ") {
+			lastID = strings.TrimSpace(strings.TrimPrefix(line, "id: "))
+		}
+	}
+	cancel()
+	resp.Body.Close()
+
+	// The generation keeps running while the client is away.
+	kit.chunks <- StreamChunk{Type: StreamChunkDelta, TextDelta: "two"}
+	close(kit.chunks)
+	if streamCtx.Err() != nil {
+		t.Fatalf("generation was cancelled before the replay TTL elapsed")
+	}
+
+	resume, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resume.Header.Set("Last-Event-ID", lastID)
+	resp, err = http.DefaultClient.Do(resume)
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(resp.Body)
+	events := parseSSEEvents(string(raw))
+	if len(events) != 2 || !strings.Contains(events[0].Data, `"two"`) || events[1].Event != "done" {
+		t.Fatalf("expected the missed chunk and done, got %+v", events)
+	}
+	first, _ := strconv.Atoi(lastID)
+	if next, _ := strconv.Atoi(events[0].ID); next <= first {
+		t.Fatalf("expected ids to keep increasing, got %s after %s", events[0].ID, lastID)
+	}
+
+	expired, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	expired.Header.Set("Last-Event-ID", "999")
+	resp, err = http.DefaultClient.Do(expired)
+	if err != nil {
+		t.Fatalf("expired: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusGone {
+		t.Fatalf("expected 410 for unknown event id, got %d", resp.StatusCode)
+	}
 }
 
 func readBody[T any](t *testing.T, r io.ReadCloser, target *T) {
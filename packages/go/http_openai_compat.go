@@ -0,0 +1,570 @@
+package aikit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OpenAI-compatible proxy. Requests name models as "provider/model" (for
+// example "anthropic/claude-sonnet-4-5"); the prefix selects the Kit provider
+// and normalizeModelID strips it before the call is routed.
+
+type openAICompatRequest struct {
+	Model               string                 `json:"model"`
+	Messages            []openAICompatMessage  `json:"messages"`
+	Tools               []openAICompatTool     `json:"tools"`
+	ToolChoice          json.RawMessage        `json:"tool_choice"`
+	ResponseFormat      *openAICompatFormat    `json:"response_format"`
+	Temperature         *float64               `json:"temperature"`
+	TopP                *float64               `json:"top_p"`
+	MaxTokens           *int                   `json:"max_tokens"`
+	MaxCompletionTokens *int                   `json:"max_completion_tokens"`
+	Stream              bool                   `json:"stream"`
+	StreamOptions       *openAICompatStreamOpt `json:"stream_options"`
+	User                string                 `json:"user"`
+}
+
+type openAICompatStreamOpt struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type openAICompatMessage struct {
+	Role       string                   `json:"role"`
+	Content    json.RawMessage          `json:"content"`
+	Name       string                   `json:"name,omitempty"`
+	ToolCalls  []openAIFunctionToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string                   `json:"tool_call_id,omitempty"`
+}
+
+type openAICompatPart struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	ImageURL *struct {
+		URL string `json:"url"`
+	} `json:"image_url"`
+}
+
+type openAICompatTool struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string                 `json:"name"`
+		Description string                 `json:"description"`
+		Parameters  map[string]interface{} `json:"parameters"`
+	} `json:"function"`
+}
+
+type openAICompatFormat struct {
+	Type       string `json:"type"`
+	JsonSchema *struct {
+		Name   string                 `json:"name"`
+		Schema map[string]interface{} `json:"schema"`
+		Strict bool                   `json:"strict"`
+	} `json:"json_schema"`
+}
+
+type openAICompatUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+type openAICompatResponseMessage struct {
+	Role      string                    `json:"role,omitempty"`
+	Content   *string                   `json:"content,omitempty"`
+	ToolCalls []openAICompatToolCallOut `json:"tool_calls,omitempty"`
+}
+
+type openAICompatToolCallOut struct {
+	Index    *int   `json:"index,omitempty"`
+	ID       string `json:"id,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Function struct {
+		Name      string `json:"name,omitempty"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type openAICompatChoice struct {
+	Index        int                          `json:"index"`
+	Message      *openAICompatResponseMessage `json:"message,omitempty"`
+	Delta        *openAICompatResponseMessage `json:"delta,omitempty"`
+	FinishReason *string                      `json:"finish_reason"`
+}
+
+type openAICompatCompletion struct {
+	ID      string               `json:"id"`
+	Object  string               `json:"object"`
+	Created int64                `json:"created"`
+	Model   string               `json:"model"`
+	Choices []openAICompatChoice `json:"choices"`
+	Usage   *openAICompatUsage   `json:"usage,omitempty"`
+}
+
+type openAICompatModel struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+type openAICompatError struct {
+	Error openAICompatErrorBody `json:"error"`
+}
+
+type openAICompatErrorBody struct {
+	Message string  `json:"message"`
+	Type    string  `json:"type"`
+	Param   *string `json:"param"`
+	Code    *string `json:"code"`
+}
+
+// OpenAIModelsHandler serves GET /v1/models with every model Kit can list,
+// named "provider/model".
+func OpenAIModelsHandler(h KitAPI) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+		defer cancel()
+		models, err := h.ListModels(ctx, &ListModelsOptions{})
+		if err != nil {
+			writeOpenAIError(w, err)
+			return
+		}
+		data := make([]openAICompatModel, 0, len(models))
+		for _, model := range models {
+			data = append(data, openAICompatModel{
+				ID:      string(model.Provider) + "/" + normalizeModelID(model.Provider, model.ID),
+				Object:  "model",
+				OwnedBy: string(model.Provider),
+			})
+		}
+		writeJSON(w, map[string]interface{}{"object": "list", "data": data})
+	}
+}
+
+// OpenAIChatCompletionsHandler serves POST /v1/chat/completions. Streaming
+// requests receive `data:` chat.completion.chunk events and a final
+// `data: [DONE]`.
+func OpenAIChatCompletionsHandler(h KitAPI) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req openAICompatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeOpenAIErrorStatus(w, http.StatusBadRequest, "invalid_request_error", "invalid JSON body", "")
+			return
+		}
+		input, err := req.toGenerateInput(h)
+		if err != nil {
+			writeOpenAIError(w, err)
+			return
+		}
+		id := newCompatID("chatcmpl-")
+		if req.Stream {
+			includeUsage := req.StreamOptions != nil && req.StreamOptions.IncludeUsage
+			streamOpenAICompat(w, r, h, input, id, req.Model, includeUsage)
+			return
+		}
+		output, err := h.Generate(r.Context(), input)
+		if err != nil {
+			writeOpenAIError(w, err)
+			return
+		}
+		message := &openAICompatResponseMessage{Role: "assistant"}
+		if output.Text != "" || len(output.ToolCalls) == 0 {
+			message.Content = &output.Text
+		}
+		for _, call := range output.ToolCalls {
+			out := openAICompatToolCallOut{ID: call.ID, Type: "function"}
+			out.Function.Name = call.Name
+			out.Function.Arguments = call.ArgumentsJSON
+			message.ToolCalls = append(message.ToolCalls, out)
+		}
+		finish := openAIFinishReason(output.FinishReason, len(output.ToolCalls) > 0)
+		writeJSON(w, openAICompatCompletion{
+			ID:      id,
+			Object:  "chat.completion",
+			Created: time.Now().Unix(),
+			Model:   req.Model,
+			Choices: []openAICompatChoice{{Message: message, FinishReason: &finish}},
+			Usage:   openAICompatUsageFrom(output.Usage),
+		})
+	}
+}
+
+func streamOpenAICompat(w http.ResponseWriter, r *http.Request, h KitAPI, input GenerateInput, id, model string, includeUsage bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeOpenAIErrorStatus(w, http.StatusInternalServerError, "server_error", "streaming unsupported", "")
+		return
+	}
+	stream, err := h.StreamGenerate(r.Context(), input)
+	if err != nil {
+		writeOpenAIError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	created := time.Now().Unix()
+	send := func(payload interface{}) bool {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return true
+		}
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+	delta := func(message *openAICompatResponseMessage, finish *string) bool {
+		return send(openAICompatCompletion{
+			ID:      id,
+			Object:  "chat.completion.chunk",
+			Created: created,
+			Model:   model,
+			Choices: []openAICompatChoice{{Delta: message, FinishReason: finish}},
+		})
+	}
+	toolDelta := func(index int, call openAICompatToolCallOut) bool {
+		call.Index = &index
+		return delta(&openAICompatResponseMessage{ToolCalls: []openAICompatToolCallOut{call}}, nil)
+	}
+
+	empty := ""
+	if !delta(&openAICompatResponseMessage{Role: "assistant", Content: &empty}, nil) {
+		return
+	}
+	// Arguments sent per tool call, so tool_call_end only has to flush what
+	// the deltas did not already carry.
+	sent := map[int]string{}
+	sawTools := false
+	for chunk := range stream {
+		ok := true
+		switch chunk.Type {
+		case StreamChunkDelta:
+			text := chunk.TextDelta
+			ok = delta(&openAICompatResponseMessage{Content: &text}, nil)
+		case StreamChunkToolCallStart:
+			sawTools = true
+			call := openAICompatToolCallOut{Type: "function"}
+			if chunk.Call != nil {
+				call.ID = chunk.Call.ID
+				call.Function.Name = chunk.Call.Name
+			}
+			ok = toolDelta(chunk.Index, call)
+		case StreamChunkToolCallDelta:
+			var call openAICompatToolCallOut
+			call.Function.Arguments = chunk.Delta
+			sent[chunk.Index] += chunk.Delta
+			ok = toolDelta(chunk.Index, call)
+		case StreamChunkToolCallEnd:
+			rest := unsentArguments(chunk, sent[chunk.Index])
+			if rest == "" {
+				continue
+			}
+			var call openAICompatToolCallOut
+			call.Function.Arguments = rest
+			ok = toolDelta(chunk.Index, call)
+		case StreamChunkMessageEnd:
+			finish := openAIFinishReason(chunk.FinishReason, sawTools)
+			ok = delta(&openAICompatResponseMessage{}, &finish)
+			if ok && includeUsage {
+				ok = send(openAICompatCompletion{
+					ID:      id,
+					Object:  "chat.completion.chunk",
+					Created: created,
+					Model:   model,
+					Choices: []openAICompatChoice{},
+					Usage:   openAICompatUsageFrom(chunk.Usage),
+				})
+			}
+		case StreamChunkError:
+			// OpenAI reports mid-stream failures as an error payload and ends
+			// the stream without [DONE].
+			if chunk.Error != nil {
+				_, errType, code := openAIErrorShape(ErrorKind(chunk.Error.Kind))
+				send(openAICompatError{Error: openAICompatErrorBody{
+					Message: chunk.Error.Message,
+					Type:    errType,
+					Code:    optionalString(code),
+				}})
+			}
+			return
+		}
+		if !ok {
+			return
+		}
+	}
+	if r.Context().Err() != nil {
+		return
+	}
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	flusher.Flush()
+}
+
+func (req openAICompatRequest) toGenerateInput(h KitAPI) (GenerateInput, error) {
+	provider, model, err := splitProviderModel(h, req.Model)
+	if err != nil {
+		return GenerateInput{}, err
+	}
+	input := GenerateInput{
+		Provider:    provider,
+		Model:       model,
+		Temperature: req.Temperature,
+		TopP:        req.TopP,
+		MaxTokens:   req.MaxTokens,
+		Stream:      req.Stream,
+	}
+	if req.MaxCompletionTokens != nil {
+		input.MaxTokens = req.MaxCompletionTokens
+	}
+	for _, message := range req.Messages {
+		converted, err := message.toMessage()
+		if err != nil {
+			return GenerateInput{}, err
+		}
+		input.Messages = append(input.Messages, converted)
+	}
+	nameToolResults(input.Messages)
+	for _, tool := range req.Tools {
+		input.Tools = append(input.Tools, ToolDefinition{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			Parameters:  tool.Function.Parameters,
+		})
+	}
+	if len(req.ToolChoice) > 0 && string(req.ToolChoice) != "null" {
+		choice, err := parseOpenAIToolChoice(req.ToolChoice)
+		if err != nil {
+			return GenerateInput{}, err
+		}
+		input.ToolChoice = choice
+	}
+	if format := req.ResponseFormat; format != nil && format.Type != "" && format.Type != "text" {
+		input.ResponseFormat = &ResponseFormat{Type: format.Type}
+		if format.JsonSchema != nil {
+			input.ResponseFormat.JsonSchema = &JsonSchemaFormat{
+				Name:   format.JsonSchema.Name,
+				Schema: format.JsonSchema.Schema,
+				Strict: format.JsonSchema.Strict,
+			}
+		}
+	}
+	if req.User != "" {
+		input.Metadata = map[string]string{"user_id": req.User}
+	}
+	return input, nil
+}
+
+func (m openAICompatMessage) toMessage() (Message, error) {
+	role := m.Role
+	if role == "developer" {
+		role = "system"
+	}
+	message := Message{Role: role, Name: m.Name, ToolCallID: m.ToolCallID}
+	content := strings.TrimSpace(string(m.Content))
+	switch {
+	case content == "" || content == "null":
+	case strings.HasPrefix(content, `"`):
+		var text string
+		if err := json.Unmarshal(m.Content, &text); err != nil {
+			return Message{}, compatValidation(err.Error())
+		}
+		message.Content = []ContentPart{{Type: "text", Text: text}}
+	default:
+		var parts []openAICompatPart
+		if err := json.Unmarshal(m.Content, &parts); err != nil {
+			return Message{}, compatValidation("content must be a string or an array of content parts")
+		}
+		for _, part := range parts {
+			switch part.Type {
+			case "text":
+				message.Content = append(message.Content, ContentPart{Type: "text", Text: part.Text})
+			case "image_url":
+				if part.ImageURL == nil {
+					return Message{}, compatValidation("image_url part is missing its url")
+				}
+				message.Content = append(message.Content, ContentPart{Type: "image", Image: imageFromURL(part.ImageURL.URL)})
+			default:
+				return Message{}, compatValidation(fmt.Sprintf("unsupported content part type %q", part.Type))
+			}
+		}
+	}
+	for _, call := range m.ToolCalls {
+		message.ToolCalls = append(message.ToolCalls, ToolCall{
+			ID:            call.ID,
+			Name:          call.Function.Name,
+			ArgumentsJSON: call.Function.Arguments,
+		})
+	}
+	return message, nil
+}
+
+// imageFromURL turns data: URLs into inline base64 images, which every
+// adapter accepts, and keeps remote URLs as they are.
+func imageFromURL(url string) *ImageContent {
+	if rest, ok := strings.CutPrefix(url, "data:"); ok {
+		if meta, data, found := strings.Cut(rest, ","); found && strings.HasSuffix(meta, ";base64") {
+			return &ImageContent{Base64: data, MediaType: strings.TrimSuffix(meta, ";base64")}
+		}
+	}
+	return &ImageContent{URL: url}
+}
+
+func parseOpenAIToolChoice(raw json.RawMessage) (*ToolChoice, error) {
+	var mode string
+	if err := json.Unmarshal(raw, &mode); err == nil {
+		switch mode {
+		case "auto", "none":
+			return &ToolChoice{Type: mode}, nil
+		case "required":
+			return &ToolChoice{Type: "any"}, nil
+		}
+		return nil, compatValidation(fmt.Sprintf("unsupported tool_choice %q", mode))
+	}
+	var named struct {
+		Function struct {
+			Name string `json:"name"`
+		} `json:"function"`
+	}
+	if err := json.Unmarshal(raw, &named); err != nil || named.Function.Name == "" {
+		return nil, compatValidation("tool_choice must be a mode or name a function")
+	}
+	return &ToolChoice{Type: "tool", Name: named.Function.Name}, nil
+}
+
+// splitProviderModel resolves "provider/model" into one of h's providers and
+// the provider's own model ID.
+func splitProviderModel(h KitAPI, value string) (Provider, string, error) {
+	prefix, model, found := strings.Cut(value, "/")
+	if !found {
+		return "", "", compatValidation(fmt.Sprintf("model %q must be named provider/model", value))
+	}
+	provider, ok := resolveProvider(h, prefix)
+	if !ok {
+		return "", "", &KitError{Kind: ErrorProviderNotFound, Message: fmt.Sprintf("unknown provider %q in model %q", prefix, value)}
+	}
+	return provider, model, nil
+}
+
+// nameToolResults names each "tool" message after the assistant tool call it
+// answers. Neither compat API carries the name on results, and Gemini's
+// functionResponse requires it.
+func nameToolResults(messages []Message) {
+	names := map[string]string{}
+	for idx, message := range messages {
+		for _, call := range message.ToolCalls {
+			names[call.ID] = call.Name
+		}
+		if message.Role == "tool" && message.Name == "" {
+			messages[idx].Name = names[message.ToolCallID]
+		}
+	}
+}
+
+// unsentArguments returns the part of a tool_call_end's final arguments that
+// the preceding deltas did not carry. Calls without arguments send nothing.
+func unsentArguments(chunk StreamChunk, sent string) string {
+	if chunk.Call == nil || chunk.Call.ArgumentsJSON == sent {
+		return ""
+	}
+	if sent == "" && chunk.Call.ArgumentsJSON == "{}" {
+		return ""
+	}
+	return strings.TrimPrefix(chunk.Call.ArgumentsJSON, sent)
+}
+
+func compatValidation(message string) error {
+	return &KitError{Kind: ErrorValidation, Message: message}
+}
+
+// openAIFinishReason maps provider finish reasons onto OpenAI's vocabulary.
+func openAIFinishReason(reason string, toolCalls bool) string {
+	if toolCalls {
+		return "tool_calls"
+	}
+	switch strings.ToLower(reason) {
+	case "length", "max_tokens", "max_output_tokens":
+		return "length"
+	case "content_filter", "safety", "recitation", "blocklist", "prohibited_content", "spii":
+		return "content_filter"
+	case "tool_calls", "tool_use", "function_call":
+		return "tool_calls"
+	}
+	return "stop"
+}
+
+func openAICompatUsageFrom(usage *Usage) *openAICompatUsage {
+	if usage == nil {
+		return nil
+	}
+	total := usage.TotalTokens
+	if total == 0 {
+		total = usage.InputTokens + usage.OutputTokens
+	}
+	return &openAICompatUsage{
+		PromptTokens:     usage.InputTokens,
+		CompletionTokens: usage.OutputTokens,
+		TotalTokens:      total,
+	}
+}
+
+func newCompatID(prefix string) string {
+	buf := make([]byte, 12)
+	_, _ = rand.Read(buf)
+	return prefix + hex.EncodeToString(buf)
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// openAIErrorShape returns the HTTP status, OpenAI error type and code for a
+// Kit error kind.
+func openAIErrorShape(kind ErrorKind) (int, string, string) {
+	switch kind {
+	case ErrorValidation, ErrorUnsupported:
+		return http.StatusBadRequest, "invalid_request_error", ""
+	case ErrorProviderAuth:
+		return http.StatusUnauthorized, "authentication_error", "invalid_api_key"
+	case ErrorProviderNotFound:
+		return http.StatusNotFound, "invalid_request_error", "model_not_found"
+	case ErrorProviderRateLimit:
+		return http.StatusTooManyRequests, "rate_limit_error", "rate_limit_exceeded"
+	case ErrorProviderUnavailable:
+		return http.StatusServiceUnavailable, "server_error", ""
+	}
+	return http.StatusInternalServerError, "server_error", ""
+}
+
+func writeOpenAIError(w http.ResponseWriter, err error) {
+	var kitErr *KitError
+	if !errors.As(err, &kitErr) {
+		writeOpenAIErrorStatus(w, http.StatusInternalServerError, "server_error", err.Error(), "")
+		return
+	}
+	status, errType, code := openAIErrorShape(kitErr.Kind)
+	if kitErr.UpstreamCode != "" {
+		code = kitErr.UpstreamCode
+	}
+	writeOpenAIErrorStatus(w, status, errType, kitErr.Message, code)
+}
+
+func writeOpenAIErrorStatus(w http.ResponseWriter, status int, errType, message, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(openAICompatError{Error: openAICompatErrorBody{
+		Message: message,
+		Type:    errType,
+		Code:    optionalString(code),
+	}})
+}
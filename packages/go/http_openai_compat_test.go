@@ -0,0 +1,294 @@
+package aikit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// recordingKit captures the GenerateInput each call receives.
+type recordingKit struct {
+	mockKit
+	inputs []GenerateInput
+	err    error
+}
+
+func (k *recordingKit) Generate(ctx context.Context, in GenerateInput) (GenerateOutput, error) {
+	k.inputs = append(k.inputs, in)
+	if k.err != nil {
+		return GenerateOutput{}, k.err
+	}
+	return k.generateResp, nil
+}
+
+func (k *recordingKit) StreamGenerate(ctx context.Context, in GenerateInput) (<-chan StreamChunk, error) {
+	k.inputs = append(k.inputs, in)
+	if k.err != nil {
+		return nil, k.err
+	}
+	return k.mockKit.StreamGenerate(ctx, in)
+}
+
+func postOpenAICompat(t *testing.T, kit KitAPI, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	OpenAIChatCompletionsHandler(kit)(rec, req)
+	return rec
+}
+
+func TestOpenAICompatTranslatesRequest(t *testing.T) {
+	kit := &recordingKit{mockKit: mockKit{generateResp: GenerateOutput{Text: "hi"}}}
+	rec := postOpenAICompat(t, kit, `{
+		"model": "anthropic/claude-sonnet-4-5",
+		"messages": [
+			{"role": "developer", "content": "be brief"},
+			{"role": "user", "content": [
+				{"type": "text", "text": "look"},
+				{"type": "image_url", "image_url": {"url": "data:image/png;base64,AAAA"}}
+			]},
+			{"role": "assistant", "content": null, "tool_calls": [
+				{"id": "call_1", "type": "function", "function": {"name": "lookup", "arguments": "{\"q\":1}"}}
+			]},
+			{"role": "tool", "tool_call_id": "call_1", "content": "found"}
+		],
+		"tools": [{"type": "function", "function": {"name": "lookup", "parameters": {"type": "object"}}}],
+		"tool_choice": "required",
+		"max_completion_tokens": 64,
+		"temperature": 0.2
+	}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	in := kit.inputs[0]
+	if in.Provider != ProviderAnthropic || in.Model != "claude-sonnet-4-5" {
+		t.Fatalf("unexpected routing: %s %s", in.Provider, in.Model)
+	}
+	if len(in.Messages) != 4 || in.Messages[0].Role != "system" || in.Messages[0].Content[0].Text != "be brief" {
+		t.Fatalf("unexpected messages: %+v", in.Messages)
+	}
+	image := in.Messages[1].Content[1].Image
+	if image == nil || image.Base64 != "AAAA" || image.MediaType != "image/png" {
+		t.Fatalf("expected inline image, got %+v", image)
+	}
+	if call := in.Messages[2].ToolCalls; len(call) != 1 || call[0].Name != "lookup" || call[0].ArgumentsJSON != `{"q":1}` {
+		t.Fatalf("unexpected assistant tool calls: %+v", call)
+	}
+	if in.Messages[3].ToolCallID != "call_1" || in.Messages[3].Name != "lookup" {
+		t.Fatalf("unexpected tool message: %+v", in.Messages[3])
+	}
+	if len(in.Tools) != 1 || in.ToolChoice == nil || in.ToolChoice.Type != "any" {
+		t.Fatalf("unexpected tools: %+v %+v", in.Tools, in.ToolChoice)
+	}
+	if in.MaxTokens == nil || *in.MaxTokens != 64 || in.Temperature == nil {
+		t.Fatalf("unexpected sampling options: %+v", in)
+	}
+}
+
+func TestOpenAICompatStripsProviderPrefixInAnyCase(t *testing.T) {
+	kit := &recordingKit{mockKit: mockKit{generateResp: GenerateOutput{Text: "hi"}}}
+	rec := postOpenAICompat(t, kit, `{"model":"OpenAI/gpt-4o","messages":[{"role":"user","content":"hi"}]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if in := kit.inputs[0]; in.Provider != ProviderOpenAI || in.Model != "gpt-4o" {
+		t.Fatalf("unexpected routing: %s %s", in.Provider, in.Model)
+	}
+}
+
+func TestOpenAICompatReturnsCompletion(t *testing.T) {
+	kit := &recordingKit{mockKit: mockKit{generateResp: GenerateOutput{
+		ToolCalls:    []ToolCall{{ID: "call_1", Name: "lookup", ArgumentsJSON: `{"q":1}`}},
+		FinishReason: "tool_use",
+		Usage:        &Usage{InputTokens: 3, OutputTokens: 4},
+	}}}
+	rec := postOpenAICompat(t, kit, `{"model":"openai/gpt-4o-mini","messages":[{"role":"user","content":"hi"}]}`)
+	var payload map[string]interface{}
+	readBody(t, rec.Result().Body, &payload)
+	if payload["object"] != "chat.completion" || payload["model"] != "openai/gpt-4o-mini" {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+	choice := payload["choices"].([]interface{})[0].(map[string]interface{})
+	if choice["finish_reason"] != "tool_calls" {
+		t.Fatalf("unexpected finish reason: %v", choice["finish_reason"])
+	}
+	message := choice["message"].(map[string]interface{})
+	calls := message["tool_calls"].([]interface{})
+	function := calls[0].(map[string]interface{})["function"].(map[string]interface{})
+	if function["name"] != "lookup" || function["arguments"] != `{"q":1}` {
+		t.Fatalf("unexpected tool call: %+v", calls[0])
+	}
+	usage := payload["usage"].(map[string]interface{})
+	if usage["prompt_tokens"] != 3.0 || usage["completion_tokens"] != 4.0 || usage["total_tokens"] != 7.0 {
+		t.Fatalf("unexpected usage: %+v", usage)
+	}
+}
+
+func TestOpenAICompatStreams(t *testing.T) {
+	kit := &recordingKit{mockKit: mockKit{streamChunks: []StreamChunk{
+		{Type: StreamChunkMessageStart, Model: "gpt-4o-mini"},
+		{Type: StreamChunkDelta, TextDelta: "Hel"},
+		{Type: StreamChunkDelta, TextDelta: "lo"},
+		{Type: StreamChunkToolCallStart, Index: 0, Call: &ToolCall{ID: "call_1", Name: "lookup"}},
+		{Type: StreamChunkToolCallDelta, Index: 0, Delta: `{"q":`, Call: &ToolCall{ID: "call_1", Name: "lookup"}},
+		{Type: StreamChunkToolCallEnd, Index: 0, Call: &ToolCall{ID: "call_1", Name: "lookup", ArgumentsJSON: `{"q":1}`}},
+		{Type: StreamChunkMessageEnd, FinishReason: "tool_calls", Usage: &Usage{InputTokens: 1, OutputTokens: 2, TotalTokens: 3}},
+	}}}
+	rec := postOpenAICompat(t, kit, `{"model":"openai/gpt-4o-mini","stream":true,"stream_options":{"include_usage":true},"messages":[{"role":"user","content":"hi"}]}`)
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	events := parseSSEEvents(rec.Body.String())
+	if len(events) == 0 || events[len(events)-1].Data != "[DONE]" {
+		t.Fatalf("expected [DONE] terminator, got %+v", events)
+	}
+	var text, args strings.Builder
+	var finish string
+	var usage *openAICompatUsage
+	for _, event := range events[:len(events)-1] {
+		var chunk openAICompatCompletion
+		if err := json.Unmarshal([]byte(event.Data), &chunk); err != nil {
+			t.Fatalf("decode chunk %q: %v", event.Data, err)
+		}
+		if chunk.Object != "chat.completion.chunk" {
+			t.Fatalf("unexpected object %q", chunk.Object)
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != nil {
+				text.WriteString(*choice.Delta.Content)
+			}
+			for _, call := range choice.Delta.ToolCalls {
+				args.WriteString(call.Function.Arguments)
+			}
+			if choice.FinishReason != nil {
+				finish = *choice.FinishReason
+			}
+		}
+	}
+	if text.String() != "Hello" || args.String() != `{"q":1}` || finish != "tool_calls" {
+		t.Fatalf("unexpected stream: text=%q args=%q finish=%q", text.String(), args.String(), finish)
+	}
+	if usage == nil || usage.TotalTokens != 3 {
+		t.Fatalf("expected usage chunk, got %+v", usage)
+	}
+}
+
+func TestOpenAICompatStreamErrorEndsWithoutDone(t *testing.T) {
+	kit := &recordingKit{mockKit: mockKit{streamChunks: []StreamChunk{
+		{Type: StreamChunkDelta, TextDelta: "partial"},
+		{Type: StreamChunkError, Error: &ChunkError{Kind: string(ErrorProviderRateLimit), Message: "slow down"}},
+	}}}
+	rec := postOpenAICompat(t, kit, `{"model":"openai/gpt-4o-mini","stream":true,"messages":[]}`)
+	events := parseSSEEvents(rec.Body.String())
+	last := events[len(events)-1].Data
+	var payload openAICompatError
+	if err := json.Unmarshal([]byte(last), &payload); err != nil || payload.Error.Type != "rate_limit_error" || payload.Error.Message != "slow down" {
+		t.Fatalf("expected error payload last, got %q", last)
+	}
+}
+
+func TestOpenAICompatErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		kit    *recordingKit
+		body   string
+		status int
+		typ    string
+	}{
+		{"invalid json", &recordingKit{}, `{`, http.StatusBadRequest, "invalid_request_error"},
+		{"missing provider", &recordingKit{}, `{"model":"gpt-4o","messages":[]}`, http.StatusBadRequest, "invalid_request_error"},
+		{"unknown provider", &recordingKit{}, `{"model":"acme/gpt-4o","messages":[]}`, http.StatusNotFound, "invalid_request_error"},
+		{"rate limited", &recordingKit{err: &KitError{Kind: ErrorProviderRateLimit, Message: "slow down"}}, `{"model":"openai/gpt-4o","messages":[]}`, http.StatusTooManyRequests, "rate_limit_error"},
+		{"stream auth", &recordingKit{err: &KitError{Kind: ErrorProviderAuth, Message: "bad key"}}, `{"model":"openai/gpt-4o","stream":true,"messages":[]}`, http.StatusUnauthorized, "authentication_error"},
+	}
+	for _, tc := range cases {
+		rec := postOpenAICompat(t, tc.kit, tc.body)
+		if rec.Code != tc.status {
+			t.Fatalf("%s: expected %d, got %d", tc.name, tc.status, rec.Code)
+		}
+		var payload openAICompatError
+		readBody(t, rec.Result().Body, &payload)
+		if payload.Error.Type != tc.typ || payload.Error.Message == "" {
+			t.Fatalf("%s: unexpected error payload %+v", tc.name, payload.Error)
+		}
+	}
+}
+
+func TestOpenAIModelsHandlerPrefixesProvider(t *testing.T) {
+	kit := &mockKit{modelsResp: []ModelMetadata{
+		{ID: "gpt-4o", Provider: ProviderOpenAI},
+		{ID: "ollama/llama3", Provider: ProviderOllama},
+	}}
+	rec := httptest.NewRecorder()
+	OpenAIModelsHandler(kit)(rec, httptest.NewRequest(http.MethodGet, "/v1/models", nil))
+	var payload struct {
+		Object string              `json:"object"`
+		Data   []openAICompatModel `json:"data"`
+	}
+	readBody(t, rec.Result().Body, &payload)
+	if payload.Object != "list" || len(payload.Data) != 2 {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+	if payload.Data[0].ID != "openai/gpt-4o" || payload.Data[1].ID != "ollama/llama3" || payload.Data[1].OwnedBy != "ollama" {
+		t.Fatalf("unexpected model ids: %+v", payload.Data)
+	}
+}
+
+func TestCompatProxiesRouteConfiguredProviders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/models" {
+			w.Write([]byte(`{"data":[{"id":"llama-3.3-70b"}]}`))
+			return
+		}
+		var body struct {
+			Model string `json:"model"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Model != "llama-3.3-70b" {
+			t.Errorf("expected the bare model id upstream, got %q", body.Model)
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+	kit, err := New(Config{
+		Adapters:   map[Provider]ProviderAdapter{ProviderOpenAI: newListingAdapter("v1")},
+		Compatible: []CompatibleConfig{{Name: "groq", APIKey: "gsk", BaseURL: server.URL}},
+	})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	OpenAIModelsHandler(kit)(rec, httptest.NewRequest(http.MethodGet, "/v1/models", nil))
+	var models struct {
+		Data []openAICompatModel `json:"data"`
+	}
+	readBody(t, rec.Result().Body, &models)
+	if len(models.Data) != 2 || models.Data[0].ID != "groq/llama-3.3-70b" {
+		t.Fatalf("unexpected models: %+v", models.Data)
+	}
+
+	rec = postOpenAICompat(t, kit, `{"model":"groq/llama-3.3-70b","messages":[{"role":"user","content":"hi"}]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("chat completions: %d %s", rec.Code, rec.Body.String())
+	}
+	rec = postAnthropicCompat(t, kit, `{"model":"groq/llama-3.3-70b","max_tokens":16,"messages":[{"role":"user","content":"hi"}]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("messages: %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	ModelsHandler(kit, nil)(rec, httptest.NewRequest(http.MethodGet, "/provider-models?providers=groq", nil))
+	var listed []ModelMetadata
+	readBody(t, rec.Result().Body, &listed)
+	if len(listed) != 1 || listed[0].Provider != "groq" {
+		t.Fatalf("expected the providers filter to keep groq, got %+v", listed)
+	}
+}
@@ -0,0 +1,341 @@
+package aikit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultSSEHeartbeat = 15 * time.Second
+
+type SSEHandlerOptions struct {
+	// HeartbeatInterval between keep-alive comments while no chunk is sent.
+	// Zero uses 15s; a negative value disables heartbeats.
+	HeartbeatInterval time.Duration
+	// Replay keeps recent streams so a client reconnecting with
+	// Last-Event-ID resumes where it left off. Nil disables resumption and
+	// cancels the upstream generation as soon as the client disconnects.
+	Replay *SSEReplayBuffer
+}
+
+// SSEReplayBuffer retains streamed events for a short time. Event IDs are
+// unique and increasing across every stream in the buffer, so a
+// Last-Event-ID identifies both the stream and the resume position. A stream
+// whose client disconnects keeps generating for TTL so the client can
+// reconnect; finished streams stay resumable for TTL as well.
+type SSEReplayBuffer struct {
+	TTL time.Duration
+
+	mu      sync.Mutex
+	lastID  uint64
+	streams []*sseStream
+}
+
+func NewSSEReplayBuffer(ttl time.Duration) *SSEReplayBuffer {
+	return &SSEReplayBuffer{TTL: ttl}
+}
+
+func (b *SSEReplayBuffer) ttl() time.Duration {
+	if b.TTL <= 0 {
+		return 30 * time.Second
+	}
+	return b.TTL
+}
+
+func (b *SSEReplayBuffer) nextID() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastID++
+	return b.lastID
+}
+
+func (b *SSEReplayBuffer) add(stream *sseStream) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.evictLocked()
+	b.streams = append(b.streams, stream)
+}
+
+// lookup returns the stream that emitted lastEventID.
+func (b *SSEReplayBuffer) lookup(lastEventID uint64) *sseStream {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.evictLocked()
+	for _, stream := range b.streams {
+		if stream.contains(lastEventID) {
+			return stream
+		}
+	}
+	return nil
+}
+
+func (b *SSEReplayBuffer) evictLocked() {
+	now := time.Now()
+	kept := b.streams[:0]
+	for _, stream := range b.streams {
+		if !stream.expired(now) {
+			kept = append(kept, stream)
+		}
+	}
+	for idx := len(kept); idx < len(b.streams); idx++ {
+		b.streams[idx] = nil
+	}
+	b.streams = kept
+}
+
+type sseRecord struct {
+	id    uint64
+	event string
+	data  []byte
+}
+
+// sseStream decouples the upstream generation from the HTTP responses
+// reading it: a pump appends records, and each connected handler replays
+// them from its own cursor. Records are kept for resumption only when the
+// stream is in a replay buffer; otherwise its one reader's cursor releases
+// them as they are delivered.
+type sseStream struct {
+	mu          sync.Mutex
+	records     []sseRecord
+	replayable  bool
+	changed     chan struct{}
+	done        bool
+	doneAt      time.Time
+	subscribers int
+	cancel      context.CancelFunc
+	grace       time.Duration
+	idle        *time.Timer
+	nextID      func() uint64
+}
+
+func newSSEStream(cancel context.CancelFunc, grace time.Duration, nextID func() uint64, replayable bool) *sseStream {
+	return &sseStream{
+		changed:    make(chan struct{}),
+		replayable: replayable,
+		cancel:     cancel,
+		grace:      grace,
+		nextID:     nextID,
+	}
+}
+
+func (s *sseStream) pump(chunks <-chan StreamChunk) {
+	var failure *ChunkError
+	for chunk := range chunks {
+		event := "chunk"
+		if chunk.Type == StreamChunkError {
+			event = "error"
+			if failure == nil && chunk.Error != nil {
+				failure = chunk.Error
+			}
+		}
+		data, err := json.Marshal(chunk)
+		if err != nil {
+			continue
+		}
+		s.append(event, data, false)
+	}
+	done := map[string]interface{}{"ok": failure == nil}
+	if failure != nil {
+		done["error"] = failure
+	}
+	data, _ := json.Marshal(done)
+	s.append("done", data, true)
+	s.cancel()
+}
+
+func (s *sseStream) append(event string, data []byte, final bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, sseRecord{id: s.nextID(), event: event, data: data})
+	if final {
+		s.done = true
+		s.doneAt = time.Now()
+	}
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// since returns records after lastID, whether the stream is finished, and a
+// channel closed on the next append. IDs increase but are shared with other
+// streams in a replay buffer, so the position is found by binary search.
+// Without replay, everything up to lastID has been delivered and is dropped.
+func (s *sseStream) since(lastID uint64) ([]sseRecord, bool, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	start := sort.Search(len(s.records), func(idx int) bool { return s.records[idx].id > lastID })
+	if !s.replayable {
+		if start == len(s.records) {
+			s.records = s.records[:0]
+		} else {
+			s.records = s.records[start:]
+		}
+		start = 0
+	}
+	return s.records[start:], s.done, s.changed
+}
+
+func (s *sseStream) contains(id uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.records) > 0 && s.records[0].id <= id && id <= s.records[len(s.records)-1].id
+}
+
+func (s *sseStream) expired(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.done && now.Sub(s.doneAt) > s.grace
+}
+
+func (s *sseStream) subscribe() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers++
+	if s.idle != nil {
+		s.idle.Stop()
+		s.idle = nil
+	}
+}
+
+// unsubscribe cancels the generation once nobody has been reading it for the
+// grace period.
+func (s *sseStream) unsubscribe() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers--
+	if s.subscribers > 0 || s.done {
+		return
+	}
+	if s.grace <= 0 {
+		s.cancel()
+		return
+	}
+	s.idle = time.AfterFunc(s.grace, func() {
+		s.mu.Lock()
+		abandoned := s.subscribers == 0
+		s.mu.Unlock()
+		if abandoned {
+			s.cancel()
+		}
+	})
+}
+
+func GenerateSSEHandler(h KitAPI) http.HandlerFunc {
+	return GenerateSSEHandlerWithOptions(h, nil)
+}
+
+// GenerateSSEHandlerWithOptions streams chunks as `event: chunk` (or
+// `event: error`) with increasing `id:` fields, sends heartbeat comments
+// while idle and finishes with `event: done` carrying `{"ok":false}` plus the
+// first error when the stream failed.
+func GenerateSSEHandlerWithOptions(h KitAPI, opts *SSEHandlerOptions) http.HandlerFunc {
+	if opts == nil {
+		opts = &SSEHandlerOptions{}
+	}
+	heartbeat := opts.HeartbeatInterval
+	if heartbeat == 0 {
+		heartbeat = defaultSSEHeartbeat
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+		if lastEventID := strings.TrimSpace(r.Header.Get("Last-Event-ID")); lastEventID != "" && opts.Replay != nil {
+			id, err := strconv.ParseUint(lastEventID, 10, 64)
+			stream := opts.Replay.lookup(id)
+			if err != nil || stream == nil {
+				http.Error(w, "stream is no longer available", http.StatusGone)
+				return
+			}
+			serveSSEStream(w, flusher, r, stream, id, heartbeat)
+			return
+		}
+		var input GenerateInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			http.Error(w, "invalid JSON", http.StatusBadRequest)
+			return
+		}
+		// The generation outlives this request only when it can be resumed;
+		// otherwise unsubscribe cancels it as soon as the client leaves.
+		ctx, cancel := context.WithCancel(context.WithoutCancel(r.Context()))
+		ch, err := h.StreamGenerate(ctx, input)
+		if err != nil {
+			cancel()
+			writeError(w, err)
+			return
+		}
+		var stream *sseStream
+		if opts.Replay != nil {
+			stream = newSSEStream(cancel, opts.Replay.ttl(), opts.Replay.nextID, true)
+			opts.Replay.add(stream)
+		} else {
+			var lastID uint64
+			stream = newSSEStream(cancel, 0, func() uint64 {
+				lastID++
+				return lastID
+			}, false)
+		}
+		go stream.pump(ch)
+		serveSSEStream(w, flusher, r, stream, 0, heartbeat)
+	}
+}
+
+func serveSSEStream(w http.ResponseWriter, flusher http.Flusher, r *http.Request, stream *sseStream, lastID uint64, heartbeat time.Duration) {
+	stream.subscribe()
+	defer stream.unsubscribe()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	// The heartbeat timer restarts on every write, so it only fills gaps.
+	var ticks <-chan time.Time
+	idle := func() {}
+	if heartbeat > 0 {
+		timer := time.NewTimer(heartbeat)
+		defer timer.Stop()
+		ticks = timer.C
+		idle = func() {
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(heartbeat)
+		}
+	}
+	for {
+		records, done, changed := stream.since(lastID)
+		for _, record := range records {
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", record.id, record.event, record.data); err != nil {
+				return
+			}
+			lastID = record.id
+		}
+		if len(records) > 0 {
+			flusher.Flush()
+			idle()
+		}
+		if done {
+			return
+		}
+		select {
+		case <-changed:
+		case <-ticks:
+			if _, err := w.Write([]byte(": heartbeat\n\n")); err != nil {
+				return
+			}
+			flusher.Flush()
+			idle()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package aikit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+)
+
+type WSFrameType string
+
+const (
+	// Client to server.
+	WSFrameStart  WSFrameType = "start"
+	WSFrameCancel WSFrameType = "cancel"
+	// Server to client.
+	WSFrameChunk WSFrameType = "chunk"
+	WSFrameDone  WSFrameType = "done"
+	WSFrameError WSFrameType = "error"
+)
+
+// WSFrame is one JSON message of the WebSocket protocol. Every frame about a
+// generation carries the client-chosen request ID; one socket can run several
+// requests at once.
+//
+//   - start{id, input} begins a generation;
+//   - cancel{id} cancels its upstream context;
+//   - chunk{id, chunk} forwards a StreamChunk;
+//   - error{id, error} reports an error chunk or a request that could not
+//     start (an error without id is a protocol error);
+//   - done{id, ok, cancelled?, error?} is the last frame for every request.
+type WSFrame struct {
+	Type      WSFrameType    `json:"type"`
+	ID        string         `json:"id,omitempty"`
+	Input     *GenerateInput `json:"input,omitempty"`
+	Chunk     *StreamChunk   `json:"chunk,omitempty"`
+	OK        *bool          `json:"ok,omitempty"`
+	Cancelled bool           `json:"cancelled,omitempty"`
+	Error     *ChunkError    `json:"error,omitempty"`
+}
+
+type WebSocketHandlerOptions struct {
+	// OriginPatterns authorizes cross-origin browser clients; the request
+	// host is always allowed.
+	OriginPatterns []string
+	// MaxConcurrent caps in-flight requests per socket (default 16).
+	MaxConcurrent int
+	// ReadLimit caps the size of one client frame in bytes (default 16 MiB,
+	// enough for inline images).
+	ReadLimit int64
+}
+
+func GenerateWebSocketHandler(h KitAPI, opts *WebSocketHandlerOptions) http.HandlerFunc {
+	if opts == nil {
+		opts = &WebSocketHandlerOptions{}
+	}
+	maxConcurrent := opts.MaxConcurrent
+	if maxConcurrent <= 0 {
+		maxConcurrent = 16
+	}
+	readLimit := opts.ReadLimit
+	if readLimit <= 0 {
+		readLimit = 16 << 20
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{OriginPatterns: opts.OriginPatterns})
+		if err != nil {
+			return
+		}
+		conn.SetReadLimit(readLimit)
+		session := &wsSession{
+			kit:           h,
+			conn:          conn,
+			maxConcurrent: maxConcurrent,
+			requests:      map[string]*wsRequest{},
+		}
+		session.serve(r.Context())
+	}
+}
+
+type wsRequest struct {
+	cancel    context.CancelFunc
+	cancelled bool
+}
+
+type wsSession struct {
+	kit           KitAPI
+	conn          *websocket.Conn
+	maxConcurrent int
+
+	mu       sync.Mutex
+	requests map[string]*wsRequest
+	wg       sync.WaitGroup
+}
+
+func (s *wsSession) serve(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		s.wg.Wait()
+		s.conn.Close(websocket.StatusNormalClosure, "")
+	}()
+	for {
+		// Read raw bytes: wsjson.Read closes the socket on malformed JSON,
+		// while a bad frame should only be reported back.
+		_, data, err := s.conn.Read(ctx)
+		if err != nil {
+			return
+		}
+		var frame WSFrame
+		if err := json.Unmarshal(data, &frame); err != nil {
+			s.write(ctx, WSFrame{Type: WSFrameError, Error: &ChunkError{Kind: string(ErrorValidation), Message: "invalid JSON frame"}})
+			continue
+		}
+		switch frame.Type {
+		case WSFrameStart:
+			s.start(ctx, frame)
+		case WSFrameCancel:
+			s.mu.Lock()
+			if req, ok := s.requests[frame.ID]; ok {
+				req.cancelled = true
+				req.cancel()
+			}
+			s.mu.Unlock()
+		default:
+			s.write(ctx, WSFrame{Type: WSFrameError, ID: frame.ID, Error: &ChunkError{
+				Kind:    string(ErrorValidation),
+				Message: fmt.Sprintf("unknown frame type %q", frame.Type),
+			}})
+		}
+	}
+}
+
+func (s *wsSession) start(ctx context.Context, frame WSFrame) {
+	reject := func(message string) {
+		s.write(ctx, WSFrame{Type: WSFrameError, ID: frame.ID, Error: &ChunkError{Kind: string(ErrorValidation), Message: message}})
+	}
+	if frame.ID == "" || frame.Input == nil {
+		reject("start frames require id and input")
+		return
+	}
+	s.mu.Lock()
+	if _, exists := s.requests[frame.ID]; exists {
+		s.mu.Unlock()
+		reject(fmt.Sprintf("request %q is already running", frame.ID))
+		return
+	}
+	if len(s.requests) >= s.maxConcurrent {
+		s.mu.Unlock()
+		reject(fmt.Sprintf("too many concurrent requests (max %d)", s.maxConcurrent))
+		return
+	}
+	reqCtx, cancel := context.WithCancel(ctx)
+	req := &wsRequest{cancel: cancel}
+	s.requests[frame.ID] = req
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer cancel()
+		failure := s.run(ctx, reqCtx, frame.ID, *frame.Input)
+		s.mu.Lock()
+		delete(s.requests, frame.ID)
+		cancelled := req.cancelled
+		s.mu.Unlock()
+		ok := failure == nil && !cancelled
+		s.write(ctx, WSFrame{Type: WSFrameDone, ID: frame.ID, OK: &ok, Cancelled: cancelled, Error: failure})
+	}()
+}
+
+// run forwards one generation and returns the first error it reported.
+// Frames are written with the session context: cancelling a write's context
+// closes the whole socket, so reqCtx only governs the upstream call.
+func (s *wsSession) run(ctx, reqCtx context.Context, id string, input GenerateInput) *ChunkError {
+	stream, err := s.kit.StreamGenerate(reqCtx, input)
+	if err != nil {
+		failure := &ChunkError{Kind: string(ErrorUnknown), Message: err.Error()}
+		var kitErr *KitError
+		if errors.As(err, &kitErr) {
+			failure = &ChunkError{Kind: string(kitErr.Kind), Message: kitErr.Message, UpstreamCode: kitErr.UpstreamCode, RequestID: kitErr.RequestID}
+		}
+		s.write(ctx, WSFrame{Type: WSFrameError, ID: id, Error: failure})
+		return failure
+	}
+	var failure *ChunkError
+	for chunk := range stream {
+		chunk := chunk
+		if reqCtx.Err() != nil {
+			continue
+		}
+		if chunk.Type == StreamChunkError {
+			if failure == nil {
+				failure = chunk.Error
+			}
+			s.write(ctx, WSFrame{Type: WSFrameError, ID: id, Error: chunk.Error})
+			continue
+		}
+		s.write(ctx, WSFrame{Type: WSFrameChunk, ID: id, Chunk: &chunk})
+	}
+	return failure
+}
+
+// write sends one frame; coder/websocket serializes concurrent writers.
+func (s *wsSession) write(ctx context.Context, frame WSFrame) {
+	_ = wsjson.Write(ctx, s.conn, frame)
+}
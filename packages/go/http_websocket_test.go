@@ -0,0 +1,198 @@
+package aikit
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+)
+
+// modelKit streams from one channel per model and records each stream's
+// context, so a test can drive several concurrent requests independently.
+type modelKit struct {
+	mockKit
+	mu     sync.Mutex
+	chunks map[string]chan StreamChunk
+	ctxs   map[string]chan context.Context
+}
+
+func newModelKit(models ...string) *modelKit {
+	k := &modelKit{chunks: map[string]chan StreamChunk{}, ctxs: map[string]chan context.Context{}}
+	for _, model := range models {
+		k.chunks[model] = make(chan StreamChunk)
+		k.ctxs[model] = make(chan context.Context, 1)
+	}
+	return k
+}
+
+func (k *modelKit) StreamGenerate(ctx context.Context, in GenerateInput) (<-chan StreamChunk, error) {
+	k.mu.Lock()
+	chunks, ok := k.chunks[in.Model]
+	ctxs := k.ctxs[in.Model]
+	k.mu.Unlock()
+	if !ok {
+		return nil, &KitError{Kind: ErrorValidation, Message: "unknown model " + in.Model}
+	}
+	ctxs <- ctx
+	out := make(chan StreamChunk)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case chunk, ok := <-chunks:
+				if !ok {
+					return
+				}
+				out <- chunk
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func dialWebSocket(t *testing.T, kit KitAPI) (*websocket.Conn, context.Context) {
+	t.Helper()
+	server := httptest.NewServer(GenerateWebSocketHandler(kit, nil))
+	t.Cleanup(server.Close)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close(websocket.StatusNormalClosure, "") })
+	return conn, ctx
+}
+
+func readFrame(t *testing.T, ctx context.Context, conn *websocket.Conn) WSFrame {
+	t.Helper()
+	var frame WSFrame
+	if err := wsjson.Read(ctx, conn, &frame); err != nil {
+		t.Fatalf("read frame: %v", err)
+	}
+	return frame
+}
+
+func sendFrame(t *testing.T, ctx context.Context, conn *websocket.Conn, frame WSFrame) {
+	t.Helper()
+	if err := wsjson.Write(ctx, conn, frame); err != nil {
+		t.Fatalf("write frame: %v", err)
+	}
+}
+
+func TestWebSocketMultiplexesAndCancelsRequests(t *testing.T) {
+	kit := newModelKit("a", "b")
+	conn, ctx := dialWebSocket(t, kit)
+
+	sendFrame(t, ctx, conn, WSFrame{Type: WSFrameStart, ID: "one", Input: &GenerateInput{Model: "a"}})
+	sendFrame(t, ctx, conn, WSFrame{Type: WSFrameStart, ID: "two", Input: &GenerateInput{Model: "b"}})
+	ctxA := <-kit.ctxs["a"]
+	<-kit.ctxs["b"]
+
+	kit.chunks["a"] <- StreamChunk{Type: StreamChunkDelta, TextDelta: "from a"}
+	kit.chunks["b"] <- StreamChunk{Type: StreamChunkDelta, TextDelta: "from b"}
+	seen := map[string]string{}
+	for len(seen) < 2 {
+		frame := readFrame(t, ctx, conn)
+		if frame.Type != WSFrameChunk || frame.Chunk == nil {
+			t.Fatalf("unexpected frame: %+v", frame)
+		}
+		seen[frame.ID] = frame.Chunk.TextDelta
+	}
+	if seen["one"] != "from a" || seen["two"] != "from b" {
+		t.Fatalf("chunks routed to the wrong request: %+v", seen)
+	}
+
+	sendFrame(t, ctx, conn, WSFrame{Type: WSFrameCancel, ID: "one"})
+	select {
+	case <-ctxA.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatalf("cancel frame did not cancel the upstream context")
+	}
+	done := readFrame(t, ctx, conn)
+	if done.Type != WSFrameDone || done.ID != "one" || !done.Cancelled || done.OK == nil || *done.OK {
+		t.Fatalf("unexpected done frame for cancelled request: %+v", done)
+	}
+
+	kit.chunks["b"] <- StreamChunk{Type: StreamChunkMessageEnd, FinishReason: "stop"}
+	close(kit.chunks["b"])
+	end := readFrame(t, ctx, conn)
+	if end.Type != WSFrameChunk || end.ID != "two" || end.Chunk.Type != StreamChunkMessageEnd {
+		t.Fatalf("unexpected frame: %+v", end)
+	}
+	done = readFrame(t, ctx, conn)
+	if done.Type != WSFrameDone || done.ID != "two" || done.Cancelled || done.OK == nil || !*done.OK {
+		t.Fatalf("unexpected done frame for completed request: %+v", done)
+	}
+}
+
+func TestWebSocketReportsErrors(t *testing.T) {
+	kit := newModelKit("a")
+	conn, ctx := dialWebSocket(t, kit)
+
+	sendFrame(t, ctx, conn, WSFrame{Type: WSFrameStart, ID: "missing", Input: &GenerateInput{Model: "nope"}})
+	frame := readFrame(t, ctx, conn)
+	if frame.Type != WSFrameError || frame.ID != "missing" || frame.Error.Kind != string(ErrorValidation) {
+		t.Fatalf("unexpected start failure frame: %+v", frame)
+	}
+	frame = readFrame(t, ctx, conn)
+	if frame.Type != WSFrameDone || frame.OK == nil || *frame.OK || frame.Error == nil {
+		t.Fatalf("unexpected done frame: %+v", frame)
+	}
+
+	sendFrame(t, ctx, conn, WSFrame{Type: WSFrameStart, ID: "one", Input: &GenerateInput{Model: "a"}})
+	<-kit.ctxs["a"]
+	sendFrame(t, ctx, conn, WSFrame{Type: WSFrameStart, ID: "one", Input: &GenerateInput{Model: "a"}})
+	frame = readFrame(t, ctx, conn)
+	if frame.Type != WSFrameError || !strings.Contains(frame.Error.Message, "already running") {
+		t.Fatalf("expected duplicate id rejection, got %+v", frame)
+	}
+
+	kit.chunks["a"] <- StreamChunk{Type: StreamChunkError, Error: &ChunkError{Kind: string(ErrorStreamInterrupted), Message: "boom"}}
+	close(kit.chunks["a"])
+	frame = readFrame(t, ctx, conn)
+	if frame.Type != WSFrameError || frame.ID != "one" || frame.Error.Kind != string(ErrorStreamInterrupted) {
+		t.Fatalf("unexpected error frame: %+v", frame)
+	}
+	frame = readFrame(t, ctx, conn)
+	if frame.Type != WSFrameDone || *frame.OK || frame.Error == nil || frame.Error.Message != "boom" {
+		t.Fatalf("unexpected done frame: %+v", frame)
+	}
+
+	if err := conn.Write(ctx, websocket.MessageText, []byte("{not json")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	frame = readFrame(t, ctx, conn)
+	if frame.Type != WSFrameError || frame.ID != "" {
+		t.Fatalf("expected protocol error, got %+v", frame)
+	}
+	sendFrame(t, ctx, conn, WSFrame{Type: "bogus", ID: "x"})
+	frame = readFrame(t, ctx, conn)
+	if frame.Type != WSFrameError || !strings.Contains(frame.Error.Message, "unknown frame type") {
+		t.Fatalf("expected unknown frame error, got %+v", frame)
+	}
+}
+
+func TestWebSocketCloseCancelsInFlightRequests(t *testing.T) {
+	kit := newModelKit("a")
+	conn, ctx := dialWebSocket(t, kit)
+	sendFrame(t, ctx, conn, WSFrame{Type: WSFrameStart, ID: "one", Input: &GenerateInput{Model: "a"}})
+	upstream := <-kit.ctxs["a"]
+	conn.Close(websocket.StatusNormalClosure, "")
+	select {
+	case <-upstream.Done():
+		if !errors.Is(upstream.Err(), context.Canceled) {
+			t.Fatalf("unexpected context error: %v", upstream.Err())
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("closing the socket did not cancel the upstream context")
+	}
+}
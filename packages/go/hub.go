@@ -3,21 +3,51 @@ package aikit
 import (
 	"context"
 	"fmt"
+	"io/fs"
 	"net/http"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type Config struct {
-	OpenAI         *OpenAIConfig
-	Anthropic      *AnthropicConfig
-	XAI            *XAIConfig
-	Google         *GoogleConfig
-	Ollama         *OllamaConfig
-	HTTPClient     *http.Client
-	RegistryTTL    time.Duration
+	OpenAI      *OpenAIConfig
+	Anthropic   *AnthropicConfig
+	XAI         *XAIConfig
+	Google      *GoogleConfig
+	Ollama      *OllamaConfig
+	HTTPClient  *http.Client
+	RegistryTTL time.Duration
+	// RegistryStaleTTL is how long past RegistryTTL a cached listing is
+	// still served while a background refresh replaces it (24h when zero,
+	// never when negative).
+	RegistryStaleTTL time.Duration
+	// RegistryStore holds cached model listings, in memory when nil. It is
+	// fixed when the Kit is created; Reload keeps the original store.
+	RegistryStore RegistryStore
+	// Catalog and CatalogDir are merged over the curated model catalog
+	// embedded in the package, in that order. Both use the layout of the
+	// repository's models directory, <provider>/scraped_models.json and
+	// <provider>/curated_models.json, and an entry matching an existing
+	// provider and id only replaces the fields it sets. Like RegistryStore they are fixed when the Kit is created; use
+	// Kit.MergeCatalog afterwards.
+	Catalog    fs.FS
+	CatalogDir string
+	// Aliases maps a model name to a canonical "provider:model" id, e.g.
+	// "sonnet-latest": "anthropic:claude-sonnet-4-5". A "provider:alias" key
+	// only applies to requests for that provider, and the target is what is
+	// sent upstream. Aliases declared in the catalog only select the entry
+	// used for pricing and metadata; these take precedence.
+	Aliases map[string]string
+	// Compatible registers extra providers that speak the OpenAI or
+	// Anthropic wire format under their own provider name.
+	Compatible     []CompatibleConfig
 	Adapters       map[Provider]ProviderAdapter
 	AdapterFactory AdapterFactory
+	// OnReload receives an event after every Kit.Reload attempt.
+	OnReload func(ReloadEvent)
 }
 
 type OpenAIConfig struct {
@@ -26,13 +56,19 @@ type OpenAIConfig struct {
 	BaseURL             string
 	Organization        string
 	DefaultUseResponses bool
+	// Timeout bounds each non-streaming request, including reading the
+	// response body. Streams use Transport.StreamTimeout instead.
+	Timeout   time.Duration
+	Transport TransportConfig
 }
 
 type AnthropicConfig struct {
-	APIKey  string
-	APIKeys []string
-	BaseURL string
-	Version string
+	APIKey    string
+	APIKeys   []string
+	BaseURL   string
+	Version   string
+	Timeout   time.Duration
+	Transport TransportConfig
 }
 
 type XAIConfig struct {
@@ -40,12 +76,16 @@ type XAIConfig struct {
 	APIKeys           []string
 	BaseURL           string
 	CompatibilityMode string
+	Timeout           time.Duration
+	Transport         TransportConfig
 }
 
 type GoogleConfig struct {
-	APIKey  string
-	APIKeys []string
-	BaseURL string
+	APIKey    string
+	APIKeys   []string
+	BaseURL   string
+	Timeout   time.Duration
+	Transport TransportConfig
 }
 
 type OllamaConfig struct {
@@ -53,6 +93,22 @@ type OllamaConfig struct {
 	APIKeys             []string
 	BaseURL             string
 	DefaultUseResponses bool
+	Timeout             time.Duration
+	Transport           TransportConfig
+}
+
+// CompatibleConfig describes a provider such as Groq, Together or a
+// self-hosted gateway that implements the OpenAI ("openai", the default) or
+// Anthropic ("anthropic") API. BaseURL is the API root without the /v1
+// suffix; keys are optional for gateways that do not check them.
+type CompatibleConfig struct {
+	Name      Provider
+	API       string
+	APIKey    string
+	APIKeys   []string
+	BaseURL   string
+	Timeout   time.Duration
+	Transport TransportConfig
 }
 
 type ProviderAdapter interface {
@@ -66,20 +122,61 @@ type ProviderAdapter interface {
 
 type AdapterFactory func(provider Provider, entitlement *EntitlementContext) (ProviderAdapter, error)
 
+// Kit routes requests to provider adapters. Its adapters, key pools and
+// registry settings live in a kitState that Reload swaps atomically; each
+// request reads the state once, so in-flight calls finish on the
+// configuration they started with.
 type Kit struct {
-	adapters map[Provider]ProviderAdapter
+	state    atomic.Pointer[kitState]
 	registry *modelRegistry
+	catalog  *catalog
+	reloadMu sync.Mutex
+}
+
+type kitState struct {
+	config   Config
+	adapters map[Provider]ProviderAdapter
 	factory  AdapterFactory
 	keyPools map[Provider]*keyPool
+	ttl      time.Duration
+	staleTTL time.Duration
 }
 
 func New(config Config) (*Kit, error) {
+	state, err := newKitState(config)
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := newCatalog(config.catalogOverrides()...)
+	if err != nil {
+		return nil, fmt.Errorf("catalog: %w", err)
+	}
+	kit := &Kit{registry: newModelRegistry(config.RegistryStore, catalog, state), catalog: catalog}
+	kit.state.Store(state)
+	return kit, nil
+}
+
+func newKitState(config Config) (*kitState, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
 	adapters := make(map[Provider]ProviderAdapter)
 	keyPools := make(map[Provider]*keyPool)
+	clients := make(map[Provider]*http.Client)
 	client := config.HTTPClient
 	if client == nil {
 		client = http.DefaultClient
 	}
+	var problems configProblems
+	clientFor := func(provider Provider, timeout time.Duration, transport TransportConfig) *http.Client {
+		providerClient, err := newProviderClient(client, timeout, transport)
+		if err != nil {
+			problems.add("%s %v", provider, err)
+			return client
+		}
+		clients[provider] = providerClient
+		return providerClient
+	}
 
 	for provider, adapter := range config.Adapters {
 		if adapter != nil {
@@ -89,42 +186,30 @@ func New(config Config) (*Kit, error) {
 
 	if config.OpenAI != nil && adapters[ProviderOpenAI] == nil {
 		keys := normalizeKeys(config.OpenAI.APIKey, config.OpenAI.APIKeys)
-		if len(keys) == 0 {
-			return nil, fmt.Errorf("openai api key is required")
-		}
 		cfg := *config.OpenAI
 		cfg.APIKey = keys[0]
-		adapters[ProviderOpenAI] = newOpenAIAdapter(&cfg, client, ProviderOpenAI)
+		adapters[ProviderOpenAI] = newOpenAIAdapter(&cfg, clientFor(ProviderOpenAI, cfg.Timeout, cfg.Transport), ProviderOpenAI)
 		keyPools[ProviderOpenAI] = newKeyPool(keys)
 	}
 	if config.Anthropic != nil && adapters[ProviderAnthropic] == nil {
 		keys := normalizeKeys(config.Anthropic.APIKey, config.Anthropic.APIKeys)
-		if len(keys) == 0 {
-			return nil, fmt.Errorf("anthropic api key is required")
-		}
 		cfg := *config.Anthropic
 		cfg.APIKey = keys[0]
-		adapters[ProviderAnthropic] = newAnthropicAdapter(&cfg, client, ProviderAnthropic)
+		adapters[ProviderAnthropic] = newAnthropicAdapter(&cfg, clientFor(ProviderAnthropic, cfg.Timeout, cfg.Transport), ProviderAnthropic)
 		keyPools[ProviderAnthropic] = newKeyPool(keys)
 	}
 	if config.XAI != nil && adapters[ProviderXAI] == nil {
 		keys := normalizeKeys(config.XAI.APIKey, config.XAI.APIKeys)
-		if len(keys) == 0 {
-			return nil, fmt.Errorf("xai api key is required")
-		}
 		cfg := *config.XAI
 		cfg.APIKey = keys[0]
-		adapters[ProviderXAI] = newXAIAdapter(&cfg, client)
+		adapters[ProviderXAI] = newXAIAdapter(&cfg, clientFor(ProviderXAI, cfg.Timeout, cfg.Transport))
 		keyPools[ProviderXAI] = newKeyPool(keys)
 	}
 	if config.Google != nil && adapters[ProviderGoogle] == nil {
 		keys := normalizeKeys(config.Google.APIKey, config.Google.APIKeys)
-		if len(keys) == 0 {
-			return nil, fmt.Errorf("google api key is required")
-		}
 		cfg := *config.Google
 		cfg.APIKey = keys[0]
-		adapters[ProviderGoogle] = newGoogleAdapter(&cfg, client)
+		adapters[ProviderGoogle] = newGoogleAdapter(&cfg, clientFor(ProviderGoogle, cfg.Timeout, cfg.Transport))
 		keyPools[ProviderGoogle] = newKeyPool(keys)
 	}
 	if config.Ollama != nil && adapters[ProviderOllama] == nil {
@@ -134,39 +219,89 @@ func New(config Config) (*Kit, error) {
 			cfg.APIKey = keys[0]
 			keyPools[ProviderOllama] = newKeyPool(keys)
 		}
-		adapters[ProviderOllama] = newOllamaAdapter(&cfg, client)
+		adapters[ProviderOllama] = newOllamaAdapter(&cfg, clientFor(ProviderOllama, cfg.Timeout, cfg.Transport))
+	}
+	for _, compat := range config.Compatible {
+		if adapters[compat.Name] != nil {
+			continue
+		}
+		keys := normalizeKeys(compat.APIKey, compat.APIKeys)
+		if len(keys) > 0 {
+			compat.APIKey = keys[0]
+			keyPools[compat.Name] = newKeyPool(keys)
+		}
+		adapters[compat.Name] = newCompatibleAdapter(compat, clientFor(compat.Name, compat.Timeout, compat.Transport))
 	}
-	if len(adapters) == 0 && config.AdapterFactory == nil {
-		return nil, fmt.Errorf("at least one provider config or adapter is required")
+	if err := problems.err(); err != nil {
+		return nil, err
 	}
 	ttl := config.RegistryTTL
 	if ttl == 0 {
 		ttl = 30 * time.Minute
 	}
+	staleTTL := config.RegistryStaleTTL
+	if staleTTL == 0 {
+		staleTTL = 24 * time.Hour
+	}
 	factory := config.AdapterFactory
 	if factory == nil {
-		factory = newAdapterFactory(config, client, adapters)
+		factory = newAdapterFactory(config, client, clients, adapters)
 	}
-	registry := newModelRegistry(adapters, ttl, factory)
-	return &Kit{
+	return &kitState{
+		config:   config,
 		adapters: adapters,
-		registry: registry,
 		factory:  factory,
 		keyPools: keyPools,
+		ttl:      ttl,
+		staleTTL: staleTTL,
 	}, nil
 }
 
+func (h *Kit) current() *kitState {
+	return h.state.Load()
+}
+
+// Providers returns every provider the Kit has an adapter for, built-in and
+// compatible alike, sorted by name.
+func (h *Kit) Providers() []Provider {
+	adapters := h.current().adapters
+	providers := make([]Provider, 0, len(adapters))
+	for provider := range adapters {
+		providers = append(providers, provider)
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i] < providers[j] })
+	return providers
+}
+
 func (h *Kit) ListModels(ctx context.Context, opts *ListModelsOptions) ([]ModelMetadata, error) {
-	models, err := h.registry.List(ctx, opts)
+	result, err := h.ListModelsDetailed(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
-	annotateModelAvailability(models, h.adapters)
-	return models, nil
+	return result.Models, nil
+}
+
+// ListModelsDetailed lists models like ListModels and, with opts.Partial,
+// reports each provider that could not be listed instead of failing.
+func (h *Kit) ListModelsDetailed(ctx context.Context, opts *ListModelsOptions) (ListModelsResult, error) {
+	result, err := h.registry.List(ctx, opts)
+	if err != nil {
+		return ListModelsResult{}, err
+	}
+	annotateModelAvailability(result.Models, h.current().adapters)
+	return result, nil
 }
 
 func (h *Kit) ListModelRecords(ctx context.Context, opts *ListModelsOptions) ([]ModelRecord, error) {
-	return h.registry.ListRecords(ctx, opts)
+	records, err := h.registry.ListRecords(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	aliases := h.current().config.Aliases
+	for idx := range records {
+		records[idx].Aliases = h.catalog.aliasesFor(aliases, records[idx].Provider, records[idx].ProviderModelID)
+	}
+	return records, nil
 }
 
 func annotateModelAvailability(models []ModelMetadata, adapters map[Provider]ProviderAdapter) {
@@ -184,10 +319,12 @@ func annotateModelAvailability(models []ModelMetadata, adapters map[Provider]Pro
 }
 
 func (h *Kit) Generate(ctx context.Context, in GenerateInput) (GenerateOutput, error) {
-	if entitlement := h.entitlementForProvider(in.Provider); entitlement != nil {
-		return h.GenerateWithContext(ctx, entitlement, in)
+	state := h.current()
+	in = h.resolveInput(state, in)
+	if entitlement := state.entitlementForProvider(in.Provider); entitlement != nil {
+		return h.generateWith(ctx, state, entitlement, in)
 	}
-	adapter, ok := h.adapters[in.Provider]
+	adapter, ok := state.adapters[in.Provider]
 	if !ok {
 		return GenerateOutput{}, fmt.Errorf("provider %s is not configured", in.Provider)
 	}
@@ -196,11 +333,16 @@ func (h *Kit) Generate(ctx context.Context, in GenerateInput) (GenerateOutput, e
 		h.registry.LearnModelUnavailable(nil, in.Provider, in.Model, err)
 		return GenerateOutput{}, err
 	}
-	return attachCost(in, output), nil
+	return h.catalog.attachCost(in, output), nil
 }
 
 func (h *Kit) GenerateWithContext(ctx context.Context, entitlement *EntitlementContext, in GenerateInput) (GenerateOutput, error) {
-	adapter, err := h.factory(in.Provider, entitlement)
+	state := h.current()
+	return h.generateWith(ctx, state, entitlement, h.resolveInput(state, in))
+}
+
+func (h *Kit) generateWith(ctx context.Context, state *kitState, entitlement *EntitlementContext, in GenerateInput) (GenerateOutput, error) {
+	adapter, err := state.factory(in.Provider, entitlement)
 	if err != nil {
 		return GenerateOutput{}, err
 	}
@@ -208,14 +350,15 @@ func (h *Kit) GenerateWithContext(ctx context.Context, entitlement *EntitlementC
 	if err != nil {
 		h.registry.LearnModelUnavailable(entitlement, in.Provider, in.Model, err)
 	}
-	return attachCost(in, output), err
+	return h.catalog.attachCost(in, output), err
 }
 
 func (h *Kit) GenerateImage(ctx context.Context, in ImageGenerateInput) (ImageGenerateOutput, error) {
-	if entitlement := h.entitlementForProvider(in.Provider); entitlement != nil {
-		return h.GenerateImageWithContext(ctx, entitlement, in)
+	state := h.current()
+	if entitlement := state.entitlementForProvider(in.Provider); entitlement != nil {
+		return h.generateImageWith(ctx, state, entitlement, in)
 	}
-	adapter, ok := h.adapters[in.Provider]
+	adapter, ok := state.adapters[in.Provider]
 	if !ok {
 		return ImageGenerateOutput{}, fmt.Errorf("provider %s is not configured", in.Provider)
 	}
@@ -228,7 +371,11 @@ func (h *Kit) GenerateImage(ctx context.Context, in ImageGenerateInput) (ImageGe
 }
 
 func (h *Kit) GenerateImageWithContext(ctx context.Context, entitlement *EntitlementContext, in ImageGenerateInput) (ImageGenerateOutput, error) {
-	adapter, err := h.factory(in.Provider, entitlement)
+	return h.generateImageWith(ctx, h.current(), entitlement, in)
+}
+
+func (h *Kit) generateImageWith(ctx context.Context, state *kitState, entitlement *EntitlementContext, in ImageGenerateInput) (ImageGenerateOutput, error) {
+	adapter, err := state.factory(in.Provider, entitlement)
 	if err != nil {
 		return ImageGenerateOutput{}, err
 	}
@@ -240,10 +387,11 @@ func (h *Kit) GenerateImageWithContext(ctx context.Context, entitlement *Entitle
 }
 
 func (h *Kit) GenerateMesh(ctx context.Context, in MeshGenerateInput) (MeshGenerateOutput, error) {
-	if entitlement := h.entitlementForProvider(in.Provider); entitlement != nil {
-		return h.GenerateMeshWithContext(ctx, entitlement, in)
+	state := h.current()
+	if entitlement := state.entitlementForProvider(in.Provider); entitlement != nil {
+		return h.generateMeshWith(ctx, state, entitlement, in)
 	}
-	adapter, ok := h.adapters[in.Provider]
+	adapter, ok := state.adapters[in.Provider]
 	if !ok {
 		return MeshGenerateOutput{}, fmt.Errorf("provider %s is not configured", in.Provider)
 	}
@@ -256,10 +404,11 @@ func (h *Kit) GenerateMesh(ctx context.Context, in MeshGenerateInput) (MeshGener
 }
 
 func (h *Kit) Transcribe(ctx context.Context, in TranscribeInput) (TranscribeOutput, error) {
-	if entitlement := h.entitlementForProvider(in.Provider); entitlement != nil {
-		return h.TranscribeWithContext(ctx, entitlement, in)
+	state := h.current()
+	if entitlement := state.entitlementForProvider(in.Provider); entitlement != nil {
+		return h.transcribeWith(ctx, state, entitlement, in)
 	}
-	adapter, ok := h.adapters[in.Provider]
+	adapter, ok := state.adapters[in.Provider]
 	if !ok {
 		return TranscribeOutput{}, fmt.Errorf("provider %s is not configured", in.Provider)
 	}
@@ -272,7 +421,11 @@ func (h *Kit) Transcribe(ctx context.Context, in TranscribeInput) (TranscribeOut
 }
 
 func (h *Kit) GenerateMeshWithContext(ctx context.Context, entitlement *EntitlementContext, in MeshGenerateInput) (MeshGenerateOutput, error) {
-	adapter, err := h.factory(in.Provider, entitlement)
+	return h.generateMeshWith(ctx, h.current(), entitlement, in)
+}
+
+func (h *Kit) generateMeshWith(ctx context.Context, state *kitState, entitlement *EntitlementContext, in MeshGenerateInput) (MeshGenerateOutput, error) {
+	adapter, err := state.factory(in.Provider, entitlement)
 	if err != nil {
 		return MeshGenerateOutput{}, err
 	}
@@ -284,7 +437,11 @@ func (h *Kit) GenerateMeshWithContext(ctx context.Context, entitlement *Entitlem
 }
 
 func (h *Kit) TranscribeWithContext(ctx context.Context, entitlement *EntitlementContext, in TranscribeInput) (TranscribeOutput, error) {
-	adapter, err := h.factory(in.Provider, entitlement)
+	return h.transcribeWith(ctx, h.current(), entitlement, in)
+}
+
+func (h *Kit) transcribeWith(ctx context.Context, state *kitState, entitlement *EntitlementContext, in TranscribeInput) (TranscribeOutput, error) {
+	adapter, err := state.factory(in.Provider, entitlement)
 	if err != nil {
 		return TranscribeOutput{}, err
 	}
@@ -296,34 +453,41 @@ func (h *Kit) TranscribeWithContext(ctx context.Context, entitlement *Entitlemen
 }
 
 func (h *Kit) StreamGenerate(ctx context.Context, in GenerateInput) (<-chan StreamChunk, error) {
-	if entitlement := h.entitlementForProvider(in.Provider); entitlement != nil {
-		return h.StreamGenerateWithContext(ctx, entitlement, in)
+	state := h.current()
+	in = h.resolveInput(state, in)
+	if entitlement := state.entitlementForProvider(in.Provider); entitlement != nil {
+		return h.streamGenerateWith(ctx, state, entitlement, in)
 	}
-	adapter, ok := h.adapters[in.Provider]
+	adapter, ok := state.adapters[in.Provider]
 	if !ok {
 		return nil, fmt.Errorf("provider %s is not configured", in.Provider)
 	}
-	stream, err := adapter.Stream(ctx, in)
+	stream, err := adapter.Stream(withStreamRequest(ctx), in)
 	if err != nil {
 		return nil, err
 	}
-	return attachCostToStream(in.Provider, in.Model, stream), nil
+	return h.catalog.attachCostToStream(ctx, in.Provider, in.Model, stream), nil
 }
 
 func (h *Kit) StreamGenerateWithContext(ctx context.Context, entitlement *EntitlementContext, in GenerateInput) (<-chan StreamChunk, error) {
-	adapter, err := h.factory(in.Provider, entitlement)
+	state := h.current()
+	return h.streamGenerateWith(ctx, state, entitlement, h.resolveInput(state, in))
+}
+
+func (h *Kit) streamGenerateWith(ctx context.Context, state *kitState, entitlement *EntitlementContext, in GenerateInput) (<-chan StreamChunk, error) {
+	adapter, err := state.factory(in.Provider, entitlement)
 	if err != nil {
 		return nil, err
 	}
-	stream, err := adapter.Stream(ctx, in)
+	stream, err := adapter.Stream(withStreamRequest(ctx), in)
 	if err != nil {
 		return nil, err
 	}
-	return attachCostToStream(in.Provider, in.Model, stream), nil
+	return h.catalog.attachCostToStream(ctx, in.Provider, in.Model, stream), nil
 }
 
-func (h *Kit) entitlementForProvider(provider Provider) *EntitlementContext {
-	pool := h.keyPools[provider]
+func (s *kitState) entitlementForProvider(provider Provider) *EntitlementContext {
+	pool := s.keyPools[provider]
 	if pool == nil {
 		return nil
 	}
@@ -338,32 +502,15 @@ func (h *Kit) entitlementForProvider(provider Provider) *EntitlementContext {
 	}
 }
 
-func attachCost(in GenerateInput, output GenerateOutput) GenerateOutput {
-	cost := estimateCost(in.Provider, in.Model, output.Usage)
-	if cost != nil {
-		output.Cost = cost
-	}
-	return output
-}
-
-func attachCostToStream(provider Provider, model string, stream <-chan StreamChunk) <-chan StreamChunk {
-	out := make(chan StreamChunk)
-	go func() {
-		defer close(out)
-		for chunk := range stream {
-			if chunk.Type == StreamChunkMessageEnd {
-				cost := estimateCost(provider, model, chunk.Usage)
-				if cost != nil {
-					chunk.Cost = cost
-				}
-			}
-			out <- chunk
+// newAdapterFactory builds per-key adapters that share the provider's tuned
+// client from clients, falling back to client.
+func newAdapterFactory(config Config, client *http.Client, clients map[Provider]*http.Client, adapters map[Provider]ProviderAdapter) AdapterFactory {
+	clientFor := func(provider Provider) *http.Client {
+		if providerClient := clients[provider]; providerClient != nil {
+			return providerClient
 		}
-	}()
-	return out
-}
-
-func newAdapterFactory(config Config, client *http.Client, adapters map[Provider]ProviderAdapter) AdapterFactory {
+		return client
+	}
 	return func(provider Provider, entitlement *EntitlementContext) (ProviderAdapter, error) {
 		if entitlement == nil || strings.TrimSpace(entitlement.APIKey) == "" {
 			if adapter, ok := adapters[provider]; ok {
@@ -379,37 +526,52 @@ func newAdapterFactory(config Config, client *http.Client, adapters map[Provider
 			}
 			cfg := *config.OpenAI
 			cfg.APIKey = apiKey
-			return newOpenAIAdapter(&cfg, client, ProviderOpenAI), nil
+			return newOpenAIAdapter(&cfg, clientFor(provider), ProviderOpenAI), nil
 		case ProviderAnthropic:
 			if config.Anthropic == nil {
 				return nil, fmt.Errorf("anthropic config is not available")
 			}
 			cfg := *config.Anthropic
 			cfg.APIKey = apiKey
-			return newAnthropicAdapter(&cfg, client, ProviderAnthropic), nil
+			return newAnthropicAdapter(&cfg, clientFor(provider), ProviderAnthropic), nil
 		case ProviderXAI:
 			if config.XAI == nil {
 				return nil, fmt.Errorf("xai config is not available")
 			}
 			cfg := *config.XAI
 			cfg.APIKey = apiKey
-			return newXAIAdapter(&cfg, client), nil
+			return newXAIAdapter(&cfg, clientFor(provider)), nil
 		case ProviderGoogle:
 			if config.Google == nil {
 				return nil, fmt.Errorf("google config is not available")
 			}
 			cfg := *config.Google
 			cfg.APIKey = apiKey
-			return newGoogleAdapter(&cfg, client), nil
+			return newGoogleAdapter(&cfg, clientFor(provider)), nil
 		case ProviderOllama:
 			if config.Ollama == nil {
 				return nil, fmt.Errorf("ollama config is not available")
 			}
 			cfg := *config.Ollama
 			cfg.APIKey = apiKey
-			return newOllamaAdapter(&cfg, client), nil
+			return newOllamaAdapter(&cfg, clientFor(provider)), nil
 		default:
+			for _, compat := range config.Compatible {
+				if compat.Name == provider {
+					compat.APIKey = apiKey
+					return newCompatibleAdapter(compat, clientFor(provider)), nil
+				}
+			}
 			return nil, fmt.Errorf("provider %s is not configured", provider)
 		}
 	}
 }
+
+// newCompatibleAdapter reuses the OpenAI or Anthropic adapter under the
+// compatible provider's own name.
+func newCompatibleAdapter(compat CompatibleConfig, client *http.Client) ProviderAdapter {
+	if compat.API == compatibleAPIAnthropic {
+		return newAnthropicAdapter(&AnthropicConfig{APIKey: compat.APIKey, BaseURL: compat.BaseURL}, client, compat.Name)
+	}
+	return newOpenAIAdapter(&OpenAIConfig{APIKey: compat.APIKey, BaseURL: compat.BaseURL}, client, compat.Name)
+}
@@ -0,0 +1,116 @@
+// Package cmdconfig loads the configuration file shared by the aikit
+// commands. Provider settings are read by aikit.LoadConfig (YAML or JSON,
+// `${NAME}` interpolation, environment fallback); this package adds the
+// `server` section used by aikit-server.
+package cmdconfig
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	aikit "github.com/Volpestyle/ai-kit/packages/go"
+	"gopkg.in/yaml.v3"
+)
+
+type File struct {
+	Server Server `yaml:"server"`
+	// Kit holds the provider sections and registryTTL.
+	Kit aikit.Config `yaml:"-"`
+}
+
+type Server struct {
+	Addr              string        `yaml:"addr"`
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout"`
+	IdleTimeout       time.Duration `yaml:"idleTimeout"`
+	// RequestTimeout bounds non-streaming requests; streams and WebSockets
+	// run until the client or the provider finishes.
+	RequestTimeout  time.Duration `yaml:"requestTimeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout"`
+	MaxBodyBytes    int64         `yaml:"maxBodyBytes"`
+	// SSEReplayTTL enables Last-Event-ID resumption on /generate/stream.
+	SSEReplayTTL     time.Duration `yaml:"sseReplayTTL"`
+	WebSocketOrigins []string      `yaml:"webSocketOrigins"`
+}
+
+// envReference mirrors the ${NAME} and ${NAME:-default} syntax accepted by
+// aikit.LoadConfig; unset references in the server section expand to "".
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// Load reads path (when non-empty), applies server defaults and loads the
+// provider configuration with aikit.LoadConfig.
+func Load(path string) (File, error) {
+	var file File
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return File{}, err
+		}
+		// Only the server section is decoded here, after substituting
+		// environment references in its values.
+		var raw struct {
+			Server yaml.Node `yaml:"server"`
+		}
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return File{}, fmt.Errorf("parse %s: %w", path, err)
+		}
+		if raw.Server.Kind != 0 {
+			expandEnv(&raw.Server)
+			if err := raw.Server.Decode(&file.Server); err != nil {
+				return File{}, fmt.Errorf("parse %s: %w", path, err)
+			}
+		}
+	}
+	kit, err := aikit.LoadConfig(path)
+	if err != nil {
+		return File{}, err
+	}
+	file.Kit = kit
+	if file.Server.Addr == "" {
+		file.Server.Addr = os.Getenv("AI_KIT_ADDR")
+	}
+	file.Server.applyDefaults()
+	return file, nil
+}
+
+func expandEnv(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode {
+		expanded := envReference.ReplaceAllStringFunc(node.Value, func(ref string) string {
+			match := envReference.FindStringSubmatch(ref)
+			if value, ok := os.LookupEnv(match[1]); ok {
+				return value
+			}
+			return match[3]
+		})
+		if expanded != node.Value && node.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle) == 0 {
+			node.Tag = ""
+		}
+		node.Value = expanded
+		return
+	}
+	for _, child := range node.Content {
+		expandEnv(child)
+	}
+}
+
+func (s *Server) applyDefaults() {
+	if s.Addr == "" {
+		s.Addr = ":8080"
+	}
+	if s.ReadHeaderTimeout == 0 {
+		s.ReadHeaderTimeout = 10 * time.Second
+	}
+	if s.IdleTimeout == 0 {
+		s.IdleTimeout = 2 * time.Minute
+	}
+	if s.RequestTimeout == 0 {
+		s.RequestTimeout = 2 * time.Minute
+	}
+	if s.ShutdownTimeout == 0 {
+		s.ShutdownTimeout = 30 * time.Second
+	}
+	if s.MaxBodyBytes == 0 {
+		s.MaxBodyBytes = 32 << 20
+	}
+}
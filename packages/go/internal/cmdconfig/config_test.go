@@ -0,0 +1,103 @@
+package cmdconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func TestLoadYAMLInterpolatesEnv(t *testing.T) {
+	t.Setenv("TEST_OPENAI_KEY", "sk-from-env")
+	t.Setenv("TEST_PORT", "9000")
+	path := writeConfig(t, "aikit.yaml", `
+server:
+  addr: ":${TEST_PORT}"
+  requestTimeout: 45s
+registryTTL: 5m
+openai:
+  apiKey: ${TEST_OPENAI_KEY}
+  baseURL: https://proxy.example/v1
+ollama:
+  baseURL: http://localhost:11434
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Server.Addr != ":9000" || cfg.Server.RequestTimeout != 45*time.Second || cfg.Kit.RegistryTTL != 5*time.Minute {
+		t.Fatalf("unexpected settings: %+v", cfg)
+	}
+	kitCfg := cfg.Kit
+	if kitCfg.OpenAI == nil || kitCfg.OpenAI.APIKey != "sk-from-env" || kitCfg.OpenAI.BaseURL != "https://proxy.example/v1" {
+		t.Fatalf("unexpected openai config: %+v", kitCfg.OpenAI)
+	}
+	if kitCfg.Ollama == nil || kitCfg.Ollama.BaseURL != "http://localhost:11434" {
+		t.Fatalf("unexpected ollama config: %+v", kitCfg.Ollama)
+	}
+	if cfg.Server.ShutdownTimeout != 30*time.Second || cfg.Server.MaxBodyBytes != 32<<20 {
+		t.Fatalf("defaults not applied: %+v", cfg.Server)
+	}
+}
+
+func TestLoadJSON(t *testing.T) {
+	path := writeConfig(t, "aikit.json", `{"anthropic": {"apiKeys": ["a", "b"], "version": "2023-06-01"}}`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Kit.Anthropic == nil || len(cfg.Kit.Anthropic.APIKeys) != 2 || cfg.Kit.Anthropic.Version != "2023-06-01" {
+		t.Fatalf("unexpected anthropic config: %+v", cfg.Kit.Anthropic)
+	}
+}
+
+func TestLoadFallsBackToEnvironment(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "k1, k2")
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	t.Setenv("GOOGLE_API_KEY", "")
+	t.Setenv("GEMINI_API_KEY", "g1")
+	t.Setenv("XAI_API_KEY", "")
+	t.Setenv("OLLAMA_BASE_URL", "")
+	t.Setenv("AI_KIT_ADDR", "127.0.0.1:7000")
+	path := writeConfig(t, "aikit.yaml", "openai:\n  apiKey: file-key\n")
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Kit.OpenAI.APIKey != "file-key" || len(cfg.Kit.OpenAI.APIKeys) != 0 {
+		t.Fatalf("file section should win over env: %+v", cfg.Kit.OpenAI)
+	}
+
+	cfg, err = Load("")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Kit.OpenAI == nil || len(cfg.Kit.OpenAI.APIKeys) != 2 || cfg.Kit.OpenAI.APIKeys[1] != "k2" {
+		t.Fatalf("expected comma-separated env keys: %+v", cfg.Kit.OpenAI)
+	}
+	if cfg.Kit.Google == nil || cfg.Kit.Google.APIKeys[0] != "g1" || cfg.Kit.Anthropic != nil || cfg.Kit.Ollama != nil {
+		t.Fatalf("unexpected env providers: %+v", cfg)
+	}
+	if cfg.Server.Addr != "127.0.0.1:7000" {
+		t.Fatalf("unexpected addr %q", cfg.Server.Addr)
+	}
+}
+
+func TestLoadReportsParseErrors(t *testing.T) {
+	path := writeConfig(t, "aikit.yaml", "openai: [unclosed")
+	if _, err := Load(path); err == nil {
+		t.Fatalf("expected parse error")
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Fatalf("expected missing file error")
+	}
+}
@@ -28,6 +28,19 @@ func (p *keyPool) Next() string {
 	return p.keys[idx%uint64(len(p.keys))]
 }
 
+// fingerprints returns the FingerprintAPIKey of every key in the pool, the
+// form registry cache keys use.
+func (p *keyPool) fingerprints() map[string]bool {
+	if p == nil {
+		return nil
+	}
+	set := make(map[string]bool, len(p.keys))
+	for _, key := range p.keys {
+		set[FingerprintAPIKey(key)] = true
+	}
+	return set
+}
+
 func normalizeKeys(primary string, extras []string) []string {
 	seen := make(map[string]struct{})
 	var keys []string
@@ -0,0 +1,298 @@
+package aikit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// ModelChangeKind names what changed about a model between two listings.
+type ModelChangeKind string
+
+const (
+	ModelAdded              ModelChangeKind = "added"
+	ModelRemoved            ModelChangeKind = "removed"
+	ModelDeprecationChanged ModelChangeKind = "deprecation_changed"
+	ModelPricingChanged     ModelChangeKind = "pricing_changed"
+)
+
+// ModelChange is one difference between a provider's previous and fresh
+// listing. Previous is nil for added models and Current for removed ones.
+// Both carry curated catalog metadata as it stood at their fetch. Deprecation
+// and pricing changes are reported when either the provider's own listing or
+// the curated values changed; since curated values win, Previous and Current
+// may look alike when the catalog overrides what the provider changed.
+type ModelChange struct {
+	Kind     ModelChangeKind `json:"kind"`
+	Provider Provider        `json:"provider"`
+	Model    string          `json:"model"`
+	Previous *ModelMetadata  `json:"previous,omitempty"`
+	Current  *ModelMetadata  `json:"current,omitempty"`
+}
+
+// ModelChangeEvent reports the changes one fetch found in a registry key's
+// listing. The first fetch of a key has nothing to compare with and emits
+// no event.
+type ModelChangeEvent struct {
+	Key       RegistryKey   `json:"key"`
+	FetchedAt time.Time     `json:"fetchedAt"`
+	Changes   []ModelChange `json:"changes"`
+}
+
+// SubscribeModelChanges calls fn after every listing fetch that changed
+// something, from the goroutine that ran the fetch; hand slow work off
+// rather than blocking it. The returned function unsubscribes.
+func (h *Kit) SubscribeModelChanges(fn func(ModelChangeEvent)) func() {
+	return h.registry.subscribe(fn)
+}
+
+func (r *modelRegistry) subscribe(fn func(ModelChangeEvent)) func() {
+	r.subMu.Lock()
+	defer r.subMu.Unlock()
+	if r.subscribers == nil {
+		r.subscribers = make(map[int]func(ModelChangeEvent))
+	}
+	id := r.nextSubscriber
+	r.nextSubscriber++
+	r.subscribers[id] = fn
+	return func() {
+		r.subMu.Lock()
+		defer r.subMu.Unlock()
+		delete(r.subscribers, id)
+	}
+}
+
+// listingSnapshot is one fetch's listing as the provider reported it and
+// with the catalog applied, so that changes on either side are found.
+type listingSnapshot struct {
+	listed  []ModelMetadata
+	applied []ModelMetadata
+}
+
+// publishChanges diffs a fresh listing against the last one this process
+// saw for key, or the stored one after a restart, and notifies subscribers.
+func (r *modelRegistry) publishChanges(key RegistryKey, previous RegistryEntry, hadPrevious bool, entry RegistryEntry) {
+	current := listingSnapshot{listed: entry.Models, applied: r.applyAll(entry.Models)}
+	r.subMu.Lock()
+	last, seen := r.snapshots[key]
+	if r.snapshots == nil {
+		r.snapshots = make(map[RegistryKey]listingSnapshot)
+	}
+	r.snapshots[key] = current
+	subscribers := make([]func(ModelChangeEvent), 0, len(r.subscribers))
+	for _, fn := range r.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	r.subMu.Unlock()
+	if !seen {
+		if !hadPrevious {
+			return
+		}
+		last = listingSnapshot{listed: previous.Models, applied: r.applyAll(previous.Models)}
+	}
+	changes := diffListings(key.Provider, last, current)
+	if len(changes) == 0 {
+		return
+	}
+	event := ModelChangeEvent{Key: key, FetchedAt: entry.FetchedAt, Changes: changes}
+	for _, fn := range subscribers {
+		fn(event)
+	}
+}
+
+func (r *modelRegistry) applyAll(models []ModelMetadata) []ModelMetadata {
+	applied := make([]ModelMetadata, len(models))
+	for i, model := range models {
+		applied[i] = r.catalog.apply(model)
+	}
+	return applied
+}
+
+func diffListings(provider Provider, previous, current listingSnapshot) []ModelChange {
+	before, listedBefore := modelsByID(previous.applied), modelsByID(previous.listed)
+	after, listedAfter := modelsByID(current.applied), modelsByID(current.listed)
+	var changes []ModelChange
+	for id, model := range after {
+		model := model
+		old, ok := before[id]
+		if !ok {
+			changes = append(changes, ModelChange{Kind: ModelAdded, Provider: provider, Model: id, Current: &model})
+			continue
+		}
+		listedOld, listedNew := listedBefore[id], listedAfter[id]
+		if old.Deprecated != model.Deprecated || listedOld.Deprecated != listedNew.Deprecated {
+			changes = append(changes, ModelChange{Kind: ModelDeprecationChanged, Provider: provider, Model: id, Previous: &old, Current: &model})
+		}
+		if !samePrices(old.TokenPrices, model.TokenPrices) || !samePrices(listedOld.TokenPrices, listedNew.TokenPrices) {
+			changes = append(changes, ModelChange{Kind: ModelPricingChanged, Provider: provider, Model: id, Previous: &old, Current: &model})
+		}
+	}
+	for id, model := range before {
+		model := model
+		if _, ok := after[id]; !ok {
+			changes = append(changes, ModelChange{Kind: ModelRemoved, Provider: provider, Model: id, Previous: &model})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Model == changes[j].Model {
+			return changes[i].Kind < changes[j].Kind
+		}
+		return changes[i].Model < changes[j].Model
+	})
+	return changes
+}
+
+func modelsByID(models []ModelMetadata) map[string]ModelMetadata {
+	byID := make(map[string]ModelMetadata, len(models))
+	for _, model := range models {
+		byID[model.ID] = model
+	}
+	return byID
+}
+
+func samePrices(a, b *TokenPrices) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// WebhookNotifier POSTs each ModelChangeEvent as JSON to URL. Subscribe it
+// with kit.SubscribeModelChanges(notifier.Notify).
+type WebhookNotifier struct {
+	URL     string
+	Headers map[string]string
+	// Client defaults to one with a 10 second timeout.
+	Client *http.Client
+	// OnError receives failed deliveries; nil drops them.
+	OnError func(ModelChangeEvent, error)
+}
+
+// Notify delivers event in the background so the fetch that found the
+// changes is not held up by the webhook.
+func (n *WebhookNotifier) Notify(event ModelChangeEvent) {
+	go func() {
+		if err := n.Send(context.Background(), event); err != nil && n.OnError != nil {
+			n.OnError(event, err)
+		}
+	}()
+}
+
+// Send delivers event and waits for the response; anything but a 2xx is an
+// error.
+func (n *WebhookNotifier) Send(ctx context.Context, event ModelChangeEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range n.Headers {
+		req.Header.Set(name, value)
+	}
+	client := n.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("model change webhook: unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// CatalogReportEntry names one model in a CatalogReport.
+type CatalogReportEntry struct {
+	Provider    Provider `json:"provider"`
+	Model       string   `json:"model"`
+	DisplayName string   `json:"displayName,omitempty"`
+}
+
+// CatalogReport compares live provider listings with a curated catalog.
+// Missing models are listed but have no curated entry, not even through a
+// snapshot suffix or fine-tune base; stale entries are curated but matched
+// by no listed model. Providers whose listing failed are in Errors and
+// contribute no stale entries.
+type CatalogReport struct {
+	Missing []CatalogReportEntry `json:"missing"`
+	Stale   []CatalogReportEntry `json:"stale"`
+	Errors  []ProviderListError  `json:"errors,omitempty"`
+}
+
+// CatalogReportOptions configures Kit.CatalogReport.
+type CatalogReportOptions struct {
+	// Providers limits the report; empty means every configured provider.
+	Providers []Provider
+	// Catalog is compared instead of the Kit's own catalog, in the layout of
+	// the repository's models directory; use os.DirFS for a checkout.
+	Catalog fs.FS
+	// Refresh fetches fresh listings instead of using the registry cache.
+	Refresh bool
+}
+
+// CatalogReport lists the drift between what providers currently list and
+// the curated catalog, to keep the models/<provider> catalog files up to date.
+func (h *Kit) CatalogReport(ctx context.Context, opts *CatalogReportOptions) (CatalogReport, error) {
+	if opts == nil {
+		opts = &CatalogReportOptions{}
+	}
+	curated := h.catalog
+	if opts.Catalog != nil {
+		curated = &catalog{}
+		if err := curated.merge(opts.Catalog); err != nil {
+			return CatalogReport{}, err
+		}
+	}
+	entries, failures, err := h.registry.entriesForProviders(ctx, &ListModelsOptions{
+		Providers: opts.Providers,
+		Refresh:   opts.Refresh,
+		Partial:   true,
+	})
+	if err != nil {
+		return CatalogReport{}, err
+	}
+	report := CatalogReport{Missing: []CatalogReportEntry{}, Stale: []CatalogReportEntry{}, Errors: failures}
+	matched := make(map[string]bool)
+	for provider, entry := range entries {
+		for _, model := range entry.Models {
+			resolved, _, ok := curated.resolve(nil, provider, model.ID)
+			if !ok {
+				report.Missing = append(report.Missing, CatalogReportEntry{Provider: provider, Model: model.ID, DisplayName: model.DisplayName})
+				continue
+			}
+			matched[canonicalID(resolved.Provider, resolved.CatalogID)] = true
+		}
+	}
+	curated.mu.RLock()
+	for _, model := range curated.models {
+		if _, listed := entries[model.Provider]; !listed || matched[canonicalID(model.Provider, model.ID)] {
+			continue
+		}
+		report.Stale = append(report.Stale, CatalogReportEntry{Provider: model.Provider, Model: model.ID, DisplayName: model.DisplayName})
+	}
+	curated.mu.RUnlock()
+	sortReportEntries(report.Missing)
+	sortReportEntries(report.Stale)
+	return report, nil
+}
+
+func sortReportEntries(entries []CatalogReportEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Provider == entries[j].Provider {
+			return entries[i].Model < entries[j].Model
+		}
+		return entries[i].Provider < entries[j].Provider
+	})
+}
@@ -0,0 +1,140 @@
+package aikit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+)
+
+// snapshotAdapter lists whatever models were last stored in it.
+type snapshotAdapter struct {
+	scriptedAdapter
+	models atomic.Value
+}
+
+func (a *snapshotAdapter) ListModels(ctx context.Context) ([]ModelMetadata, error) {
+	return a.models.Load().([]ModelMetadata), nil
+}
+
+func refreshListing(t *testing.T, kit *Kit) {
+	t.Helper()
+	if _, err := kit.ListModels(context.Background(), &ListModelsOptions{Refresh: true}); err != nil {
+		t.Fatalf("list models: %v", err)
+	}
+}
+
+func TestRegistryEmitsModelChanges(t *testing.T) {
+	adapter := &snapshotAdapter{}
+	adapter.models.Store([]ModelMetadata{{ID: "gpt-4", Provider: ProviderOpenAI}, {ID: "gpt-old", Provider: ProviderOpenAI}, {ID: "gpt-x", Provider: ProviderOpenAI}})
+	kit, err := New(Config{Adapters: map[Provider]ProviderAdapter{ProviderOpenAI: adapter}})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	var events []ModelChangeEvent
+	unsubscribe := kit.SubscribeModelChanges(func(event ModelChangeEvent) { events = append(events, event) })
+	refreshListing(t, kit)
+	if len(events) != 0 {
+		t.Fatalf("the first fetch has nothing to compare with, got %+v", events)
+	}
+
+	adapter.models.Store([]ModelMetadata{{ID: "gpt-4", Provider: ProviderOpenAI}, {ID: "gpt-x", Provider: ProviderOpenAI, Deprecated: true}, {ID: "gpt-new", Provider: ProviderOpenAI}})
+	refreshListing(t, kit)
+	if len(events) != 1 || events[0].Key.Provider != ProviderOpenAI {
+		t.Fatalf("expected one event for the openai listing, got %+v", events)
+	}
+	var kinds []string
+	for _, change := range events[0].Changes {
+		kinds = append(kinds, change.Model+" "+string(change.Kind))
+	}
+	if len(kinds) != 3 || kinds[0] != "gpt-new added" || kinds[1] != "gpt-old removed" || kinds[2] != "gpt-x deprecation_changed" {
+		t.Fatalf("unexpected changes: %v", kinds)
+	}
+
+	kit.MergeCatalog(fstest.MapFS{"openai/scraped_models.json": {Data: []byte(`[{"id": "gpt-4", "tokenPrices": {"input": 1, "output": 2}}]`)}})
+	refreshListing(t, kit)
+	if len(events) != 2 || len(events[1].Changes) != 1 || events[1].Changes[0].Kind != ModelPricingChanged {
+		t.Fatalf("expected a catalog repricing to show up on the next fetch, got %+v", events)
+	}
+	if change := events[1].Changes[0]; change.Previous.TokenPrices.Input != 0.03 || change.Current.TokenPrices.Input != 1 {
+		t.Fatalf("expected the old and new prices, got %+v %+v", change.Previous.TokenPrices, change.Current.TokenPrices)
+	}
+
+	// The catalog's deprecated flag wins for gpt-4, but the provider's own
+	// change is still news.
+	adapter.models.Store([]ModelMetadata{{ID: "gpt-4", Provider: ProviderOpenAI, Deprecated: true}, {ID: "gpt-x", Provider: ProviderOpenAI, Deprecated: true}, {ID: "gpt-new", Provider: ProviderOpenAI}})
+	refreshListing(t, kit)
+	if len(events) != 3 || len(events[2].Changes) != 1 || events[2].Changes[0].Model != "gpt-4" || events[2].Changes[0].Kind != ModelDeprecationChanged {
+		t.Fatalf("expected a provider-side deprecation of a curated model, got %+v", events)
+	}
+
+	unsubscribe()
+	adapter.models.Store([]ModelMetadata{{ID: "gpt-4", Provider: ProviderOpenAI}})
+	refreshListing(t, kit)
+	if len(events) != 3 {
+		t.Fatalf("unsubscribed handler still called: %+v", events)
+	}
+}
+
+func TestWebhookNotifierPostsEvents(t *testing.T) {
+	received := make(chan ModelChangeEvent, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer hook" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var event ModelChangeEvent
+		json.NewDecoder(r.Body).Decode(&event)
+		received <- event
+	}))
+	defer server.Close()
+	event := ModelChangeEvent{Key: RegistryKey{Provider: ProviderOpenAI, Fingerprint: "default"}, Changes: []ModelChange{{Kind: ModelAdded, Provider: ProviderOpenAI, Model: "gpt-new"}}}
+
+	notifier := &WebhookNotifier{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer hook"}}
+	if err := notifier.Send(context.Background(), event); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if got := <-received; len(got.Changes) != 1 || got.Changes[0].Model != "gpt-new" {
+		t.Fatalf("unexpected delivery: %+v", got)
+	}
+	if err := (&WebhookNotifier{URL: server.URL}).Send(context.Background(), event); err == nil {
+		t.Fatalf("expected a rejected delivery to be an error")
+	}
+}
+
+func TestCatalogReportFindsMissingAndStaleEntries(t *testing.T) {
+	adapter := &snapshotAdapter{}
+	adapter.models.Store([]ModelMetadata{{ID: "gpt-4-0613", Provider: ProviderOpenAI}, {ID: "gpt-new", Provider: ProviderOpenAI}})
+	kit, err := New(Config{Adapters: map[Provider]ProviderAdapter{
+		ProviderOpenAI:    adapter,
+		ProviderAnthropic: &failingListAdapter{err: &KitError{Kind: ErrorProviderAuth, Message: "invalid x-api-key"}},
+	}})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	report, err := kit.CatalogReport(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("report: %v", err)
+	}
+	if len(report.Missing) != 1 || report.Missing[0].Model != "gpt-new" {
+		t.Fatalf("expected gpt-new missing, got %+v", report.Missing)
+	}
+	if len(report.Stale) != 1 || report.Stale[0].Model != "gpt-3.5-turbo" {
+		t.Fatalf("expected gpt-3.5-turbo stale and the snapshot to match gpt-4, got %+v", report.Stale)
+	}
+	if len(report.Errors) != 1 || report.Errors[0].Provider != ProviderAnthropic {
+		t.Fatalf("expected the failed provider reported, got %+v", report.Errors)
+	}
+
+	files := fstest.MapFS{"openai/scraped_models.json": {Data: []byte(`[{"id": "gpt-new"}]`)}}
+	report, err = kit.CatalogReport(context.Background(), &CatalogReportOptions{Catalog: files, Providers: []Provider{ProviderOpenAI}})
+	if err != nil {
+		t.Fatalf("report: %v", err)
+	}
+	if len(report.Missing) != 1 || report.Missing[0].Model != "gpt-4-0613" || len(report.Stale) != 0 {
+		t.Fatalf("expected a report against the given files only, got %+v", report)
+	}
+}
@@ -0,0 +1,191 @@
+package aikit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type ObjectMode string
+
+const (
+	ObjectModeAuto       ObjectMode = ""
+	ObjectModeJSONSchema ObjectMode = "json_schema"
+	ObjectModeTool       ObjectMode = "tool"
+)
+
+type GenerateObjectOptions struct {
+	Name       string
+	Mode       ObjectMode
+	MaxRepairs *int
+}
+
+type ObjectResult[T any] struct {
+	Object   T              `json:"object"`
+	Output   GenerateOutput `json:"output"`
+	Usage    Usage          `json:"usage"`
+	Cost     *CostBreakdown `json:"cost,omitempty"`
+	Attempts int            `json:"attempts"`
+}
+
+func GenerateObject[T any](ctx context.Context, kit KitAPI, in GenerateInput) (ObjectResult[T], error) {
+	return GenerateObjectWithOptions[T](ctx, kit, in, GenerateObjectOptions{})
+}
+
+func GenerateObjectWithOptions[T any](ctx context.Context, kit KitAPI, in GenerateInput, opts GenerateObjectOptions) (ObjectResult[T], error) {
+	var result ObjectResult[T]
+	name := strings.TrimSpace(opts.Name)
+	if name == "" {
+		name = "response"
+	}
+	maxRepairs := 2
+	if opts.MaxRepairs != nil {
+		maxRepairs = *opts.MaxRepairs
+	}
+	mode := opts.Mode
+	if mode == ObjectModeAuto {
+		mode = objectModeFor(in.Provider)
+	}
+	format, err := ResponseFormatFor[T](name, mode == ObjectModeJSONSchema && strictSchemaFor(in.Provider))
+	if err != nil {
+		format, err = ResponseFormatFor[T](name, false)
+		if err != nil {
+			return result, err
+		}
+	}
+	schema := format.JsonSchema.Schema
+	in.Stream = false
+	if mode == ObjectModeTool {
+		in.Tools = append(append([]ToolDefinition(nil), in.Tools...), ToolDefinition{
+			Name:        name,
+			Description: "Respond with the requested structured output.",
+			Parameters:  schema,
+		})
+		in.ToolChoice = &ToolChoice{Type: "tool", Name: name}
+		in.ResponseFormat = nil
+	} else {
+		in.ResponseFormat = format
+	}
+	messages := append([]Message(nil), in.Messages...)
+	var lastErr error
+	for attempt := 0; attempt <= maxRepairs; attempt++ {
+		in.Messages = messages
+		output, err := kit.Generate(ctx, in)
+		if err != nil {
+			return result, err
+		}
+		result.Attempts++
+		result.Output = output
+		addUsage(&result.Usage, output.Usage)
+		result.Cost = addCost(result.Cost, output.Cost)
+
+		raw, call := objectPayload(output, mode, name)
+		object, err := decodeObject[T](raw, schema)
+		if err == nil {
+			result.Object = object
+			return result, nil
+		}
+		lastErr = err
+		messages = append(messages, assistantMessage(output))
+		feedback := fmt.Sprintf("The previous response was not valid: %s. Reply again with JSON that matches the schema exactly.", err.Error())
+		if call != nil {
+			messages = append(messages, Message{
+				Role:       "tool",
+				ToolCallID: call.ID,
+				Name:       call.Name,
+				Content:    []ContentPart{{Type: "text", Text: toJSONString(map[string]string{"error": feedback})}},
+			})
+		} else {
+			messages = append(messages, Message{
+				Role:    "user",
+				Content: []ContentPart{{Type: "text", Text: feedback}},
+			})
+		}
+	}
+	return result, &KitError{
+		Kind:     ErrorValidation,
+		Message:  fmt.Sprintf("structured output invalid after %d attempts: %v", result.Attempts, lastErr),
+		Provider: in.Provider,
+		Cause:    lastErr,
+	}
+}
+
+// Providers whose adapters send a native JSON schema; anything else (Ollama,
+// custom adapters) is steered through a forced tool call instead.
+func objectModeFor(provider Provider) ObjectMode {
+	switch provider {
+	case ProviderOpenAI, ProviderAnthropic, ProviderGoogle, ProviderXAI:
+		return ObjectModeJSONSchema
+	}
+	return ObjectModeTool
+}
+
+// Gemini's responseSchema is an OpenAPI subset without type arrays or
+// additionalProperties, so Google gets the plain schema, where optional
+// fields are simply not required.
+func strictSchemaFor(provider Provider) bool {
+	return provider != ProviderGoogle
+}
+
+func objectPayload(output GenerateOutput, mode ObjectMode, name string) (string, *ToolCall) {
+	for idx := range output.ToolCalls {
+		if output.ToolCalls[idx].Name == name {
+			return output.ToolCalls[idx].ArgumentsJSON, &output.ToolCalls[idx]
+		}
+	}
+	if mode == ObjectModeTool && len(output.ToolCalls) > 0 {
+		return output.ToolCalls[0].ArgumentsJSON, &output.ToolCalls[0]
+	}
+	return stripCodeFence(output.Text), nil
+}
+
+func decodeObject[T any](raw string, schema map[string]interface{}) (T, error) {
+	var object T
+	if strings.TrimSpace(raw) == "" {
+		return object, fmt.Errorf("response was empty")
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		return object, fmt.Errorf("response is not valid JSON: %v", err)
+	}
+	if err := ValidateJSONSchema(schema, value); err != nil {
+		if kitErr, ok := err.(*KitError); ok {
+			return object, fmt.Errorf("%s", kitErr.Message)
+		}
+		return object, err
+	}
+	if err := json.Unmarshal([]byte(raw), &object); err != nil {
+		return object, fmt.Errorf("response does not decode into %T: %v", object, err)
+	}
+	return object, nil
+}
+
+func stripCodeFence(text string) string {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "```") {
+		return trimmed
+	}
+	trimmed = strings.TrimPrefix(trimmed, "```")
+	if newline := strings.IndexByte(trimmed, '\n'); newline >= 0 {
+		trimmed = trimmed[newline+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(trimmed), "```"))
+}
+
+func addCost(total *CostBreakdown, cost *CostBreakdown) *CostBreakdown {
+	if cost == nil {
+		return total
+	}
+	if total == nil {
+		copied := *cost
+		return &copied
+	}
+	total.InputCostUSD = roundUsd(total.InputCostUSD + cost.InputCostUSD)
+	total.OutputCostUSD = roundUsd(total.OutputCostUSD + cost.OutputCostUSD)
+	total.TotalCostUSD = roundUsd(total.TotalCostUSD + cost.TotalCostUSD)
+	if cost.PricingPerMillion != nil {
+		total.PricingPerMillion = cost.PricingPerMillion
+	}
+	return total
+}
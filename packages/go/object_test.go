@@ -0,0 +1,120 @@
+package aikit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type objectCity struct {
+	Name       string `json:"name"`
+	Population int    `json:"population"`
+	Country    string `json:"country,omitempty" enum:"FR,IT"`
+}
+
+func TestGenerateObjectRepairsInvalidJSON(t *testing.T) {
+	adapter := &scriptedAdapter{outputs: []GenerateOutput{
+		{Text: `{"name":"Paris","population":"lots"}`, Usage: &Usage{InputTokens: 5, OutputTokens: 5, TotalTokens: 10}},
+		{Text: "```json\n{\"name\":\"Paris\",\"population\":2100000,\"country\":\"FR\"}\n```", Usage: &Usage{InputTokens: 8, OutputTokens: 6, TotalTokens: 14}},
+	}}
+	kit := newScriptedKit(t, adapter)
+	result, err := GenerateObject[objectCity](context.Background(), kit, GenerateInput{
+		Provider: ProviderOpenAI,
+		Model:    "test-model",
+		Messages: []Message{{Role: "user", Content: []ContentPart{{Type: "text", Text: "Describe Paris"}}}},
+	})
+	if err != nil {
+		t.Fatalf("generate object: %v", err)
+	}
+	if result.Object.Population != 2100000 || result.Object.Country != "FR" || result.Attempts != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if result.Usage.TotalTokens != 24 {
+		t.Fatalf("expected usage across attempts, got %+v", result.Usage)
+	}
+	first := adapter.inputs[0]
+	if first.ResponseFormat == nil || first.ResponseFormat.JsonSchema == nil || !first.ResponseFormat.JsonSchema.Strict {
+		t.Fatalf("expected strict json_schema response format: %+v", first.ResponseFormat)
+	}
+	repair := adapter.inputs[1].Messages
+	last := repair[len(repair)-1]
+	if last.Role != "user" || !strings.Contains(last.Content[0].Text, "population") {
+		t.Fatalf("expected validation feedback in repair prompt: %+v", last)
+	}
+}
+
+func TestGenerateObjectToolModeAndExhaustedRepairs(t *testing.T) {
+	adapter := &scriptedAdapter{outputs: []GenerateOutput{
+		{ToolCalls: []ToolCall{{ID: "call_1", Name: "city", ArgumentsJSON: `{"name":"Rome","population":2800000,"country":"IT"}`}}},
+	}}
+	kit, err := New(Config{Adapters: map[Provider]ProviderAdapter{ProviderOllama: adapter}})
+	if err != nil {
+		t.Fatalf("new kit: %v", err)
+	}
+	result, err := GenerateObjectWithOptions[objectCity](context.Background(), kit, GenerateInput{Provider: ProviderOllama, Model: "llama"}, GenerateObjectOptions{Name: "city"})
+	if err != nil {
+		t.Fatalf("generate object: %v", err)
+	}
+	if result.Object.Name != "Rome" {
+		t.Fatalf("unexpected object: %+v", result.Object)
+	}
+	input := adapter.inputs[0]
+	if input.ResponseFormat != nil || input.ToolChoice == nil || input.ToolChoice.Name != "city" || len(input.Tools) != 1 {
+		t.Fatalf("expected forced tool call fallback: %+v", input)
+	}
+
+	adapter = &scriptedAdapter{outputs: []GenerateOutput{{Text: `{"name":"Nowhere","population":1,"country":"XX"}`}}}
+	zero := 0
+	_, err = GenerateObjectWithOptions[objectCity](context.Background(), newScriptedKit(t, adapter), GenerateInput{Provider: ProviderOpenAI, Model: "test-model"}, GenerateObjectOptions{MaxRepairs: &zero})
+	kitErr, ok := err.(*KitError)
+	if !ok || kitErr.Kind != ErrorValidation || len(adapter.inputs) != 1 {
+		t.Fatalf("expected validation error without repairs, got %v after %d calls", err, len(adapter.inputs))
+	}
+}
+
+type objectPerson struct {
+	Name     string  `json:"name"`
+	Nickname *string `json:"nickname,omitempty"`
+}
+
+func TestGenerateObjectSendsGeminiCompatibleSchema(t *testing.T) {
+	var config map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			GenerationConfig map[string]interface{} `json:"generationConfig"`
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		config = payload.GenerationConfig
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"{\"name\":\"Ada\"}"}]},"finishReason":"STOP"}]}`))
+	}))
+	defer server.Close()
+	kit, err := New(Config{Google: &GoogleConfig{APIKey: "k", BaseURL: server.URL}})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	result, err := GenerateObject[objectPerson](context.Background(), kit, GenerateInput{Provider: ProviderGoogle, Model: "gemini-2.5-flash"})
+	if err != nil || result.Object.Name != "Ada" {
+		t.Fatalf("generate object: %+v %v", result.Object, err)
+	}
+	schema, _ := config["responseSchema"].(map[string]interface{})
+	raw, _ := json.Marshal(schema)
+	if strings.Contains(string(raw), "additionalProperties") || strings.Contains(string(raw), "null") {
+		t.Fatalf("responseSchema uses keywords Gemini rejects: %s", raw)
+	}
+	if required, _ := schema["required"].([]interface{}); len(required) != 1 || required[0] != "name" {
+		t.Fatalf("expected only the required field to be required, got %s", raw)
+	}
+
+	strict, err := JSONSchemaFor[objectPerson](SchemaOptions{Strict: true})
+	if err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	nickname := geminiResponseSchema(strict)["properties"].(map[string]interface{})["nickname"].(map[string]interface{})
+	if nickname["type"] != "string" || nickname["nullable"] != true {
+		t.Fatalf("expected a strict nullable field as nullable:true, got %+v", nickname)
+	}
+}
@@ -0,0 +1,411 @@
+package aikit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ParsePartialJSON decodes a possibly truncated JSON document into the same
+// shapes encoding/json produces for interface{} targets. Open strings, arrays
+// and objects are closed; dangling keys, partial literals and unparseable
+// number prefixes are dropped. It returns nil when no value has started yet
+// and an error only for input that can never become valid JSON.
+func ParsePartialJSON(raw string) (interface{}, error) {
+	parser := &partialParser{src: raw}
+	parser.skipSpace()
+	if parser.eof() {
+		return nil, nil
+	}
+	value, complete, present, err := parser.value()
+	if err != nil {
+		return nil, err
+	}
+	if !present {
+		return nil, nil
+	}
+	if complete {
+		parser.skipSpace()
+		if !parser.eof() {
+			return nil, parser.syntaxError("unexpected trailing content")
+		}
+	}
+	return value, nil
+}
+
+type PartialJSONDecoder struct {
+	buf  strings.Builder
+	last interface{}
+}
+
+// Write appends a fragment and returns the best-effort value so far. On a
+// syntax error the previous value is returned alongside the error.
+func (d *PartialJSONDecoder) Write(fragment string) (interface{}, error) {
+	d.buf.WriteString(fragment)
+	return d.parse()
+}
+
+// Set replaces the buffer, for producers that emit cumulative state.
+func (d *PartialJSONDecoder) Set(full string) (interface{}, error) {
+	d.buf.Reset()
+	d.buf.WriteString(full)
+	return d.parse()
+}
+
+func (d *PartialJSONDecoder) Value() interface{} {
+	return d.last
+}
+
+func (d *PartialJSONDecoder) String() string {
+	return d.buf.String()
+}
+
+func (d *PartialJSONDecoder) parse() (interface{}, error) {
+	value, err := ParsePartialJSON(stripLeadingFence(d.buf.String()))
+	if err != nil {
+		return d.last, err
+	}
+	if value != nil {
+		d.last = value
+	}
+	return d.last, nil
+}
+
+type PartialChunk struct {
+	StreamChunk
+	Partial interface{} `json:"partial,omitempty"`
+}
+
+// StreamPartialJSON forwards every chunk and attaches the partial object
+// decoded so far: from accumulated TextDelta for delta chunks, and from the
+// arguments of the call at chunk.Index for tool-call chunks.
+func StreamPartialJSON(ctx context.Context, stream <-chan StreamChunk) <-chan PartialChunk {
+	out := make(chan PartialChunk)
+	go func() {
+		defer close(out)
+		text := &PartialJSONDecoder{}
+		calls := map[int]*PartialJSONDecoder{}
+		legacy := map[string]*PartialJSONDecoder{}
+		for chunk := range stream {
+			partial := PartialChunk{StreamChunk: chunk}
+			switch {
+			case chunk.Type == StreamChunkDelta && chunk.TextDelta != "":
+				partial.Partial, _ = text.Write(chunk.TextDelta)
+			case chunk.Type == StreamChunkToolCallStart, chunk.Type == StreamChunkToolCallDelta, chunk.Type == StreamChunkToolCallEnd:
+				decoder := calls[chunk.Index]
+				if decoder == nil {
+					decoder = &PartialJSONDecoder{}
+					calls[chunk.Index] = decoder
+				}
+				if chunk.Type == StreamChunkToolCallEnd && chunk.Call != nil {
+					partial.Partial, _ = decoder.Set(chunk.Call.ArgumentsJSON)
+				} else {
+					partial.Partial, _ = decoder.Write(chunk.Delta)
+				}
+			case chunk.Type == StreamChunkToolCall && chunk.Call != nil:
+				key := chunk.Call.ID
+				if key == "" {
+					key = chunk.Call.Name
+				}
+				decoder := legacy[key]
+				if decoder == nil {
+					decoder = &PartialJSONDecoder{}
+					legacy[key] = decoder
+				}
+				if chunk.Call.ArgumentsJSON != "" {
+					partial.Partial, _ = decoder.Set(chunk.Call.ArgumentsJSON)
+				} else {
+					partial.Partial, _ = decoder.Write(chunk.Delta)
+				}
+			}
+			select {
+			case out <- partial:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+func stripLeadingFence(raw string) string {
+	trimmed := strings.TrimLeft(raw, " \t\r\n")
+	if !strings.HasPrefix(trimmed, "```") {
+		return raw
+	}
+	newline := strings.IndexByte(trimmed, '\n')
+	if newline < 0 {
+		return ""
+	}
+	body := trimmed[newline+1:]
+	if end := strings.LastIndex(body, "```"); end >= 0 {
+		body = body[:end]
+	}
+	return body
+}
+
+type partialParser struct {
+	src string
+	pos int
+}
+
+func (p *partialParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *partialParser) skipSpace() {
+	for !p.eof() {
+		switch p.src[p.pos] {
+		case ' ', '\t', '\r', '\n':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *partialParser) syntaxError(message string) error {
+	return &KitError{Kind: ErrorValidation, Message: fmt.Sprintf("partial json: %s at offset %d", message, p.pos)}
+}
+
+// value returns the parsed value, whether it is complete, and whether any
+// usable value was present at all.
+func (p *partialParser) value() (interface{}, bool, bool, error) {
+	p.skipSpace()
+	if p.eof() {
+		return nil, false, false, nil
+	}
+	switch c := p.src[p.pos]; {
+	case c == '{':
+		value, complete, err := p.object()
+		return value, complete, true, err
+	case c == '[':
+		value, complete, err := p.array()
+		return value, complete, true, err
+	case c == '"':
+		value, complete := p.str()
+		return value, complete, true, nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.number()
+	case c == 't' || c == 'f' || c == 'n':
+		return p.literal()
+	default:
+		return nil, false, false, p.syntaxError(fmt.Sprintf("unexpected character %q", c))
+	}
+}
+
+func (p *partialParser) object() (map[string]interface{}, bool, error) {
+	result := map[string]interface{}{}
+	p.pos++
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return result, false, nil
+		}
+		if p.src[p.pos] == '}' {
+			p.pos++
+			return result, true, nil
+		}
+		if p.src[p.pos] != '"' {
+			return result, false, p.syntaxError("expected object key")
+		}
+		key, complete := p.str()
+		if !complete {
+			return result, false, nil
+		}
+		p.skipSpace()
+		if p.eof() {
+			return result, false, nil
+		}
+		if p.src[p.pos] != ':' {
+			return result, false, p.syntaxError("expected ':'")
+		}
+		p.pos++
+		value, complete, present, err := p.value()
+		if err != nil {
+			return result, false, err
+		}
+		if present {
+			result[key] = value
+		}
+		if !complete {
+			return result, false, nil
+		}
+		p.skipSpace()
+		if p.eof() {
+			return result, false, nil
+		}
+		switch p.src[p.pos] {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return result, true, nil
+		default:
+			return result, false, p.syntaxError("expected ',' or '}'")
+		}
+	}
+}
+
+func (p *partialParser) array() ([]interface{}, bool, error) {
+	result := []interface{}{}
+	p.pos++
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return result, false, nil
+		}
+		if p.src[p.pos] == ']' {
+			p.pos++
+			return result, true, nil
+		}
+		value, complete, present, err := p.value()
+		if err != nil {
+			return result, false, err
+		}
+		if present {
+			result = append(result, value)
+		}
+		if !complete {
+			return result, false, nil
+		}
+		p.skipSpace()
+		if p.eof() {
+			return result, false, nil
+		}
+		switch p.src[p.pos] {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return result, true, nil
+		default:
+			return result, false, p.syntaxError("expected ',' or ']'")
+		}
+	}
+}
+
+func (p *partialParser) str() (string, bool) {
+	var sb strings.Builder
+	p.pos++
+	for !p.eof() {
+		c := p.src[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return sb.String(), true
+		case c == '\\':
+			if p.pos+1 >= len(p.src) {
+				p.pos = len(p.src)
+				return sb.String(), false
+			}
+			esc := p.src[p.pos+1]
+			switch esc {
+			case 'u':
+				r, width, ok := p.unicodeEscape()
+				if !ok {
+					p.pos = len(p.src)
+					return sb.String(), false
+				}
+				sb.WriteRune(r)
+				p.pos += width
+				continue
+			case 'b':
+				sb.WriteByte('\b')
+			case 'f':
+				sb.WriteByte('\f')
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			default:
+				sb.WriteByte(esc)
+			}
+			p.pos += 2
+		default:
+			r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+			if r == utf8.RuneError && size == 1 && !utf8.FullRuneInString(p.src[p.pos:]) {
+				p.pos = len(p.src)
+				return sb.String(), false
+			}
+			sb.WriteString(p.src[p.pos : p.pos+size])
+			p.pos += size
+		}
+	}
+	return sb.String(), false
+}
+
+func (p *partialParser) unicodeEscape() (rune, int, bool) {
+	readHex := func(start int) (rune, bool) {
+		if start+4 > len(p.src) {
+			return 0, false
+		}
+		value, err := strconv.ParseUint(p.src[start:start+4], 16, 32)
+		if err != nil {
+			return utf8.RuneError, true
+		}
+		return rune(value), true
+	}
+	r, ok := readHex(p.pos + 2)
+	if !ok {
+		return 0, 0, false
+	}
+	if !utf16.IsSurrogate(r) {
+		return r, 6, true
+	}
+	if p.pos+8 > len(p.src) {
+		return 0, 0, false
+	}
+	if p.src[p.pos+6] != '\\' || p.src[p.pos+7] != 'u' {
+		return utf8.RuneError, 6, true
+	}
+	low, ok := readHex(p.pos + 8)
+	if !ok {
+		return 0, 0, false
+	}
+	return utf16.DecodeRune(r, low), 12, true
+}
+
+func (p *partialParser) number() (interface{}, bool, bool, error) {
+	start := p.pos
+	for !p.eof() && strings.IndexByte("+-0123456789.eE", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+	raw := p.src[start:p.pos]
+	complete := !p.eof()
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		if complete {
+			return nil, false, false, p.syntaxError(fmt.Sprintf("invalid number %q", raw))
+		}
+		trimmed := strings.TrimRight(raw, "+-.eE")
+		value, err = strconv.ParseFloat(trimmed, 64)
+		if err != nil {
+			return nil, false, false, nil
+		}
+	}
+	return value, complete, true, nil
+}
+
+func (p *partialParser) literal() (interface{}, bool, bool, error) {
+	for _, candidate := range []struct {
+		text  string
+		value interface{}
+	}{{"true", true}, {"false", false}, {"null", nil}} {
+		rest := p.src[p.pos:]
+		if strings.HasPrefix(rest, candidate.text) {
+			p.pos += len(candidate.text)
+			return candidate.value, true, true, nil
+		}
+		if strings.HasPrefix(candidate.text, rest) {
+			p.pos = len(p.src)
+			return nil, false, false, nil
+		}
+	}
+	return nil, false, false, p.syntaxError("invalid literal")
+}
@@ -0,0 +1,88 @@
+package aikit
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestParsePartialJSON(t *testing.T) {
+	cases := []struct {
+		input string
+		want  interface{}
+	}{
+		{``, nil},
+		{`{`, map[string]interface{}{}},
+		{`{"na`, map[string]interface{}{}},
+		{`{"name"`, map[string]interface{}{}},
+		{`{"name": "Par`, map[string]interface{}{"name": "Par"}},
+		{`{"name": "Paris", "tags": ["a", "b`, map[string]interface{}{"name": "Paris", "tags": []interface{}{"a", "b"}}},
+		{`{"ok": tr`, map[string]interface{}{}},
+		{`{"ok": true, "n": 12`, map[string]interface{}{"ok": true, "n": float64(12)}},
+		{`{"n": -`, map[string]interface{}{}},
+		{`{"n": 1.`, map[string]interface{}{"n": float64(1)}},
+		{`{"nested": {"x": null, "y": [1, {"z": "é\`, map[string]interface{}{
+			"nested": map[string]interface{}{"x": nil, "y": []interface{}{float64(1), map[string]interface{}{"z": "é"}}},
+		}},
+		{`[1, 2,`, []interface{}{float64(1), float64(2)}},
+		{`{"done": "yes"}`, map[string]interface{}{"done": "yes"}},
+	}
+	for _, tc := range cases {
+		got, err := ParsePartialJSON(tc.input)
+		if err != nil {
+			t.Fatalf("%q: unexpected error %v", tc.input, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("%q: expected %#v, got %#v", tc.input, tc.want, got)
+		}
+	}
+	if _, err := ParsePartialJSON(`{"a": 1 x`); err == nil {
+		t.Fatalf("expected syntax error")
+	}
+}
+
+func TestPartialJSONDecoderKeepsLastGoodValue(t *testing.T) {
+	decoder := &PartialJSONDecoder{}
+	for _, fragment := range []string{"```json\n{\"ti", "tle\": \"Hel", "lo\"}"} {
+		if _, err := decoder.Write(fragment); err != nil {
+			t.Fatalf("write %q: %v", fragment, err)
+		}
+	}
+	if !reflect.DeepEqual(decoder.Value(), map[string]interface{}{"title": "Hello"}) {
+		t.Fatalf("unexpected value: %#v", decoder.Value())
+	}
+	value, err := decoder.Write(" garbage")
+	if err == nil || !reflect.DeepEqual(value, map[string]interface{}{"title": "Hello"}) {
+		t.Fatalf("expected last good value with error, got %#v, %v", value, err)
+	}
+}
+
+func TestStreamPartialJSON(t *testing.T) {
+	stream := make(chan StreamChunk, 5)
+	stream <- StreamChunk{Type: StreamChunkDelta, TextDelta: `{"answer": "4`}
+	stream <- StreamChunk{Type: StreamChunkDelta, TextDelta: `2"}`}
+	stream <- StreamChunk{Type: StreamChunkToolCall, Call: &ToolCall{ID: "c1", Name: "lookup"}, Delta: `{"q": "go`}
+	stream <- StreamChunk{Type: StreamChunkToolCall, Call: &ToolCall{ID: "c1", Name: "lookup", ArgumentsJSON: `{"q": "golang", "n": 3`}}
+	stream <- StreamChunk{Type: StreamChunkMessageEnd, FinishReason: "stop"}
+	close(stream)
+
+	var updates []PartialChunk
+	for chunk := range StreamPartialJSON(context.Background(), stream) {
+		updates = append(updates, chunk)
+	}
+	if len(updates) != 5 {
+		t.Fatalf("expected every chunk to be forwarded, got %d", len(updates))
+	}
+	if !reflect.DeepEqual(updates[1].Partial, map[string]interface{}{"answer": "42"}) {
+		t.Fatalf("unexpected text partial: %#v", updates[1].Partial)
+	}
+	if !reflect.DeepEqual(updates[2].Partial, map[string]interface{}{"q": "go"}) {
+		t.Fatalf("unexpected delta partial: %#v", updates[2].Partial)
+	}
+	if !reflect.DeepEqual(updates[3].Partial, map[string]interface{}{"q": "golang", "n": float64(3)}) {
+		t.Fatalf("unexpected cumulative partial: %#v", updates[3].Partial)
+	}
+	if updates[4].Partial != nil || updates[4].Type != StreamChunkMessageEnd {
+		t.Fatalf("unexpected final chunk: %#v", updates[4])
+	}
+}
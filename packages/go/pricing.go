@@ -1,77 +1,11 @@
 package aikit
 
 import (
-	"encoding/json"
+	"context"
 	"math"
-	"os"
-	"path/filepath"
-	"runtime"
 	"strings"
-	"sync"
 )
 
-type curatedModel struct {
-	ID            string            `json:"id"`
-	DisplayName   string            `json:"displayName"`
-	Provider      Provider          `json:"provider"`
-	Family        string            `json:"family"`
-	Capabilities  ModelCapabilities `json:"capabilities"`
-	ContextWindow int               `json:"contextWindow"`
-	TokenPrices   *TokenPrices      `json:"tokenPrices"`
-	Deprecated    bool              `json:"deprecated"`
-	InPreview     bool              `json:"inPreview"`
-}
-
-var curatedOnce sync.Once
-var curatedModels []curatedModel
-
-func loadCuratedModels() []curatedModel {
-	curatedOnce.Do(func() {
-		root := modelsRoot()
-		if root == "" {
-			curatedModels = []curatedModel{}
-			return
-		}
-		entries, err := os.ReadDir(root)
-		if err != nil {
-			curatedModels = []curatedModel{}
-			return
-		}
-		output := make([]curatedModel, 0)
-		for _, entry := range entries {
-			if !entry.IsDir() {
-				continue
-			}
-			provider := entry.Name()
-			path := filepath.Join(root, provider, "scraped_models.json")
-			raw, err := os.ReadFile(path)
-			if err != nil {
-				continue
-			}
-			var models []curatedModel
-			if err := json.Unmarshal(raw, &models); err != nil {
-				continue
-			}
-			for i := range models {
-				if models[i].Provider == "" {
-					models[i].Provider = Provider(provider)
-				}
-				output = append(output, models[i])
-			}
-		}
-		curatedModels = output
-	})
-	return curatedModels
-}
-
-func modelsRoot() string {
-	_, filename, _, ok := runtime.Caller(0)
-	if !ok {
-		return ""
-	}
-	return filepath.Join(filepath.Dir(filename), "..", "..", "models")
-}
-
 func normalizeModelID(provider Provider, modelID string) string {
 	prefix := string(provider) + "/"
 	if strings.HasPrefix(modelID, prefix) {
@@ -80,29 +14,12 @@ func normalizeModelID(provider Provider, modelID string) string {
 	return modelID
 }
 
-func findCuratedModel(provider Provider, modelID string) *curatedModel {
-	normalized := normalizeModelID(provider, modelID)
-	var best *curatedModel
-	for i := range loadCuratedModels() {
-		model := &curatedModels[i]
-		if model.Provider != provider {
-			continue
-		}
-		if model.ID == normalized {
-			return model
-		}
-		if strings.HasPrefix(normalized, model.ID) {
-			if best == nil || len(model.ID) > len(best.ID) {
-				best = model
-			}
-		}
-	}
-	return best
-}
-
-func applyCuratedMetadata(model ModelMetadata) ModelMetadata {
-	curated := findCuratedModel(model.Provider, model.ID)
-	if curated == nil {
+// apply fills in curated metadata for a listed model, matching dated
+// snapshots and fine-tunes to their catalog entry. Curated values win; what
+// the catalog leaves unknown keeps whatever the provider listing reported.
+func (c *catalog) apply(model ModelMetadata) ModelMetadata {
+	_, curated, ok := c.resolve(nil, model.Provider, model.ID)
+	if !ok {
 		return model
 	}
 	if curated.DisplayName != "" {
@@ -111,10 +28,13 @@ func applyCuratedMetadata(model ModelMetadata) ModelMetadata {
 	if curated.Family != "" {
 		model.Family = curated.Family
 	}
-	model.Capabilities = curated.Capabilities
+	model.Capabilities = model.Capabilities.withKnown(curated.Capabilities)
 	if curated.ContextWindow > 0 {
 		model.ContextWindow = curated.ContextWindow
 	}
+	if curated.MaxOutputTokens > 0 {
+		model.MaxOutputTokens = curated.MaxOutputTokens
+	}
 	if curated.TokenPrices != nil {
 		model.TokenPrices = curated.TokenPrices
 	}
@@ -123,19 +43,19 @@ func applyCuratedMetadata(model ModelMetadata) ModelMetadata {
 	return model
 }
 
-func lookupTokenPrices(provider Provider, modelID string) *TokenPrices {
-	curated := findCuratedModel(provider, modelID)
-	if curated == nil {
+func (c *catalog) tokenPrices(provider Provider, modelID string) *TokenPrices {
+	_, curated, ok := c.resolve(nil, provider, modelID)
+	if !ok {
 		return nil
 	}
 	return curated.TokenPrices
 }
 
-func estimateCost(provider Provider, modelID string, usage *Usage) *CostBreakdown {
+func (c *catalog) estimateCost(provider Provider, modelID string, usage *Usage) *CostBreakdown {
 	if usage == nil {
 		return nil
 	}
-	pricing := lookupTokenPrices(provider, modelID)
+	pricing := c.tokenPrices(provider, modelID)
 	if pricing == nil {
 		return nil
 	}
@@ -145,13 +65,49 @@ func estimateCost(provider Provider, modelID string, usage *Usage) *CostBreakdow
 	inputCost := float64(usage.InputTokens) * pricing.Input / 1_000_000
 	outputCost := float64(usage.OutputTokens) * pricing.Output / 1_000_000
 	return &CostBreakdown{
-		InputCostUSD:    roundUsd(inputCost),
-		OutputCostUSD:   roundUsd(outputCost),
-		TotalCostUSD:    roundUsd(inputCost + outputCost),
+		InputCostUSD:      roundUsd(inputCost),
+		OutputCostUSD:     roundUsd(outputCost),
+		TotalCostUSD:      roundUsd(inputCost + outputCost),
 		PricingPerMillion: pricing,
 	}
 }
 
+func (c *catalog) attachCost(in GenerateInput, output GenerateOutput) GenerateOutput {
+	cost := c.estimateCost(in.Provider, in.Model, output.Usage)
+	if cost != nil {
+		output.Cost = cost
+	}
+	return output
+}
+
+// attachCostToStream stops forwarding once ctx is done but keeps draining
+// stream, so adapters that ignore ctx are not left blocked on a send.
+func (c *catalog) attachCostToStream(ctx context.Context, provider Provider, model string, stream <-chan StreamChunk) <-chan StreamChunk {
+	out := make(chan StreamChunk)
+	go func() {
+		defer close(out)
+		seq := 0
+		for chunk := range stream {
+			if ctx.Err() != nil {
+				continue
+			}
+			seq++
+			chunk.Seq = seq
+			if chunk.Type == StreamChunkMessageEnd {
+				cost := c.estimateCost(provider, model, chunk.Usage)
+				if cost != nil {
+					chunk.Cost = cost
+				}
+			}
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return out
+}
+
 func roundUsd(value float64) float64 {
 	return math.Round(value*1_000_000) / 1_000_000
 }
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
@@ -18,7 +19,10 @@ type anthropicAdapter struct {
 
 type anthropicModelList struct {
 	Data []struct {
-		ID string `json:"id"`
+		ID             string `json:"id"`
+		DisplayName    string `json:"display_name"`
+		MaxInputTokens int    `json:"max_input_tokens"`
+		MaxTokens      int    `json:"max_tokens"`
 	} `json:"data"`
 }
 
@@ -47,14 +51,25 @@ type anthropicStreamEvent struct {
 		Type        string `json:"type"`
 		Text        string `json:"text,omitempty"`
 		PartialJSON string `json:"partial_json,omitempty"`
+		StopReason  string `json:"stop_reason,omitempty"`
 	} `json:"delta"`
 	Usage struct {
 		InputTokens  int `json:"input_tokens"`
 		OutputTokens int `json:"output_tokens"`
 	} `json:"usage"`
 	Message struct {
+		ID         string `json:"id"`
+		Model      string `json:"model"`
 		StopReason string `json:"stop_reason"`
+		Usage      struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
 	} `json:"message"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
 }
 
 func newAnthropicAdapter(cfg *AnthropicConfig, client *http.Client, provider Provider) ProviderAdapter {
@@ -87,11 +102,17 @@ func (a *anthropicAdapter) ListModels(ctx context.Context) ([]ModelMetadata, err
 	}
 	models := make([]ModelMetadata, 0, len(payload.Data))
 	for _, model := range payload.Data {
+		displayName := model.DisplayName
+		if displayName == "" {
+			displayName = model.ID
+		}
 		models = append(models, ModelMetadata{
-			ID:          model.ID,
-			DisplayName: model.ID,
-			Provider:    a.provider,
-			Family:      deriveFamily(model.ID),
+			ID:              model.ID,
+			DisplayName:     displayName,
+			Provider:        a.provider,
+			Family:          deriveFamily(model.ID),
+			ContextWindow:   model.MaxInputTokens,
+			MaxOutputTokens: model.MaxTokens,
 		})
 	}
 	return models, nil
@@ -150,69 +171,115 @@ func (a *anthropicAdapter) Stream(ctx context.Context, in GenerateInput) (<-chan
 	go func() {
 		defer close(ch)
 		defer resp.Body.Close()
+		emitter := newStreamEmitter(ctx, ch, a.provider, in.Model, resp)
 		events := streamSSE(ctx, resp.Body)
-		toolStates := map[int]*ToolCall{}
 		var usage *Usage
 		var finishReason string
+		var readErr error
+		stopped := false
 		for event := range events {
+			if event.Err != nil {
+				readErr = event.Err
+				break
+			}
 			if event.Data == "" || event.Data == "[DONE]" {
 				continue
 			}
 			var payload anthropicStreamEvent
 			if err := json.Unmarshal([]byte(event.Data), &payload); err != nil {
+				emitter.decodeError(err)
 				continue
 			}
-			if payload.Usage.InputTokens != 0 || payload.Usage.OutputTokens != 0 {
-				usage = &Usage{
-					InputTokens:  payload.Usage.InputTokens,
-					OutputTokens: payload.Usage.OutputTokens,
-					TotalTokens:  payload.Usage.InputTokens + payload.Usage.OutputTokens,
+			// message_start reports input tokens; message_delta reports the
+			// cumulative output count (and sometimes omits input tokens).
+			for _, reported := range []struct{ input, output int }{
+				{payload.Message.Usage.InputTokens, payload.Message.Usage.OutputTokens},
+				{payload.Usage.InputTokens, payload.Usage.OutputTokens},
+			} {
+				if reported.input == 0 && reported.output == 0 {
+					continue
+				}
+				if usage == nil {
+					usage = &Usage{}
+				}
+				if reported.input != 0 {
+					usage.InputTokens = reported.input
+				}
+				if reported.output != 0 {
+					usage.OutputTokens = reported.output
 				}
+				usage.TotalTokens = usage.InputTokens + usage.OutputTokens
 			}
+			// Content block indexes count text blocks too; tool keys use them
+			// while the emitter assigns the tool call ordinal.
+			key := strconv.Itoa(payload.Index)
 			switch payload.Type {
+			case "message_start":
+				emitter.start(payload.Message.Model, payload.Message.ID)
 			case "content_block_delta":
-				if payload.Delta.Type == "text_delta" && payload.Delta.Text != "" {
-					ch <- StreamChunk{Type: StreamChunkDelta, TextDelta: payload.Delta.Text}
+				if payload.Delta.Type == "text_delta" {
+					emitter.text(payload.Delta.Text)
 				}
-				if payload.Delta.Type == "input_json_delta" && payload.Index >= 0 {
-					state := toolStates[payload.Index]
-					if state != nil {
-						state.ArgumentsJSON += payload.Delta.PartialJSON
-						ch <- StreamChunk{
-							Type:  StreamChunkToolCall,
-							Call:  state,
-							Delta: payload.Delta.PartialJSON,
-						}
-					}
+				if payload.Delta.Type == "input_json_delta" {
+					emitter.toolDelta(key, payload.Delta.PartialJSON)
 				}
 			case "content_block_start":
 				if payload.ContentBlock.Type == "tool_use" {
-					toolStates[payload.Index] = &ToolCall{
-						ID:            payload.ContentBlock.ID,
-						Name:          payload.ContentBlock.Name,
-						ArgumentsJSON: toJSONString(payload.ContentBlock.Input),
+					// The start block carries an empty input object; the real
+					// arguments arrive as input_json_delta fragments.
+					emitter.toolStart(key, payload.ContentBlock.ID, payload.ContentBlock.Name)
+					if len(payload.ContentBlock.Input) > 0 {
+						emitter.toolDelta(key, toJSONString(payload.ContentBlock.Input))
 					}
 				}
 			case "content_block_stop":
-				if state, ok := toolStates[payload.Index]; ok {
-					ch <- StreamChunk{
-						Type: StreamChunkToolCall,
-						Call: state,
-					}
+				emitter.toolEnd(key)
+			case "message_delta":
+				if payload.Delta.StopReason != "" {
+					finishReason = payload.Delta.StopReason
 				}
 			case "message_stop":
-				finishReason = payload.Message.StopReason
+				if payload.Message.StopReason != "" {
+					finishReason = payload.Message.StopReason
+				}
+				stopped = true
+			case "error":
+				chunkErr := &ChunkError{Kind: string(ErrorUnknown), Message: "anthropic streaming error"}
+				if payload.Error != nil {
+					chunkErr.Kind = string(anthropicStreamErrorKind(payload.Error.Type))
+					chunkErr.UpstreamCode = payload.Error.Type
+					if payload.Error.Message != "" {
+						chunkErr.Message = payload.Error.Message
+					}
+				}
+				emitter.abort(chunkErr)
 			}
 		}
-		ch <- StreamChunk{
-			Type:         StreamChunkMessageEnd,
-			FinishReason: finishReason,
-			Usage:        usage,
+		if readErr != nil || !stopped {
+			emitter.incomplete(readErr)
+			return
 		}
+		emitter.end(finishReason, usage)
 	}()
 	return ch, nil
 }
 
+func anthropicStreamErrorKind(errorType string) ErrorKind {
+	switch errorType {
+	case "invalid_request_error":
+		return ErrorValidation
+	case "authentication_error", "permission_error":
+		return ErrorProviderAuth
+	case "not_found_error":
+		return ErrorProviderNotFound
+	case "rate_limit_error":
+		return ErrorProviderRateLimit
+	case "api_error", "overloaded_error":
+		return ErrorProviderUnavailable
+	}
+	return ErrorUnknown
+}
+
 func (a *anthropicAdapter) buildPayload(in GenerateInput, stream bool) map[string]interface{} {
 	system, messages := splitAnthropicMessages(in.Messages)
 	payload := map[string]interface{}{
@@ -296,24 +363,32 @@ func convertAnthropicResponse(resp anthropicMessageResponse) GenerateOutput {
 func splitAnthropicMessages(messages []Message) (string, []map[string]interface{}) {
 	var systemParts []string
 	var result []map[string]interface{}
+	var toolResults []map[string]interface{}
 	for _, message := range messages {
 		if message.Role == "system" {
 			systemParts = append(systemParts, joinTextContent(message.Content))
 			continue
 		}
 		if message.Role == "tool" {
+			block := map[string]interface{}{
+				"type":        "tool_result",
+				"tool_use_id": message.ToolCallID,
+				"content":     joinTextContent(message.Content),
+			}
+			// Results for parallel tool calls must share a single user turn.
+			if toolResults != nil {
+				toolResults = append(toolResults, block)
+				result[len(result)-1]["content"] = toolResults
+				continue
+			}
+			toolResults = []map[string]interface{}{block}
 			result = append(result, map[string]interface{}{
-				"role": "user",
-				"content": []map[string]interface{}{
-					{
-						"type":        "tool_result",
-						"tool_use_id": message.ToolCallID,
-						"content":     joinTextContent(message.Content),
-					},
-				},
+				"role":    "user",
+				"content": toolResults,
 			})
 			continue
 		}
+		toolResults = nil
 		parts := make([]map[string]interface{}, 0, len(message.Content))
 		for _, part := range message.Content {
 			if part.Type == "text" {
@@ -339,6 +414,14 @@ func splitAnthropicMessages(messages []Message) (string, []map[string]interface{
 				parts = append(parts, entry)
 			}
 		}
+		for _, call := range message.ToolCalls {
+			parts = append(parts, map[string]interface{}{
+				"type":  "tool_use",
+				"id":    call.ID,
+				"name":  call.Name,
+				"input": parseToolArguments(call.ArgumentsJSON),
+			})
+		}
 		result = append(result, map[string]interface{}{
 			"role":    message.Role,
 			"content": parts,
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
@@ -17,20 +18,30 @@ type googleAdapter struct {
 
 type geminiModelList struct {
 	Models []struct {
-		Name             string `json:"name"`
-		DisplayName      string `json:"displayName"`
-		InputTokenLimit  int    `json:"inputTokenLimit"`
-		OutputTokenLimit int    `json:"outputTokenLimit"`
+		Name                       string   `json:"name"`
+		DisplayName                string   `json:"displayName"`
+		InputTokenLimit            int      `json:"inputTokenLimit"`
+		OutputTokenLimit           int      `json:"outputTokenLimit"`
+		SupportedGenerationMethods []string `json:"supportedGenerationMethods"`
+		Thinking                   *bool    `json:"thinking"`
 	} `json:"models"`
 }
 
 type geminiResponse struct {
-	Candidates []struct {
+	ResponseID   string `json:"responseId"`
+	ModelVersion string `json:"modelVersion"`
+	Candidates   []struct {
 		Content struct {
 			Parts []map[string]interface{} `json:"parts"`
 		} `json:"content"`
-		FinishReason string `json:"finishReason"`
+		FinishReason  string         `json:"finishReason"`
+		SafetyRatings []SafetyRating `json:"safetyRatings"`
 	} `json:"candidates"`
+	PromptFeedback *struct {
+		BlockReason        string         `json:"blockReason"`
+		BlockReasonMessage string         `json:"blockReasonMessage"`
+		SafetyRatings      []SafetyRating `json:"safetyRatings"`
+	} `json:"promptFeedback"`
 	UsageMetadata struct {
 		PromptTokenCount     int `json:"promptTokenCount"`
 		CandidatesTokenCount int `json:"candidatesTokenCount"`
@@ -63,16 +74,35 @@ func (g *googleAdapter) ListModels(ctx context.Context) ([]ModelMetadata, error)
 	for _, model := range payload.Models {
 		id := strings.TrimPrefix(model.Name, "models/")
 		models = append(models, ModelMetadata{
-			ID:          id,
-			DisplayName: model.DisplayName,
-			Provider:    ProviderGoogle,
-			Family:      deriveFamily(id),
-			ContextWindow: model.InputTokenLimit,
+			ID:              id,
+			DisplayName:     model.DisplayName,
+			Provider:        ProviderGoogle,
+			Family:          deriveFamily(id),
+			Capabilities:    geminiCapabilities(model.SupportedGenerationMethods, model.Thinking),
+			ContextWindow:   model.InputTokenLimit,
+			MaxOutputTokens: model.OutputTokenLimit,
 		})
 	}
 	return models, nil
 }
 
+// geminiCapabilities reads what supportedGenerationMethods says about a
+// model. An empty list says nothing, so everything stays unknown.
+func geminiCapabilities(methods []string, thinking *bool) ModelCapabilities {
+	caps := ModelCapabilities{Reasoning: thinking}
+	if len(methods) == 0 {
+		return caps
+	}
+	supported := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		supported[method] = true
+	}
+	caps.Text = boolPtr(supported["generateContent"])
+	caps.Streaming = boolPtr(supported["streamGenerateContent"])
+	caps.Batch = boolPtr(supported["batchGenerateContent"])
+	return caps
+}
+
 func (g *googleAdapter) Generate(ctx context.Context, in GenerateInput) (GenerateOutput, error) {
 	path := fmt.Sprintf("%s/v1beta/models/%s:generateContent?key=%s", g.baseURL, ensureModelsPrefix(in.Model), g.config.APIKey)
 	payload := g.buildPayload(in)
@@ -144,28 +174,64 @@ func (g *googleAdapter) Stream(ctx context.Context, in GenerateInput) (<-chan St
 	go func() {
 		defer close(ch)
 		defer resp.Body.Close()
+		emitter := newStreamEmitter(ctx, ch, ProviderGoogle, in.Model, resp)
 		events := streamSSE(ctx, resp.Body)
+		calls := 0
+		var readErr error
+		// finishReason, usage and safety ratings are reported on (at least)
+		// the last event; earlier events may carry partial values.
+		final := StreamChunk{}
+		finished := false
 		for event := range events {
+			if event.Err != nil {
+				readErr = event.Err
+				break
+			}
 			if event.Data == "" || event.Data == "[DONE]" {
 				continue
 			}
 			var payload geminiResponse
 			if err := json.Unmarshal([]byte(event.Data), &payload); err != nil {
+				emitter.decodeError(err)
+				continue
+			}
+			emitter.start(payload.ModelVersion, payload.ResponseID)
+			if feedback := payload.PromptFeedback; feedback != nil && feedback.BlockReason != "" {
+				message := feedback.BlockReasonMessage
+				if message == "" {
+					message = fmt.Sprintf("Gemini blocked the prompt (%s)", feedback.BlockReason)
+				}
+				emitter.abort(&ChunkError{
+					Kind:         string(ErrorValidation),
+					Message:      message,
+					UpstreamCode: feedback.BlockReason,
+				})
 				continue
 			}
 			output := convertGeminiResponse(payload)
-			if output.Text != "" {
-				ch <- StreamChunk{Type: StreamChunkDelta, TextDelta: output.Text}
+			if output.FinishReason != "" {
+				final.FinishReason = output.FinishReason
+				finished = true
+			}
+			if output.Usage != nil && output.Usage.TotalTokens > 0 {
+				final.Usage = output.Usage
+			}
+			if len(output.SafetyRatings) > 0 {
+				final.SafetyRatings = output.SafetyRatings
 			}
+			emitter.text(output.Text)
+			// Gemini sends each function call whole and reuses the function
+			// name as its ID, so every call gets its own key.
 			for _, call := range output.ToolCalls {
-				callCopy := call
-				ch <- StreamChunk{Type: StreamChunkToolCall, Call: &callCopy}
+				emitter.toolCall(strconv.Itoa(calls), call)
+				calls++
 			}
 		}
-		ch <- StreamChunk{
-			Type:         StreamChunkMessageEnd,
-			FinishReason: "stop",
+		if readErr != nil || !finished {
+			emitter.incomplete(readErr)
+			return
 		}
+		emitter.finish(final)
 	}()
 	return ch, nil
 }
@@ -184,7 +250,7 @@ func (g *googleAdapter) buildPayload(in GenerateInput) map[string]interface{} {
 	}
 	if in.ResponseFormat != nil && in.ResponseFormat.Type == "json_schema" && in.ResponseFormat.JsonSchema != nil {
 		config["responseMimeType"] = "application/json"
-		config["responseSchema"] = in.ResponseFormat.JsonSchema.Schema
+		config["responseSchema"] = geminiResponseSchema(in.ResponseFormat.JsonSchema.Schema)
 	}
 	payload := map[string]interface{}{
 		"contents":          contents,
@@ -196,6 +262,97 @@ func (g *googleAdapter) buildPayload(in GenerateInput) map[string]interface{} {
 	return payload
 }
 
+// geminiResponseSchema rewrites a JSON schema into the OpenAPI subset that
+// responseSchema accepts: null in a type array or anyOf becomes
+// nullable:true, and additionalProperties is dropped.
+func geminiResponseSchema(schema map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(schema))
+	for key, value := range schema {
+		switch key {
+		case "additionalProperties":
+		case "type":
+			kind, nullable := geminiSchemaType(value)
+			out["type"] = kind
+			if nullable {
+				out["nullable"] = true
+			}
+		case "enum":
+			values, _ := value.([]interface{})
+			enum := make([]interface{}, 0, len(values))
+			for _, item := range values {
+				if item != nil {
+					enum = append(enum, item)
+				}
+			}
+			out["enum"] = enum
+		case "properties":
+			properties, _ := value.(map[string]interface{})
+			converted := make(map[string]interface{}, len(properties))
+			for name, property := range properties {
+				if propertySchema, ok := property.(map[string]interface{}); ok {
+					converted[name] = geminiResponseSchema(propertySchema)
+				}
+			}
+			out["properties"] = converted
+		case "items":
+			if items, ok := value.(map[string]interface{}); ok {
+				out["items"] = geminiResponseSchema(items)
+			}
+		case "anyOf":
+			options, _ := value.([]interface{})
+			var kept []interface{}
+			nullable := false
+			for _, option := range options {
+				optionSchema, _ := option.(map[string]interface{})
+				if optionSchema["type"] == "null" {
+					nullable = true
+					continue
+				}
+				kept = append(kept, geminiResponseSchema(optionSchema))
+			}
+			if len(kept) == 1 {
+				for k, v := range kept[0].(map[string]interface{}) {
+					out[k] = v
+				}
+			} else {
+				out["anyOf"] = kept
+			}
+			if nullable {
+				out["nullable"] = true
+			}
+		default:
+			out[key] = value
+		}
+	}
+	return out
+}
+
+func geminiSchemaType(value interface{}) (interface{}, bool) {
+	var kinds []string
+	switch typed := value.(type) {
+	case []string:
+		kinds = typed
+	case []interface{}:
+		for _, kind := range typed {
+			if name, ok := kind.(string); ok {
+				kinds = append(kinds, name)
+			}
+		}
+	default:
+		return value, false
+	}
+	nullable := false
+	var kind interface{}
+	for _, name := range kinds {
+		if name == "null" {
+			nullable = true
+		} else if kind == nil {
+			kind = name
+		}
+	}
+	return kind, nullable
+}
+
 func buildGeminiImagePayload(in ImageGenerateInput) map[string]interface{} {
 	parts := []map[string]interface{}{
 		{"text": in.Prompt},
@@ -233,25 +390,32 @@ func buildGeminiImagePayload(in ImageGenerateInput) map[string]interface{} {
 func buildGeminiMessages(messages []Message) (map[string]interface{}, []map[string]interface{}) {
 	var systemParts []string
 	var contents []map[string]interface{}
+	var responseParts []map[string]interface{}
 	for _, message := range messages {
 		if message.Role == "system" {
 			systemParts = append(systemParts, joinTextContent(message.Content))
 			continue
 		}
 		if message.Role == "tool" {
-			contents = append(contents, map[string]interface{}{
-				"role": "user",
-				"parts": []map[string]interface{}{
-					{
-						"functionResponse": map[string]interface{}{
-							"name":     message.Name,
-							"response": joinTextContent(message.Content),
-						},
-					},
+			part := map[string]interface{}{
+				"functionResponse": map[string]interface{}{
+					"name":     message.Name,
+					"response": geminiFunctionResponse(joinTextContent(message.Content)),
 				},
+			}
+			if responseParts != nil {
+				responseParts = append(responseParts, part)
+				contents[len(contents)-1]["parts"] = responseParts
+				continue
+			}
+			responseParts = []map[string]interface{}{part}
+			contents = append(contents, map[string]interface{}{
+				"role":  "user",
+				"parts": responseParts,
 			})
 			continue
 		}
+		responseParts = nil
 		parts := make([]map[string]interface{}, 0, len(message.Content))
 		for _, part := range message.Content {
 			if part.Type == "text" {
@@ -273,6 +437,14 @@ func buildGeminiMessages(messages []Message) (map[string]interface{}, []map[stri
 				}
 			}
 		}
+		for _, call := range message.ToolCalls {
+			parts = append(parts, map[string]interface{}{
+				"functionCall": map[string]interface{}{
+					"name": call.Name,
+					"args": parseToolArguments(call.ArgumentsJSON),
+				},
+			})
+		}
 		role := "user"
 		if message.Role == "assistant" {
 			role = "model"
@@ -294,6 +466,16 @@ func buildGeminiMessages(messages []Message) (map[string]interface{}, []map[stri
 	return system, contents
 }
 
+// Gemini expects functionResponse.response to be an object, so plain-text
+// tool results are wrapped.
+func geminiFunctionResponse(text string) interface{} {
+	var parsed map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &parsed); err == nil && parsed != nil {
+		return parsed
+	}
+	return map[string]interface{}{"content": text}
+}
+
 func buildGeminiTools(tools []ToolDefinition) interface{} {
 	if len(tools) == 0 {
 		return nil
@@ -339,8 +521,10 @@ func convertGeminiResponse(resp geminiResponse) GenerateOutput {
 	text := ""
 	var calls []ToolCall
 	var finish string
+	var ratings []SafetyRating
 	if len(resp.Candidates) > 0 {
 		finish = resp.Candidates[0].FinishReason
+		ratings = resp.Candidates[0].SafetyRatings
 		for _, part := range resp.Candidates[0].Content.Parts {
 			if value, ok := part["text"].(string); ok {
 				text += value
@@ -361,11 +545,12 @@ func convertGeminiResponse(resp geminiResponse) GenerateOutput {
 	usage.OutputTokens = resp.UsageMetadata.CandidatesTokenCount
 	usage.TotalTokens = resp.UsageMetadata.TotalTokenCount
 	return GenerateOutput{
-		Text:         text,
-		ToolCalls:    calls,
-		Usage:        usage,
-		FinishReason: finish,
-		Raw:          resp,
+		Text:          text,
+		ToolCalls:     calls,
+		Usage:         usage,
+		FinishReason:  finish,
+		SafetyRatings: ratings,
+		Raw:           resp,
 	}
 }
 
@@ -12,6 +12,7 @@ import (
 	"net/textproto"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -67,6 +68,9 @@ type openAIChatResponse struct {
 }
 
 type openAIChatChunk struct {
+	ID      string                 `json:"id"`
+	Model   string                 `json:"model"`
+	Error   *openAIStreamErrorBody `json:"error"`
 	Choices []struct {
 		FinishReason string `json:"finish_reason"`
 		Delta        struct {
@@ -89,6 +93,8 @@ type openAIChatChunk struct {
 }
 
 type openAIResponsesResponse struct {
+	ID     string `json:"id"`
+	Model  string `json:"model"`
 	Status string `json:"status"`
 	Output []struct {
 		Content []struct {
@@ -107,6 +113,12 @@ type openAIResponsesResponse struct {
 	RequiredAction *openAIResponsesRequiredAction `json:"required_action"`
 }
 
+type openAIStreamErrorBody struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Code    string `json:"code"`
+}
+
 type openAIResponsesStreamPayload struct {
 	Delta struct {
 		Text      string `json:"text"`
@@ -155,12 +167,6 @@ func (p *openAIResponsesStreamPayload) ErrorCode() string {
 	return p.Error.Code
 }
 
-type toolState struct {
-	ID            string
-	Name          string
-	ArgumentsJSON string
-}
-
 func newOpenAIAdapter(cfg *OpenAIConfig, client *http.Client, provider Provider) ProviderAdapter {
 	base := cfg.BaseURL
 	if base == "" {
@@ -399,7 +405,7 @@ func (a *openAIAdapter) Stream(ctx context.Context, in GenerateInput) (<-chan St
 		if err != nil {
 			return nil, err
 		}
-		return a.streamResponses(ctx, resp), nil
+		return a.streamResponses(ctx, in, resp), nil
 	}
 	body := a.buildChatPayload(in, true)
 	req, err := a.jsonRequest(ctx, http.MethodPost, "/v1/chat/completions", body)
@@ -410,22 +416,46 @@ func (a *openAIAdapter) Stream(ctx context.Context, in GenerateInput) (<-chan St
 	if err != nil {
 		return nil, err
 	}
+	return a.streamChat(ctx, in, resp), nil
+}
+
+func (a *openAIAdapter) streamChat(ctx context.Context, in GenerateInput, resp *http.Response) <-chan StreamChunk {
 	ch := make(chan StreamChunk)
 	go func() {
 		defer close(ch)
 		defer resp.Body.Close()
+		emitter := newStreamEmitter(ctx, ch, a.provider, in.Model, resp)
 		events := streamSSE(ctx, resp.Body)
-		toolStates := map[int]*ToolCall{}
 		var finishReason string
 		var usage *Usage
+		var readErr error
+		done := false
 		for event := range events {
-			if event.Data == "" || event.Data == "[DONE]" {
+			if event.Err != nil {
+				readErr = event.Err
+				break
+			}
+			if event.Data == "[DONE]" {
+				done = true
+				continue
+			}
+			if event.Data == "" {
 				continue
 			}
 			var chunk openAIChatChunk
 			if err := json.Unmarshal([]byte(event.Data), &chunk); err != nil {
+				emitter.decodeError(err)
+				continue
+			}
+			if chunk.Error != nil {
+				emitter.abort(&ChunkError{
+					Kind:         string(openAIStreamErrorKind(chunk.Error.Type, chunk.Error.Code)),
+					Message:      chunk.Error.Message,
+					UpstreamCode: chunk.Error.Code,
+				})
 				continue
 			}
+			emitter.start(chunk.Model, chunk.ID)
 			if chunk.Usage != nil {
 				usage = &Usage{
 					InputTokens:  chunk.Usage.PromptTokens,
@@ -440,46 +470,45 @@ func (a *openAIAdapter) Stream(ctx context.Context, in GenerateInput) (<-chan St
 				if choice.Delta.Content != nil {
 					switch val := choice.Delta.Content.(type) {
 					case string:
-						if val != "" {
-							ch <- StreamChunk{Type: StreamChunkDelta, TextDelta: val}
-						}
+						emitter.text(val)
 					case []interface{}:
 						for _, item := range val {
 							if part, ok := item.(map[string]interface{}); ok {
-								if text, ok := part["text"].(string); ok && text != "" {
-									ch <- StreamChunk{Type: StreamChunkDelta, TextDelta: text}
+								if text, ok := part["text"].(string); ok {
+									emitter.text(text)
 								}
 							}
 						}
 					}
 				}
 				for _, tool := range choice.Delta.ToolCalls {
-					state := toolStates[tool.Index]
-					if state == nil {
-						state = &ToolCall{
-							ID:   tool.ID,
-							Name: tool.Function.Name,
-						}
-						toolStates[tool.Index] = state
-					}
-					state.ArgumentsJSON += tool.Function.Arguments
-					ch <- StreamChunk{
-						Type:  StreamChunkToolCall,
-						Call:  state,
-						Delta: tool.Function.Arguments,
-					}
+					key := strconv.Itoa(tool.Index)
+					emitter.toolStart(key, tool.ID, tool.Function.Name)
+					emitter.toolDelta(key, tool.Function.Arguments)
 				}
 			}
 		}
-		if finishReason != "" {
-			ch <- StreamChunk{
-				Type:         StreamChunkMessageEnd,
-				FinishReason: finishReason,
-				Usage:        usage,
-			}
+		if readErr != nil || (finishReason == "" && !done) {
+			emitter.incomplete(readErr)
+			return
 		}
+		emitter.end(finishReason, usage)
 	}()
-	return ch, nil
+	return ch
+}
+
+func openAIStreamErrorKind(errorType, code string) ErrorKind {
+	switch {
+	case errorType == "invalid_request_error":
+		return ErrorValidation
+	case errorType == "authentication_error" || code == "invalid_api_key":
+		return ErrorProviderAuth
+	case errorType == "rate_limit_error" || code == "rate_limit_exceeded":
+		return ErrorProviderRateLimit
+	case errorType == "server_error" || errorType == "service_unavailable":
+		return ErrorProviderUnavailable
+	}
+	return ErrorUnknown
 }
 
 func (a *openAIAdapter) jsonRequest(ctx context.Context, method, path string, payload map[string]interface{}) (*http.Request, error) {
@@ -639,50 +668,39 @@ func (a *openAIAdapter) shouldUseResponses(in GenerateInput) bool {
 	return a.config.DefaultUseResponses
 }
 
-func (a *openAIAdapter) streamResponses(ctx context.Context, resp *http.Response) <-chan StreamChunk {
+func (a *openAIAdapter) streamResponses(ctx context.Context, in GenerateInput, resp *http.Response) <-chan StreamChunk {
 	ch := make(chan StreamChunk)
 	go func() {
 		defer close(ch)
 		defer resp.Body.Close()
+		emitter := newStreamEmitter(ctx, ch, a.provider, in.Model, resp)
 		events := streamSSE(ctx, resp.Body)
-		toolStates := map[string]*toolState{}
+		var readErr error
 		for event := range events {
+			if event.Err != nil {
+				readErr = event.Err
+				break
+			}
 			if event.Data == "" || event.Data == "[DONE]" {
 				continue
 			}
 			var payload openAIResponsesStreamPayload
 			if err := json.Unmarshal([]byte(event.Data), &payload); err != nil {
+				emitter.decodeError(err)
 				continue
 			}
+			if payload.Response != nil {
+				emitter.start(payload.Response.Model, payload.Response.ID)
+			}
 			switch event.Event {
 			case "response.output_text.delta", "response.refusal.delta":
-				if payload.Delta.Text != "" {
-					ch <- StreamChunk{Type: StreamChunkDelta, TextDelta: payload.Delta.Text}
-				}
+				emitter.text(payload.Delta.Text)
 			case "response.tool_call.delta", "response.output_tool_call.delta":
 				if payload.ToolCallID == "" {
 					continue
 				}
-				state := toolStates[payload.ToolCallID]
-				if state == nil {
-					state = &toolState{ID: payload.ToolCallID}
-					toolStates[payload.ToolCallID] = state
-				}
-				if payload.Delta.Name != "" {
-					state.Name = payload.Delta.Name
-				}
-				if payload.Delta.Arguments != "" {
-					state.ArgumentsJSON += payload.Delta.Arguments
-				}
-				ch <- StreamChunk{
-					Type: StreamChunkToolCall,
-					Call: &ToolCall{
-						ID:            state.ID,
-						Name:          state.Name,
-						ArgumentsJSON: state.ArgumentsJSON,
-					},
-					Delta: payload.Delta.Arguments,
-				}
+				emitter.toolStart(payload.ToolCallID, payload.ToolCallID, payload.Delta.Name)
+				emitter.toolDelta(payload.ToolCallID, payload.Delta.Arguments)
 			case "response.required_action":
 				action := payload.RequiredAction
 				if action == nil && payload.Response != nil {
@@ -690,25 +708,8 @@ func (a *openAIAdapter) streamResponses(ctx context.Context, resp *http.Response
 				}
 				if action != nil && action.SubmitToolOutputs != nil {
 					for _, call := range action.SubmitToolOutputs.ToolCalls {
-						state := toolStates[call.ID]
-						if state == nil {
-							state = &toolState{ID: call.ID, Name: call.Function.Name}
-							toolStates[call.ID] = state
-						}
-						if call.Function.Name != "" {
-							state.Name = call.Function.Name
-						}
-						if call.Function.Arguments != "" {
-							state.ArgumentsJSON = call.Function.Arguments
-						}
-						ch <- StreamChunk{
-							Type: StreamChunkToolCall,
-							Call: &ToolCall{
-								ID:            state.ID,
-								Name:          state.Name,
-								ArgumentsJSON: state.ArgumentsJSON,
-							},
-						}
+						emitter.toolArguments(call.ID, call.ID, call.Function.Name, call.Function.Arguments)
+						emitter.toolEnd(call.ID)
 					}
 				}
 			case "response.completed":
@@ -718,11 +719,7 @@ func (a *openAIAdapter) streamResponses(ctx context.Context, resp *http.Response
 					usage = mapResponsesUsage(payload.Response.Usage)
 					status = payload.Response.Status
 				}
-				ch <- StreamChunk{
-					Type:         StreamChunkMessageEnd,
-					FinishReason: status,
-					Usage:        usage,
-				}
+				emitter.end(status, usage)
 			case "response.failed", "response.canceled":
 				var status string
 				if payload.Response != nil && payload.Response.Status != "" {
@@ -730,33 +727,29 @@ func (a *openAIAdapter) streamResponses(ctx context.Context, resp *http.Response
 				} else {
 					status = strings.TrimPrefix(event.Event, "response.")
 				}
-				ch <- StreamChunk{
-					Type:         StreamChunkMessageEnd,
-					FinishReason: status,
-				}
+				emitter.end(status, nil)
 			case "response.error":
 				message := "openai streaming error"
-				if payload.Error != nil && payload.Error.Message != "" {
-					message = payload.Error.Message
-				}
-				ch <- StreamChunk{
-					Type: StreamChunkError,
-					Error: &ChunkError{
-						Kind:         "upstream_error",
-						Message:      message,
-						UpstreamCode: payload.ErrorCode(),
-					},
+				kind := ErrorUnknown
+				if payload.Error != nil {
+					if payload.Error.Message != "" {
+						message = payload.Error.Message
+					}
+					kind = openAIStreamErrorKind(payload.Error.Type, payload.Error.Code)
 				}
+				emitter.abort(&ChunkError{
+					Kind:         string(kind),
+					Message:      message,
+					UpstreamCode: payload.ErrorCode(),
+				})
 			case "response.output_audio.delta":
-				ch <- StreamChunk{
-					Type: StreamChunkError,
-					Error: &ChunkError{
-						Kind:    "unsupported",
-						Message: "audio streaming is not supported by this adapter",
-					},
-				}
+				emitter.fail(&ChunkError{
+					Kind:    "unsupported",
+					Message: "audio streaming is not supported by this adapter",
+				})
 			}
 		}
+		emitter.incomplete(readErr)
 	}()
 	return ch
 }
@@ -905,9 +898,31 @@ func mapMessagesToChat(messages []Message) []map[string]interface{} {
 				content = text["text"]
 			}
 		}
-		result = append(result, map[string]interface{}{
+		entry := map[string]interface{}{
 			"role":    message.Role,
 			"content": content,
+		}
+		if len(message.ToolCalls) > 0 {
+			if len(parts) == 0 {
+				entry["content"] = nil
+			}
+			entry["tool_calls"] = mapToolCallsToChat(message.ToolCalls)
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
+func mapToolCallsToChat(calls []ToolCall) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(calls))
+	for _, call := range calls {
+		result = append(result, map[string]interface{}{
+			"id":   call.ID,
+			"type": "function",
+			"function": map[string]string{
+				"name":      call.Name,
+				"arguments": call.ArgumentsJSON,
+			},
 		})
 	}
 	return result
@@ -916,6 +931,14 @@ func mapMessagesToChat(messages []Message) []map[string]interface{} {
 func mapMessagesToResponses(messages []Message) []map[string]interface{} {
 	result := make([]map[string]interface{}, 0, len(messages))
 	for _, message := range messages {
+		if message.Role == "tool" && message.ToolCallID != "" {
+			result = append(result, map[string]interface{}{
+				"type":    "function_call_output",
+				"call_id": message.ToolCallID,
+				"output":  joinTextContent(message.Content),
+			})
+			continue
+		}
 		content := make([]map[string]interface{}, 0, len(message.Content))
 		for _, part := range message.Content {
 			if part.Type == "text" {
@@ -949,7 +972,17 @@ func mapMessagesToResponses(messages []Message) []map[string]interface{} {
 		if message.Name != "" {
 			entry["name"] = message.Name
 		}
-		result = append(result, entry)
+		if len(content) > 0 || len(message.ToolCalls) == 0 {
+			result = append(result, entry)
+		}
+		for _, call := range message.ToolCalls {
+			result = append(result, map[string]interface{}{
+				"type":      "function_call",
+				"call_id":   call.ID,
+				"name":      call.Name,
+				"arguments": call.ArgumentsJSON,
+			})
+		}
 	}
 	return result
 }
@@ -989,6 +1022,9 @@ func mapToolChoiceToOpenAI(choice *ToolChoice) interface{} {
 	if choice.Type == "auto" || choice.Type == "none" {
 		return choice.Type
 	}
+	if choice.Type == "any" {
+		return "required"
+	}
 	return map[string]interface{}{
 		"type": "function",
 		"function": map[string]string{
@@ -3,6 +3,7 @@ package aikit
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -72,3 +73,14 @@ func fetchURLAsBase64(ctx context.Context, client *http.Client, rawURL string) (
 func isHTTPURL(raw string) bool {
 	return strings.HasPrefix(raw, "http://") || strings.HasPrefix(raw, "https://")
 }
+
+func parseToolArguments(raw string) map[string]interface{} {
+	args := map[string]interface{}{}
+	if strings.TrimSpace(raw) == "" {
+		return args
+	}
+	if err := json.Unmarshal([]byte(raw), &args); err != nil || args == nil {
+		return map[string]interface{}{}
+	}
+	return args
+}
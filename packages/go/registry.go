@@ -2,79 +2,144 @@ package aikit
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 )
 
-type registryEntry struct {
-	expires   time.Time
-	fetchedAt time.Time
-	data      []ModelMetadata
-}
-
 type learnedEntry struct {
 	expires time.Time
 	reason  string
 }
 
-type registryKey struct {
-	Provider    Provider
-	Fingerprint string
-	AccountID   string
-	Region      string
-	Environment string
-	TenantID    string
-	UserID      string
+// verifiedEntry is the outcome of the last conclusive probe of a model.
+type verifiedEntry struct {
+	at       time.Time
+	entitled bool
+	reason   string
 }
 
 type learnedKey struct {
-	registryKey
+	RegistryKey
 	ModelID string
 }
 
+// backgroundRefreshTimeout bounds listing fetches, which run detached from
+// the request that started them.
+const backgroundRefreshTimeout = time.Minute
+
 type modelRegistry struct {
-	adapters    map[Provider]ProviderAdapter
-	factory     AdapterFactory
-	ttl         time.Duration
-	learnedTTL  time.Duration
-	cache       map[registryKey]registryEntry
-	learned     map[learnedKey]learnedEntry
-	mu          sync.RWMutex
+	store      RegistryStore
+	catalog    *catalog
+	flights    flightGroup
+	adapters   map[Provider]ProviderAdapter
+	factory    AdapterFactory
+	ttl        time.Duration
+	staleTTL   time.Duration
+	learnedTTL time.Duration
+	learned    map[learnedKey]learnedEntry
+	verified   map[learnedKey]verifiedEntry
+	generation uint64
+	mu         sync.RWMutex
+
+	// subMu guards change subscribers and the snapshots they are diffed
+	// against: each key's last fetch, as listed and with the catalog applied.
+	subMu          sync.Mutex
+	subscribers    map[int]func(ModelChangeEvent)
+	nextSubscriber int
+	snapshots      map[RegistryKey]listingSnapshot
 }
 
-func newModelRegistry(adapters map[Provider]ProviderAdapter, ttl time.Duration, factory AdapterFactory) *modelRegistry {
+func newModelRegistry(store RegistryStore, catalog *catalog, state *kitState) *modelRegistry {
+	if store == nil {
+		store = NewMemoryRegistryStore()
+	}
 	return &modelRegistry{
-		adapters:   adapters,
-		factory:    factory,
-		ttl:       ttl,
+		store:      store,
+		catalog:    catalog,
+		adapters:   state.adapters,
+		factory:    state.factory,
+		ttl:        state.ttl,
+		staleTTL:   state.staleTTL,
 		learnedTTL: 20 * time.Minute,
-		cache:      make(map[registryKey]registryEntry),
 		learned:    make(map[learnedKey]learnedEntry),
+		verified:   make(map[learnedKey]verifiedEntry),
+	}
+}
+
+// reconfigure points the registry at a reloaded Kit's adapters. Fetches that
+// started before the swap still return, but no longer populate the store.
+func (r *modelRegistry) reconfigure(state *kitState) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.adapters = state.adapters
+	r.ttl = state.ttl
+	r.staleTTL = state.staleTTL
+	r.factory = state.factory
+	r.generation++
+}
+
+// invalidate drops stored listings and learned and verified availability
+// whose key matches, returning the number of entries removed.
+func (r *modelRegistry) invalidate(match func(RegistryKey) bool) int {
+	ctx := context.Background()
+	removed := 0
+	keys, _ := r.store.Keys(ctx)
+	for _, key := range keys {
+		if match(key) && r.store.Delete(ctx, key) == nil {
+			removed++
+		}
+	}
+	r.subMu.Lock()
+	for key := range r.snapshots {
+		if match(key) {
+			delete(r.snapshots, key)
+		}
+	}
+	r.subMu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key := range r.learned {
+		if match(key.RegistryKey) {
+			delete(r.learned, key)
+			removed++
+		}
+	}
+	for key := range r.verified {
+		if match(key.RegistryKey) {
+			delete(r.verified, key)
+			removed++
+		}
 	}
+	return removed
 }
 
-func (r *modelRegistry) List(ctx context.Context, opts *ListModelsOptions) ([]ModelMetadata, error) {
-	entries, err := r.entriesForProviders(ctx, opts)
+func (r *modelRegistry) List(ctx context.Context, opts *ListModelsOptions) (ListModelsResult, error) {
+	entries, failures, err := r.entriesForProviders(ctx, opts)
 	if err != nil {
-		return nil, err
+		return ListModelsResult{}, err
 	}
-	results := make([]ModelMetadata, 0)
+	result := ListModelsResult{Models: make([]ModelMetadata, 0), Errors: failures}
 	for _, entry := range entries {
-		results = append(results, entry.data...)
+		for _, model := range entry.Models {
+			result.Models = append(result.Models, r.catalog.apply(model))
+		}
 	}
-	sort.Slice(results, func(i, j int) bool {
-		if results[i].Provider == results[j].Provider {
-			return results[i].DisplayName < results[j].DisplayName
+	sort.Slice(result.Models, func(i, j int) bool {
+		models := result.Models
+		if models[i].Provider == models[j].Provider {
+			return models[i].DisplayName < models[j].DisplayName
 		}
-		return results[i].Provider < results[j].Provider
+		return models[i].Provider < models[j].Provider
 	})
-	return results, nil
+	return result, nil
 }
 
 func (r *modelRegistry) ListRecords(ctx context.Context, opts *ListModelsOptions) ([]ModelRecord, error) {
-	entries, err := r.entriesForProviders(ctx, opts)
+	entries, _, err := r.entriesForProviders(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -84,8 +149,8 @@ func (r *modelRegistry) ListRecords(ctx context.Context, opts *ListModelsOptions
 	}
 	results := make([]ModelRecord, 0)
 	for provider, entry := range entries {
-		for _, model := range entry.data {
-			results = append(results, r.modelRecordFromMetadata(model, provider, entry.fetchedAt, entitlement))
+		for _, model := range entry.Models {
+			results = append(results, r.modelRecordFromMetadata(r.catalog.apply(model), provider, entry.FetchedAt, entitlement))
 		}
 	}
 	sort.Slice(results, func(i, j int) bool {
@@ -111,17 +176,82 @@ func (r *modelRegistry) LearnModelUnavailable(entitlement *EntitlementContext, p
 	r.mu.Unlock()
 }
 
-func (r *modelRegistry) entriesForProviders(ctx context.Context, opts *ListModelsOptions) (map[Provider]registryEntry, error) {
+// recordVerification keeps a probe's outcome until the next conclusive
+// probe. A successful probe is newer evidence than anything learned from
+// earlier failures, so it clears those.
+func (r *modelRegistry) recordVerification(key learnedKey, entry verifiedEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.verified[key] = entry
+	if entry.entitled {
+		delete(r.learned, key)
+	}
+}
+
+// entriesForProviders lists every resolved provider concurrently. A failed
+// provider fails the whole listing unless opts.Partial is set, in which case
+// it is reported alongside the providers that answered.
+func (r *modelRegistry) entriesForProviders(ctx context.Context, opts *ListModelsOptions) (map[Provider]RegistryEntry, []ProviderListError, error) {
 	providers := r.resolveProviders(opts)
-	results := make(map[Provider]registryEntry, len(providers))
-	for _, provider := range providers {
-		entry, err := r.modelsForProvider(ctx, provider, opts)
-		if err != nil {
-			return nil, err
+	var timeout time.Duration
+	if opts != nil {
+		timeout = opts.ProviderTimeout
+	}
+	type listing struct {
+		entry RegistryEntry
+		err   error
+	}
+	listings := make([]listing, len(providers))
+	var wg sync.WaitGroup
+	for idx, provider := range providers {
+		wg.Add(1)
+		go func(idx int, provider Provider) {
+			defer wg.Done()
+			var providerCtx context.Context
+			var cancel context.CancelFunc
+			if timeout > 0 {
+				providerCtx, cancel = context.WithTimeout(ctx, timeout)
+			} else {
+				providerCtx, cancel = context.WithCancel(ctx)
+			}
+			defer cancel()
+			entry, err := r.modelsForProvider(providerCtx, provider, opts)
+			if err != nil && ctx.Err() == nil && errors.Is(providerCtx.Err(), context.DeadlineExceeded) {
+				err = &KitError{
+					Kind:     ErrorProviderUnavailable,
+					Message:  fmt.Sprintf("model listing timed out after %s", timeout),
+					Provider: provider,
+					Cause:    err,
+				}
+			}
+			listings[idx] = listing{entry: entry, err: err}
+		}(idx, provider)
+	}
+	wg.Wait()
+	entries := make(map[Provider]RegistryEntry, len(providers))
+	var failures []ProviderListError
+	for idx, provider := range providers {
+		if err := listings[idx].err; err != nil {
+			if opts == nil || !opts.Partial {
+				return nil, nil, err
+			}
+			failures = append(failures, providerListError(provider, err))
+			continue
 		}
-		results[provider] = entry
+		entries[provider] = listings[idx].entry
 	}
-	return results, nil
+	return entries, failures, nil
+}
+
+func providerListError(provider Provider, err error) ProviderListError {
+	failure := ProviderListError{Provider: provider, Kind: string(ErrorUnknown), Message: err.Error()}
+	var kitErr *KitError
+	if errors.As(err, &kitErr) {
+		failure.Kind = string(kitErr.Kind)
+		failure.Message = kitErr.Message
+		failure.UpstreamCode = kitErr.UpstreamCode
+	}
+	return failure
 }
 
 func (r *modelRegistry) resolveProviders(opts *ListModelsOptions) []Provider {
@@ -131,6 +261,8 @@ func (r *modelRegistry) resolveProviders(opts *ListModelsOptions) []Provider {
 	if opts != nil && opts.Entitlement != nil && opts.Entitlement.Provider != "" {
 		return []Provider{opts.Entitlement.Provider}
 	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	providers := make([]Provider, 0, len(r.adapters))
 	for provider := range r.adapters {
 		providers = append(providers, provider)
@@ -138,7 +270,11 @@ func (r *modelRegistry) resolveProviders(opts *ListModelsOptions) []Provider {
 	return providers
 }
 
-func (r *modelRegistry) modelsForProvider(ctx context.Context, provider Provider, opts *ListModelsOptions) (registryEntry, error) {
+// modelsForProvider serves a fresh stored listing as is. A stale one is
+// served while a background refresh replaces it, as long as it expired less
+// than staleTTL ago; anything older, missing or explicitly refreshed is
+// fetched before returning. A failed fetch falls back to whatever is stored.
+func (r *modelRegistry) modelsForProvider(ctx context.Context, provider Provider, opts *ListModelsOptions) (RegistryEntry, error) {
 	refresh := opts != nil && opts.Refresh
 	var entitlement *EntitlementContext
 	if opts != nil {
@@ -146,59 +282,91 @@ func (r *modelRegistry) modelsForProvider(ctx context.Context, provider Provider
 	}
 	key := r.registryKey(provider, entitlement)
 	if !refresh {
-		if cached, ok := r.cached(key); ok {
-			return cached, nil
+		if stored, ok := r.stored(ctx, key); ok {
+			now := time.Now()
+			if now.Before(stored.ExpiresAt) {
+				return stored, nil
+			}
+			if r.servesStale(stored, now) {
+				go r.refreshInBackground(provider, entitlement, key)
+				return stored, nil
+			}
 		}
 	}
-	entry, err := r.fetchAndCache(ctx, provider, entitlement, key)
+	entry, err := r.refresh(ctx, provider, entitlement, key)
 	if err != nil {
-		if cached, ok := r.cached(key); ok {
-			return cached, nil
+		if stored, ok := r.stored(ctx, key); ok {
+			return stored, nil
 		}
-		return registryEntry{}, err
+		return RegistryEntry{}, err
 	}
 	return entry, nil
 }
 
-func (r *modelRegistry) cached(key registryKey) (registryEntry, bool) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	entry, ok := r.cache[key]
-	if !ok || time.Now().After(entry.expires) {
-		return registryEntry{}, false
+// stored reads key from the store; store errors count as a miss.
+func (r *modelRegistry) stored(ctx context.Context, key RegistryKey) (RegistryEntry, bool) {
+	entry, ok, err := r.store.Get(ctx, key)
+	if err != nil || !ok {
+		return RegistryEntry{}, false
 	}
 	return entry, true
 }
 
-func (r *modelRegistry) fetchAndCache(ctx context.Context, provider Provider, entitlement *EntitlementContext, key registryKey) (registryEntry, error) {
+func (r *modelRegistry) servesStale(entry RegistryEntry, now time.Time) bool {
+	r.mu.RLock()
+	staleTTL := r.staleTTL
+	r.mu.RUnlock()
+	return staleTTL > 0 && now.Before(entry.ExpiresAt.Add(staleTTL))
+}
+
+func (r *modelRegistry) refreshInBackground(provider Provider, entitlement *EntitlementContext, key RegistryKey) {
+	r.refresh(context.Background(), provider, entitlement, key)
+}
+
+// refresh fetches key once however many callers ask for it concurrently.
+func (r *modelRegistry) refresh(ctx context.Context, provider Provider, entitlement *EntitlementContext, key RegistryKey) (RegistryEntry, error) {
+	return r.flights.do(ctx, key, func(ctx context.Context) (RegistryEntry, error) {
+		return r.fetchAndStore(ctx, provider, entitlement, key)
+	})
+}
+
+func (r *modelRegistry) fetchAndStore(ctx context.Context, provider Provider, entitlement *EntitlementContext, key RegistryKey) (RegistryEntry, error) {
+	r.mu.RLock()
+	generation, ttl := r.generation, r.ttl
+	r.mu.RUnlock()
 	adapter, err := r.adapterFor(provider, entitlement)
 	if err != nil {
-		return registryEntry{}, err
+		return RegistryEntry{}, err
 	}
 	models, err := adapter.ListModels(ctx)
 	if err != nil {
-		return registryEntry{}, err
-	}
-	for idx, model := range models {
-		models[idx] = applyCuratedMetadata(model)
+		return RegistryEntry{}, err
 	}
 	now := time.Now()
-	entry := registryEntry{
-		data:      models,
-		expires:   now.Add(r.ttl),
-		fetchedAt: now,
+	entry := RegistryEntry{
+		Models:    models,
+		FetchedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	r.mu.RLock()
+	current := r.generation == generation
+	r.mu.RUnlock()
+	if current {
+		previous, hadPrevious := r.stored(ctx, key)
+		r.store.Put(ctx, key, entry)
+		r.publishChanges(key, previous, hadPrevious, entry)
 	}
-	r.mu.Lock()
-	r.cache[key] = entry
-	r.mu.Unlock()
 	return entry, nil
 }
 
 func (r *modelRegistry) adapterFor(provider Provider, entitlement *EntitlementContext) (ProviderAdapter, error) {
-	if r.factory != nil {
-		return r.factory(provider, entitlement)
+	r.mu.RLock()
+	adapters, factory := r.adapters, r.factory
+	r.mu.RUnlock()
+	if factory != nil {
+		return factory(provider, entitlement)
 	}
-	if adapter, ok := r.adapters[provider]; ok {
+	if adapter, ok := adapters[provider]; ok {
 		return adapter, nil
 	}
 	return nil, &KitError{
@@ -208,8 +376,8 @@ func (r *modelRegistry) adapterFor(provider Provider, entitlement *EntitlementCo
 	}
 }
 
-func (r *modelRegistry) registryKey(provider Provider, entitlement *EntitlementContext) registryKey {
-	key := registryKey{
+func (r *modelRegistry) registryKey(provider Provider, entitlement *EntitlementContext) RegistryKey {
+	key := RegistryKey{
 		Provider:    provider,
 		Fingerprint: "default",
 	}
@@ -233,7 +401,7 @@ func (r *modelRegistry) registryKey(provider Provider, entitlement *EntitlementC
 
 func (r *modelRegistry) learnedKey(provider Provider, entitlement *EntitlementContext, modelID string) learnedKey {
 	return learnedKey{
-		registryKey: r.registryKey(provider, entitlement),
+		RegistryKey: r.registryKey(provider, entitlement),
 		ModelID:     modelID,
 	}
 }
@@ -253,20 +421,33 @@ func (r *modelRegistry) learnedStatus(provider Provider, entitlement *Entitlemen
 	return entry, true
 }
 
+func (r *modelRegistry) verifiedStatus(provider Provider, entitlement *EntitlementContext, modelID string) (verifiedEntry, bool) {
+	key := r.learnedKey(provider, entitlement, modelID)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, ok := r.verified[key]
+	return entry, ok
+}
+
 func (r *modelRegistry) modelRecordFromMetadata(model ModelMetadata, provider Provider, verifiedAt time.Time, entitlement *EntitlementContext) ModelRecord {
+	caps := model.Capabilities.clone()
 	modalities := ModelModalities{
-		Text:   model.Capabilities.Text,
-		Vision: model.Capabilities.Vision,
+		Text:     caps.Text,
+		Vision:   caps.Vision,
+		AudioIn:  caps.AudioIn,
+		AudioOut: caps.AudioOut,
+		ImageOut: caps.Image,
 	}
 	features := ModelFeatures{
-		Tools:      model.Capabilities.ToolUse,
-		JSONMode:   model.Capabilities.StructuredOutput,
-		JSONSchema: model.Capabilities.StructuredOutput,
-		Streaming:  true,
+		Tools:      caps.ToolUse,
+		JSONMode:   caps.JSONMode,
+		JSONSchema: caps.StructuredOutput,
+		Streaming:  caps.Streaming,
+		Batch:      caps.Batch,
 	}
 	var limits *ModelLimits
-	if model.ContextWindow > 0 {
-		limits = &ModelLimits{ContextTokens: model.ContextWindow}
+	if model.ContextWindow > 0 || model.MaxOutputTokens > 0 {
+		limits = &ModelLimits{ContextTokens: model.ContextWindow, MaxOutputTokens: model.MaxOutputTokens}
 	}
 	var pricing *ModelPricing
 	if model.TokenPrices != nil {
@@ -291,6 +472,12 @@ func (r *modelRegistry) modelRecordFromMetadata(model ModelMetadata, provider Pr
 	if !verifiedAt.IsZero() {
 		availability.LastVerifiedAt = verifiedAt.UTC().Format(time.RFC3339)
 	}
+	if verified, ok := r.verifiedStatus(provider, entitlement, model.ID); ok {
+		availability.Entitled = verified.entitled
+		availability.Confidence = AvailabilityVerified
+		availability.Reason = verified.reason
+		availability.LastVerifiedAt = verified.at.UTC().Format(time.RFC3339)
+	}
 	if learned, ok := r.learnedStatus(provider, entitlement, model.ID); ok {
 		availability.Entitled = false
 		availability.Confidence = AvailabilityLearned
@@ -298,16 +485,16 @@ func (r *modelRegistry) modelRecordFromMetadata(model ModelMetadata, provider Pr
 	}
 	recordID := string(provider) + ":" + model.ID
 	return ModelRecord{
-		ID:             recordID,
-		Provider:       provider,
+		ID:              recordID,
+		Provider:        provider,
 		ProviderModelID: model.ID,
-		DisplayName:    model.DisplayName,
-		Modalities:     modalities,
-		Features:       features,
-		Limits:         limits,
-		Tags:           tags,
-		Pricing:        pricing,
-		Availability:   availability,
+		DisplayName:     model.DisplayName,
+		Modalities:      modalities,
+		Features:        features,
+		Limits:          limits,
+		Tags:            tags,
+		Pricing:         pricing,
+		Availability:    availability,
 	}
 }
 
@@ -0,0 +1,245 @@
+package aikit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// RegistryKey identifies one cached model listing: a provider seen through a
+// particular key and entitlement scope. Listings fetched with the Kit's own
+// configuration use the fingerprint "default".
+type RegistryKey struct {
+	Provider    Provider `json:"provider"`
+	Fingerprint string   `json:"fingerprint"`
+	AccountID   string   `json:"accountId,omitempty"`
+	Region      string   `json:"region,omitempty"`
+	Environment string   `json:"environment,omitempty"`
+	TenantID    string   `json:"tenantId,omitempty"`
+	UserID      string   `json:"userId,omitempty"`
+}
+
+// RegistryEntry is a cached model listing, as the provider reported it;
+// curated catalog metadata is applied when the listing is read. Entries past
+// ExpiresAt are stale: they may still be served while a background refresh
+// runs.
+type RegistryEntry struct {
+	Models    []ModelMetadata `json:"models"`
+	FetchedAt time.Time       `json:"fetchedAt"`
+	ExpiresAt time.Time       `json:"expiresAt"`
+}
+
+// RegistryStore holds the model registry's cached listings. Implementations
+// must be safe for concurrent use. Errors are treated as cache misses, so a
+// failing store slows the Kit down but never breaks it.
+type RegistryStore interface {
+	Get(ctx context.Context, key RegistryKey) (RegistryEntry, bool, error)
+	Put(ctx context.Context, key RegistryKey, entry RegistryEntry) error
+	Delete(ctx context.Context, key RegistryKey) error
+	Keys(ctx context.Context) ([]RegistryKey, error)
+}
+
+// MemoryRegistryStore keeps listings in process memory. It is the default.
+type MemoryRegistryStore struct {
+	mu      sync.RWMutex
+	entries map[RegistryKey]RegistryEntry
+}
+
+func NewMemoryRegistryStore() *MemoryRegistryStore {
+	return &MemoryRegistryStore{entries: make(map[RegistryKey]RegistryEntry)}
+}
+
+func (s *MemoryRegistryStore) Get(ctx context.Context, key RegistryKey) (RegistryEntry, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entry, ok := s.entries[key]
+	return entry, ok, nil
+}
+
+func (s *MemoryRegistryStore) Put(ctx context.Context, key RegistryKey, entry RegistryEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = entry
+	return nil
+}
+
+func (s *MemoryRegistryStore) Delete(ctx context.Context, key RegistryKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+func (s *MemoryRegistryStore) Keys(ctx context.Context) ([]RegistryKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make([]RegistryKey, 0, len(s.entries))
+	for key := range s.entries {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// FileRegistryStore keeps listings in memory and writes them through to a
+// JSON file, so a restarted process starts with a warm (if stale) registry.
+type FileRegistryStore struct {
+	path   string
+	memory *MemoryRegistryStore
+	mu     sync.Mutex
+}
+
+type registryFile struct {
+	Entries []registryFileEntry `json:"entries"`
+}
+
+type registryFileEntry struct {
+	Key RegistryKey `json:"key"`
+	RegistryEntry
+}
+
+// NewFileRegistryStore loads path when it exists; the file is created on the
+// first write.
+func NewFileRegistryStore(path string) (*FileRegistryStore, error) {
+	store := &FileRegistryStore{path: path, memory: NewMemoryRegistryStore()}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file registryFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("registry cache %s: %w", path, err)
+	}
+	for _, entry := range file.Entries {
+		store.memory.entries[entry.Key] = entry.RegistryEntry
+	}
+	return store, nil
+}
+
+func (s *FileRegistryStore) Get(ctx context.Context, key RegistryKey) (RegistryEntry, bool, error) {
+	return s.memory.Get(ctx, key)
+}
+
+func (s *FileRegistryStore) Put(ctx context.Context, key RegistryKey, entry RegistryEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.memory.Put(ctx, key, entry)
+	return s.flush()
+}
+
+func (s *FileRegistryStore) Delete(ctx context.Context, key RegistryKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.memory.Delete(ctx, key)
+	return s.flush()
+}
+
+func (s *FileRegistryStore) Keys(ctx context.Context) ([]RegistryKey, error) {
+	return s.memory.Keys(ctx)
+}
+
+// flush rewrites the whole file through a temporary file and a rename, so
+// readers never see a partial write.
+func (s *FileRegistryStore) flush() error {
+	var file registryFile
+	s.memory.mu.RLock()
+	for key, entry := range s.memory.entries {
+		file.Entries = append(file.Entries, registryFileEntry{Key: key, RegistryEntry: entry})
+	}
+	s.memory.mu.RUnlock()
+	data, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// flightGroup collapses concurrent fetches of the same registry key into one.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[RegistryKey]*flightCall
+}
+
+type flightCall struct {
+	done  chan struct{}
+	entry RegistryEntry
+	err   error
+}
+
+// do starts fetch unless a fetch for key is already running, then waits for
+// the result or for ctx, whichever comes first. The fetch gets a context
+// detached from the caller that started it, so that caller giving up does
+// not fail the others waiting on the same key.
+func (g *flightGroup) do(ctx context.Context, key RegistryKey, fetch func(context.Context) (RegistryEntry, error)) (RegistryEntry, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[RegistryKey]*flightCall)
+	}
+	call, ok := g.calls[key]
+	if !ok {
+		call = &flightCall{done: make(chan struct{})}
+		g.calls[key] = call
+		go g.run(context.WithoutCancel(ctx), key, call, fetch)
+	}
+	g.mu.Unlock()
+	select {
+	case <-call.done:
+		return call.entry, call.err
+	case <-ctx.Done():
+		return RegistryEntry{}, ctx.Err()
+	}
+}
+
+func (g *flightGroup) run(ctx context.Context, key RegistryKey, call *flightCall, fetch func(context.Context) (RegistryEntry, error)) {
+	ctx, cancel := context.WithTimeout(ctx, backgroundRefreshTimeout)
+	defer cancel()
+	call.entry, call.err = fetch(ctx)
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	close(call.done)
+}
+
+// ScheduleRegistryRefresh refreshes the model listings of every configured
+// provider, plus those seen through entitlements, immediately and then every
+// interval until ctx is done. Failed refreshes keep the previous listing.
+func (h *Kit) ScheduleRegistryRefresh(ctx context.Context, interval time.Duration, entitlements ...*EntitlementContext) error {
+	if interval <= 0 {
+		return fmt.Errorf("registry refresh interval must be positive")
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		state := h.current()
+		for provider := range state.adapters {
+			h.registry.ListRecords(ctx, &ListModelsOptions{Providers: []Provider{provider}, Refresh: true})
+		}
+		for _, entitlement := range entitlements {
+			h.registry.ListRecords(ctx, &ListModelsOptions{Entitlement: entitlement, Refresh: true})
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
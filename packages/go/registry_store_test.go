@@ -0,0 +1,192 @@
+package aikit
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// listingAdapter lists one model named after its current version, holding
+// each listing until gate is closed, or its context ends, when a gate is set.
+type listingAdapter struct {
+	scriptedAdapter
+	calls   atomic.Int32
+	version atomic.Value
+	gate    chan struct{}
+}
+
+func newListingAdapter(version string) *listingAdapter {
+	adapter := &listingAdapter{}
+	adapter.version.Store(version)
+	return adapter
+}
+
+func (a *listingAdapter) ListModels(ctx context.Context) ([]ModelMetadata, error) {
+	a.calls.Add(1)
+	if a.gate != nil {
+		select {
+		case <-a.gate:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return []ModelMetadata{{ID: a.version.Load().(string), Provider: ProviderOpenAI}}, nil
+}
+
+func listedID(t *testing.T, kit *Kit) string {
+	t.Helper()
+	models, err := kit.ListModels(context.Background(), &ListModelsOptions{Providers: []Provider{ProviderOpenAI}})
+	if err != nil || len(models) != 1 {
+		t.Fatalf("list models: %+v %v", models, err)
+	}
+	return models[0].ID
+}
+
+func TestFileRegistryStorePersistsAcrossKits(t *testing.T) {
+	path := t.TempDir() + "/models.json"
+	store, err := NewFileRegistryStore(path)
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	kit, err := New(Config{Adapters: map[Provider]ProviderAdapter{ProviderOpenAI: newListingAdapter("gpt-a")}, RegistryStore: store})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	listedID(t, kit)
+
+	reopened, err := NewFileRegistryStore(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	adapter := newListingAdapter("gpt-b")
+	restarted, err := New(Config{Adapters: map[Provider]ProviderAdapter{ProviderOpenAI: adapter}, RegistryStore: reopened})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	if id := listedID(t, restarted); id != "gpt-a" || adapter.calls.Load() != 0 {
+		t.Fatalf("expected the persisted listing without a fetch, got %q after %d calls", id, adapter.calls.Load())
+	}
+}
+
+func TestRegistryServesStaleWhileRefreshing(t *testing.T) {
+	adapter := newListingAdapter("v1")
+	kit, err := New(Config{Adapters: map[Provider]ProviderAdapter{ProviderOpenAI: adapter}, RegistryTTL: time.Millisecond})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	listedID(t, kit)
+	adapter.version.Store("v2")
+	time.Sleep(5 * time.Millisecond)
+	if id := listedID(t, kit); id != "v1" {
+		t.Fatalf("expected the stale listing, got %q", id)
+	}
+	deadline := time.Now().Add(time.Second)
+	for adapter.calls.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("background refresh never ran")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	noStale, err := New(Config{Adapters: map[Provider]ProviderAdapter{ProviderOpenAI: adapter}, RegistryTTL: time.Millisecond, RegistryStaleTTL: -1})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	listedID(t, noStale)
+	adapter.version.Store("v3")
+	time.Sleep(5 * time.Millisecond)
+	if id := listedID(t, noStale); id != "v3" {
+		t.Fatalf("expected a blocking refresh without a stale window, got %q", id)
+	}
+}
+
+func TestRegistryCollapsesConcurrentFetches(t *testing.T) {
+	adapter := newListingAdapter("m")
+	adapter.gate = make(chan struct{})
+	kit, err := New(Config{Adapters: map[Provider]ProviderAdapter{ProviderOpenAI: adapter}})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := kit.ListModels(context.Background(), nil); err != nil {
+				t.Errorf("list models: %v", err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(adapter.gate)
+	wg.Wait()
+	if calls := adapter.calls.Load(); calls != 1 {
+		t.Fatalf("expected one fetch, got %d", calls)
+	}
+}
+
+func TestRegistryFetchOutlivesFirstCaller(t *testing.T) {
+	adapter := newListingAdapter("m")
+	adapter.gate = make(chan struct{})
+	kit, err := New(Config{Adapters: map[Provider]ProviderAdapter{ProviderOpenAI: adapter}})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := kit.ListModels(ctx, nil)
+		first <- err
+	}()
+	for adapter.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	second := make(chan error, 1)
+	go func() {
+		_, err := kit.ListModels(context.Background(), nil)
+		second <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	if err := <-first; err == nil {
+		t.Fatalf("expected the cancelled caller to give up")
+	}
+	close(adapter.gate)
+	if err := <-second; err != nil {
+		t.Fatalf("expected the waiting caller to get the shared fetch, got %v", err)
+	}
+	if calls := adapter.calls.Load(); calls != 1 {
+		t.Fatalf("expected one fetch, got %d", calls)
+	}
+}
+
+func TestScheduleRegistryRefresh(t *testing.T) {
+	adapter := newListingAdapter("m")
+	store := NewMemoryRegistryStore()
+	kit, err := New(Config{Adapters: map[Provider]ProviderAdapter{ProviderOpenAI: adapter}, RegistryStore: store})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- kit.ScheduleRegistryRefresh(ctx, 10*time.Millisecond, &EntitlementContext{Provider: ProviderOpenAI, TenantID: "acme"})
+	}()
+	deadline := time.Now().Add(time.Second)
+	for adapter.calls.Load() < 4 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected repeated refreshes, got %d", adapter.calls.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	keys, _ := store.Keys(context.Background())
+	if len(keys) != 2 {
+		t.Fatalf("expected the default and tenant listings, got %+v", keys)
+	}
+}
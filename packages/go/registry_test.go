@@ -0,0 +1,151 @@
+package aikit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// failingListAdapter fails every listing with err, or hangs until the
+// listing's context is done when err is nil.
+type failingListAdapter struct {
+	scriptedAdapter
+	err error
+}
+
+func (a *failingListAdapter) ListModels(ctx context.Context) ([]ModelMetadata, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func partialKit(t *testing.T) *Kit {
+	t.Helper()
+	kit, err := New(Config{Adapters: map[Provider]ProviderAdapter{
+		ProviderOpenAI:    newListingAdapter("gpt"),
+		ProviderAnthropic: &failingListAdapter{err: &KitError{Kind: ErrorProviderAuth, Message: "invalid x-api-key", UpstreamCode: "authentication_error"}},
+		ProviderGoogle:    &failingListAdapter{},
+	}})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	return kit
+}
+
+func TestListModelsPartialReportsFailedProviders(t *testing.T) {
+	kit := partialKit(t)
+	opts := &ListModelsOptions{Providers: []Provider{ProviderOpenAI, ProviderAnthropic, ProviderGoogle}, ProviderTimeout: 50 * time.Millisecond}
+	start := time.Now()
+	if _, err := kit.ListModels(context.Background(), opts); err == nil {
+		t.Fatalf("expected a failing provider to fail the full listing")
+	}
+
+	opts.Partial = true
+	result, err := kit.ListModelsDetailed(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatalf("providers were not listed concurrently within their timeout")
+	}
+	if len(result.Models) != 1 || result.Models[0].ID != "gpt" {
+		t.Fatalf("unexpected models %+v", result.Models)
+	}
+	want := []ProviderListError{
+		{Provider: ProviderAnthropic, Kind: string(ErrorProviderAuth), Message: "invalid x-api-key", UpstreamCode: "authentication_error"},
+		{Provider: ProviderGoogle, Kind: string(ErrorProviderUnavailable), Message: "model listing timed out after 50ms"},
+	}
+	if len(result.Errors) != len(want) || result.Errors[0] != want[0] || result.Errors[1] != want[1] {
+		t.Fatalf("unexpected errors %+v", result.Errors)
+	}
+}
+
+func TestModelsHandlerPartial(t *testing.T) {
+	handler := ModelsHandler(partialKit(t), &ModelsHandlerOptions{ProviderTimeout: 20 * time.Millisecond})
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/provider-models", nil))
+	if rec.Code == http.StatusOK {
+		t.Fatalf("expected the full listing to fail")
+	}
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/provider-models?partial=true", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var result ListModelsResult
+	readBody(t, rec.Result().Body, &result)
+	if len(result.Models) != 1 || len(result.Errors) != 2 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
+
+func TestRegistryFetchWaitersHonorTheirContext(t *testing.T) {
+	adapter := newListingAdapter("m")
+	adapter.gate = make(chan struct{})
+	defer close(adapter.gate)
+	kit, err := New(Config{Adapters: map[Provider]ProviderAdapter{ProviderOpenAI: adapter}})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	go kit.ListModels(context.Background(), nil)
+	time.Sleep(10 * time.Millisecond)
+	_, err = kit.ListModels(context.Background(), &ListModelsOptions{ProviderTimeout: 20 * time.Millisecond})
+	var kitErr *KitError
+	if !errors.As(err, &kitErr) || kitErr.Kind != ErrorProviderUnavailable {
+		t.Fatalf("expected a waiter to time out on its own deadline, got %v", err)
+	}
+}
+
+func TestModelRecordsKeepUnknownFieldsUnknown(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"models": [
+  {"name": "models/gemini-2.5-flash", "inputTokenLimit": 1048576, "outputTokenLimit": 65536, "thinking": true,
+   "supportedGenerationMethods": ["generateContent", "countTokens", "batchGenerateContent"]},
+  {"name": "models/text-embedding-004", "inputTokenLimit": 2048, "outputTokenLimit": 1,
+   "supportedGenerationMethods": ["embedContent"]},
+  {"name": "models/mystery"}
+]}`))
+	}))
+	defer server.Close()
+	kit, err := New(Config{Google: &GoogleConfig{APIKey: "k", BaseURL: server.URL}})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	records, err := kit.ListModelRecords(context.Background(), nil)
+	if err != nil || len(records) != 3 {
+		t.Fatalf("list records: %+v %v", records, err)
+	}
+	byID := map[string]ModelRecord{}
+	for _, record := range records {
+		byID[record.ProviderModelID] = record
+	}
+
+	flash := byID["gemini-2.5-flash"]
+	if flash.Limits == nil || flash.Limits.ContextTokens != 1000000 || flash.Limits.MaxOutputTokens != 65536 {
+		t.Fatalf("expected the curated context window and the listed output limit, got %+v", flash.Limits)
+	}
+	if !isTrue(flash.Modalities.Text) || flash.Modalities.ImageOut == nil || *flash.Modalities.ImageOut {
+		t.Fatalf("expected known text in and no image out, got %+v", flash.Modalities)
+	}
+	if flash.Modalities.Vision != nil || flash.Features.Tools != nil || flash.Features.JSONMode != nil {
+		t.Fatalf("fields neither source reports should stay unknown: %+v %+v", flash.Modalities, flash.Features)
+	}
+	if flash.Features.Streaming == nil || *flash.Features.Streaming || !isTrue(flash.Features.Batch) {
+		t.Fatalf("expected streaming and batch from the generation methods, got %+v", flash.Features)
+	}
+
+	embedding := byID["text-embedding-004"]
+	if embedding.Modalities.Text == nil || *embedding.Modalities.Text || embedding.Features.Streaming == nil || *embedding.Features.Streaming {
+		t.Fatalf("an embedding model should report no text generation or streaming: %+v", embedding)
+	}
+	mystery := byID["mystery"]
+	if mystery.Limits != nil || mystery.Features != (ModelFeatures{}) || mystery.Modalities != (ModelModalities{}) {
+		t.Fatalf("a bare listing should leave everything unknown: %+v", mystery)
+	}
+}
@@ -0,0 +1,172 @@
+package aikit
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// ReloadEvent describes one Reload attempt. Err is set when the new
+// configuration was rejected, in which case the Kit keeps the old one.
+type ReloadEvent struct {
+	Added   []Provider `json:"added,omitempty"`
+	Removed []Provider `json:"removed,omitempty"`
+	// Changed lists providers whose settings or keys differ.
+	Changed []Provider `json:"changed,omitempty"`
+	// Invalidated counts registry cache entries and learned or verified
+	// availability dropped because their provider or key went away.
+	Invalidated int   `json:"invalidated"`
+	Err         error `json:"-"`
+}
+
+// Reload validates config and atomically replaces the Kit's adapters, key
+// pools and registry settings. Requests already running finish on the
+// configuration they started with. Cached model listings for removed
+// providers, removed keys and changed provider settings are invalidated;
+// everything else stays warm. A nil OnReload keeps the current handler.
+func (h *Kit) Reload(config Config) error {
+	event, onReload := h.reload(config)
+	if onReload != nil {
+		onReload(event)
+	}
+	return event.Err
+}
+
+func (h *Kit) reload(config Config) (ReloadEvent, func(ReloadEvent)) {
+	h.reloadMu.Lock()
+	defer h.reloadMu.Unlock()
+	old := h.current()
+	if config.OnReload == nil {
+		config.OnReload = old.config.OnReload
+	}
+	next, err := newKitState(config)
+	if err != nil {
+		return ReloadEvent{Err: err}, config.OnReload
+	}
+	h.registry.reconfigure(next)
+	h.state.Store(next)
+	event, stale := diffKitStates(old, next)
+	event.Invalidated = h.registry.invalidate(stale)
+	return event, config.OnReload
+}
+
+// WatchConfig polls path every interval (5s when zero) and reloads the Kit
+// through LoadConfig whenever the file's contents change. HTTPClient,
+// Adapters, AdapterFactory and OnReload carry over from the running
+// configuration because a file cannot express them. A file that fails to
+// load is reported through OnReload and leaves the Kit untouched.
+// WatchConfig blocks until ctx is done.
+func (h *Kit) WatchConfig(ctx context.Context, path string, interval time.Duration) error {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	last, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		// Unreadable files are usually mid-rename; try again next tick.
+		data, err := os.ReadFile(path)
+		if err != nil || bytes.Equal(data, last) {
+			continue
+		}
+		last = data
+		running := h.current().config
+		config, err := LoadConfig(path)
+		if err != nil {
+			if running.OnReload != nil {
+				running.OnReload(ReloadEvent{Err: err})
+			}
+			continue
+		}
+		config.HTTPClient = running.HTTPClient
+		config.Adapters = running.Adapters
+		config.AdapterFactory = running.AdapterFactory
+		config.OnReload = running.OnReload
+		h.Reload(config)
+	}
+}
+
+// diffKitStates reports what a reload changed and which registry keys no
+// longer describe a configured provider or key.
+func diffKitStates(old, next *kitState) (ReloadEvent, func(RegistryKey) bool) {
+	var event ReloadEvent
+	removed := make(map[Provider]bool)
+	changed := make(map[Provider]bool)
+	removedKeys := make(map[Provider]map[string]bool)
+	for provider := range old.adapters {
+		if _, ok := next.adapters[provider]; !ok {
+			event.Removed = append(event.Removed, provider)
+			removed[provider] = true
+		}
+	}
+	for provider := range next.adapters {
+		if _, ok := old.adapters[provider]; !ok {
+			event.Added = append(event.Added, provider)
+			continue
+		}
+		gone := make(map[string]bool)
+		nextKeys := next.keyPools[provider].fingerprints()
+		for fingerprint := range old.keyPools[provider].fingerprints() {
+			if !nextKeys[fingerprint] {
+				gone[fingerprint] = true
+			}
+		}
+		if len(gone) > 0 || !reflect.DeepEqual(old.config.providerSection(provider), next.config.providerSection(provider)) {
+			event.Changed = append(event.Changed, provider)
+			changed[provider] = true
+			removedKeys[provider] = gone
+		}
+	}
+	for _, list := range [][]Provider{event.Added, event.Removed, event.Changed} {
+		sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+	}
+	stale := func(key RegistryKey) bool {
+		if removed[key.Provider] {
+			return true
+		}
+		// Listings fetched without an entitlement used the provider's
+		// configured adapter, so any change to it makes them stale.
+		if key.Fingerprint == "default" {
+			return changed[key.Provider]
+		}
+		return removedKeys[key.Provider][key.Fingerprint]
+	}
+	return event, stale
+}
+
+// providerSection returns the part of c that configures provider, for
+// comparing two configurations.
+func (c Config) providerSection(provider Provider) interface{} {
+	if adapter := c.Adapters[provider]; adapter != nil {
+		return adapter
+	}
+	switch provider {
+	case ProviderOpenAI:
+		return c.OpenAI
+	case ProviderAnthropic:
+		return c.Anthropic
+	case ProviderXAI:
+		return c.XAI
+	case ProviderGoogle:
+		return c.Google
+	case ProviderOllama:
+		return c.Ollama
+	}
+	for _, compat := range c.Compatible {
+		if compat.Name == provider {
+			return compat
+		}
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package aikit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// keyEchoServer answers OpenAI model listings and completions with the
+// bearer key it received, holding completions for "slow" keys until release
+// is closed.
+func keyEchoServer(t *testing.T, release chan struct{}) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/models" {
+			w.Write([]byte(`{"data":[{"id":"model-for-` + key + `"}]}`))
+			return
+		}
+		if strings.HasPrefix(key, "slow") {
+			<-release
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"` + key + `"},"finish_reason":"stop"}]}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func textInput(provider Provider) GenerateInput {
+	return GenerateInput{Provider: provider, Model: "m", Messages: []Message{{Role: "user", Content: []ContentPart{{Type: "text", Text: "hi"}}}}}
+}
+
+func TestReloadRotatesKeysAndInvalidatesRegistry(t *testing.T) {
+	server := keyEchoServer(t, nil)
+	var events []ReloadEvent
+	kit, err := New(Config{
+		OpenAI:   &OpenAIConfig{APIKey: "key-a", BaseURL: server.URL},
+		Ollama:   &OllamaConfig{BaseURL: server.URL},
+		OnReload: func(event ReloadEvent) { events = append(events, event) },
+	})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	ctx := context.Background()
+	models, err := kit.ListModels(ctx, &ListModelsOptions{Providers: []Provider{ProviderOpenAI}})
+	if err != nil || len(models) != 1 || models[0].ID != "model-for-key-a" {
+		t.Fatalf("unexpected models %+v %v", models, err)
+	}
+
+	err = kit.Reload(Config{
+		OpenAI:     &OpenAIConfig{APIKey: "key-b", BaseURL: server.URL},
+		Compatible: []CompatibleConfig{{Name: "groq", BaseURL: server.URL, APIKey: "gsk"}},
+	})
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if out, err := kit.Generate(ctx, textInput(ProviderOpenAI)); err != nil || out.Text != "key-b" {
+		t.Fatalf("expected the rotated key, got %q %v", out.Text, err)
+	}
+	models, err = kit.ListModels(ctx, &ListModelsOptions{Providers: []Provider{ProviderOpenAI}})
+	if err != nil || models[0].ID != "model-for-key-b" {
+		t.Fatalf("registry kept a listing for the removed key: %+v %v", models, err)
+	}
+	if _, err := kit.Generate(ctx, textInput(ProviderOllama)); err == nil {
+		t.Fatalf("expected ollama to be removed")
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("expected one event, got %+v", events)
+	}
+	event := events[0]
+	if len(event.Added) != 1 || event.Added[0] != "groq" || len(event.Removed) != 1 || event.Removed[0] != ProviderOllama ||
+		len(event.Changed) != 1 || event.Changed[0] != ProviderOpenAI || event.Invalidated != 1 {
+		t.Fatalf("unexpected event %+v", event)
+	}
+}
+
+func TestReloadLetsInFlightRequestsFinish(t *testing.T) {
+	release := make(chan struct{})
+	server := keyEchoServer(t, release)
+	kit, err := New(Config{OpenAI: &OpenAIConfig{APIKey: "slow-old", BaseURL: server.URL}})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	var wg sync.WaitGroup
+	var inFlight GenerateOutput
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		inFlight, _ = kit.Generate(context.Background(), textInput(ProviderOpenAI))
+	}()
+	time.Sleep(50 * time.Millisecond)
+	if err := kit.Reload(Config{OpenAI: &OpenAIConfig{APIKey: "new", BaseURL: server.URL}}); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if out, _ := kit.Generate(context.Background(), textInput(ProviderOpenAI)); out.Text != "new" {
+		t.Fatalf("expected new key after reload, got %q", out.Text)
+	}
+	close(release)
+	wg.Wait()
+	if inFlight.Text != "slow-old" {
+		t.Fatalf("in-flight request should finish on the old key, got %q", inFlight.Text)
+	}
+}
+
+func TestReloadRejectsInvalidConfig(t *testing.T) {
+	server := keyEchoServer(t, nil)
+	var events []ReloadEvent
+	kit, err := New(Config{OpenAI: &OpenAIConfig{APIKey: "key-a", BaseURL: server.URL}, OnReload: func(event ReloadEvent) { events = append(events, event) }})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	err = kit.Reload(Config{OpenAI: &OpenAIConfig{}})
+	var cfgErr *ConfigError
+	if !errors.As(err, &cfgErr) || len(events) != 1 || events[0].Err != err {
+		t.Fatalf("expected a rejected reload event, got %v %+v", err, events)
+	}
+	if out, _ := kit.Generate(context.Background(), textInput(ProviderOpenAI)); out.Text != "key-a" {
+		t.Fatalf("old config should stay active, got %q", out.Text)
+	}
+}
+
+func TestWatchConfigReloadsOnChange(t *testing.T) {
+	clearProviderEnv(t)
+	server := keyEchoServer(t, nil)
+	path := writeConfigFile(t, "aikit.yaml", "openai:\n  apiKey: key-a\n  baseURL: "+server.URL+"\n")
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	events := make(chan ReloadEvent, 4)
+	cfg.OnReload = func(event ReloadEvent) { events <- event }
+	kit, err := New(cfg)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go kit.WatchConfig(ctx, path, 10*time.Millisecond)
+
+	time.Sleep(30 * time.Millisecond)
+	// Replace the file by rename so the watcher never sees a partial write.
+	writeFile := func(content string) {
+		t.Helper()
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, []byte(content), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			t.Fatalf("rename: %v", err)
+		}
+	}
+	writeFile("openai:\n  apiKey: ${TEST_WATCH_UNSET}\n")
+	if event := <-events; event.Err == nil {
+		t.Fatalf("expected a load error event, got %+v", event)
+	}
+	writeFile("openai:\n  apiKey: key-b\n  baseURL: " + server.URL + "\n")
+	if event := <-events; event.Err != nil || len(event.Changed) != 1 {
+		t.Fatalf("unexpected event %+v", event)
+	}
+	if out, _ := kit.Generate(context.Background(), textInput(ProviderOpenAI)); out.Text != "key-b" {
+		t.Fatalf("expected reloaded key, got %q", out.Text)
+	}
+}
@@ -51,13 +51,13 @@ func filterModels(models []ModelRecord, req ModelResolutionRequest) []ModelRecor
 		if !model.Availability.Entitled {
 			continue
 		}
-		if req.Constraints.RequireTools && !model.Features.Tools {
+		if req.Constraints.RequireTools && !isTrue(model.Features.Tools) {
 			continue
 		}
-		if req.Constraints.RequireJSON && !(model.Features.JSONMode || model.Features.JSONSchema) {
+		if req.Constraints.RequireJSON && !(isTrue(model.Features.JSONMode) || isTrue(model.Features.JSONSchema)) {
 			continue
 		}
-		if req.Constraints.RequireVision && !model.Modalities.Vision {
+		if req.Constraints.RequireVision && !isTrue(model.Modalities.Vision) {
 			continue
 		}
 		if !allowPreview && hasTag(model.Tags, "preview") {
@@ -102,7 +102,7 @@ func matchesPreferred(model ModelRecord, preferred []string) bool {
 		return true
 	}
 	for _, entry := range preferred {
-		if entry == model.ID || entry == model.ProviderModelID {
+		if preferredMatch(model, entry) {
 			return true
 		}
 	}
@@ -114,13 +114,26 @@ func preferredRank(model ModelRecord, preferred []string) int {
 		return len(preferred) + 1
 	}
 	for idx, entry := range preferred {
-		if entry == model.ID || entry == model.ProviderModelID {
+		if preferredMatch(model, entry) {
 			return idx
 		}
 	}
 	return len(preferred) + 1
 }
 
+// preferredMatch accepts a record id, a provider model id or an alias.
+func preferredMatch(model ModelRecord, entry string) bool {
+	if entry == model.ID || entry == model.ProviderModelID {
+		return true
+	}
+	for _, alias := range model.Aliases {
+		if entry == alias {
+			return true
+		}
+	}
+	return false
+}
+
 func withinCost(model ModelRecord, maxCost float64) bool {
 	if model.Pricing == nil {
 		return true
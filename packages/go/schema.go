@@ -0,0 +1,335 @@
+package aikit
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Struct fields are described with tags alongside the usual json tag:
+//
+//	City  string `json:"city" description:"City name"`
+//	Unit  string `json:"unit,omitempty" enum:"celsius,fahrenheit"`
+//	Limit *int   `json:"limit" required:"false"`
+//
+// Fields are required unless they use omitempty or required:"false". In
+// strict mode every field is required and optional fields become nullable,
+// matching OpenAI's strict structured-output rules.
+type SchemaOptions struct {
+	Strict bool
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+func JSONSchemaFor[T any](opts SchemaOptions) (map[string]interface{}, error) {
+	return JSONSchemaOf(reflect.TypeOf((*T)(nil)).Elem(), opts)
+}
+
+func JSONSchemaOf(t reflect.Type, opts SchemaOptions) (map[string]interface{}, error) {
+	if t == nil {
+		return nil, &KitError{Kind: ErrorValidation, Message: "schema: type is required"}
+	}
+	builder := &schemaBuilder{strict: opts.Strict, visiting: map[reflect.Type]bool{}}
+	return builder.build(t, "")
+}
+
+func ToolDefinitionFor[T any](name, description string) (ToolDefinition, error) {
+	schema, err := JSONSchemaFor[T](SchemaOptions{})
+	if err != nil {
+		return ToolDefinition{}, err
+	}
+	if schema["type"] != "object" {
+		return ToolDefinition{}, &KitError{Kind: ErrorValidation, Message: fmt.Sprintf("schema: tool %s parameters must be a struct", name)}
+	}
+	return ToolDefinition{Name: name, Description: description, Parameters: schema}, nil
+}
+
+func ResponseFormatFor[T any](name string, strict bool) (*ResponseFormat, error) {
+	schema, err := JSONSchemaFor[T](SchemaOptions{Strict: strict})
+	if err != nil {
+		return nil, err
+	}
+	if strict && schema["type"] != "object" {
+		return nil, &KitError{Kind: ErrorValidation, Message: fmt.Sprintf("schema: strict response format %s must be a struct", name)}
+	}
+	return &ResponseFormat{
+		Type: "json_schema",
+		JsonSchema: &JsonSchemaFormat{
+			Name:   name,
+			Schema: schema,
+			Strict: strict,
+		},
+	}, nil
+}
+
+type schemaBuilder struct {
+	strict   bool
+	visiting map[reflect.Type]bool
+}
+
+func (b *schemaBuilder) build(t reflect.Type, path string) (map[string]interface{}, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}, nil
+	case t == rawMessageType:
+		return b.anySchema(path)
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}, nil
+		}
+		items, err := b.build(t.Elem(), path+"[]")
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "array", "items": items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, schemaError(path, "map keys must be strings")
+		}
+		if b.strict {
+			return nil, schemaError(path, "maps are not allowed in strict schemas")
+		}
+		values, err := b.build(t.Elem(), path+"{}")
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "object", "additionalProperties": values}, nil
+	case reflect.Interface:
+		return b.anySchema(path)
+	case reflect.Struct:
+		return b.buildStruct(t, path)
+	}
+	return nil, schemaError(path, fmt.Sprintf("unsupported type %s", t))
+}
+
+func (b *schemaBuilder) anySchema(path string) (map[string]interface{}, error) {
+	if b.strict {
+		return nil, schemaError(path, "untyped values are not allowed in strict schemas")
+	}
+	return map[string]interface{}{}, nil
+}
+
+func (b *schemaBuilder) buildStruct(t reflect.Type, path string) (map[string]interface{}, error) {
+	if b.visiting[t] {
+		return nil, schemaError(path, fmt.Sprintf("recursive type %s is not supported", t))
+	}
+	b.visiting[t] = true
+	defer delete(b.visiting, t)
+
+	properties := map[string]interface{}{}
+	var order []string
+	var required []string
+	var fields []schemaField
+	collectFields(t, 0, map[reflect.Type]bool{}, &fields)
+	for _, field := range dominantFields(fields) {
+		schema, isRequired, err := b.buildField(field, path)
+		if err != nil {
+			return nil, err
+		}
+		properties[field.name] = schema
+		order = append(order, field.name)
+		if isRequired {
+			required = append(required, field.name)
+		}
+	}
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if b.strict {
+		schema["additionalProperties"] = false
+		required = order
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	} else if b.strict {
+		schema["required"] = []string{}
+	}
+	return schema, nil
+}
+
+// schemaField is a struct field found while walking embedded structs, with
+// what encoding/json needs to choose between fields sharing a JSON name.
+type schemaField struct {
+	field   reflect.StructField
+	name    string
+	options string
+	depth   int
+	tagged  bool
+}
+
+// collectFields lists t's JSON fields in declaration order, descending into
+// untagged embedded structs the way encoding/json promotes their fields.
+func collectFields(t reflect.Type, depth int, embedding map[reflect.Type]bool, fields *[]schemaField) {
+	if embedding[t] {
+		return
+	}
+	embedding[t] = true
+	defer delete(embedding, t)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				collectFields(embedded, depth+1, embedding, fields)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		tagged := name != ""
+		if !tagged {
+			name = field.Name
+		}
+		*fields = append(*fields, schemaField{field: field, name: name, options: options, depth: depth, tagged: tagged})
+	}
+}
+
+// dominantFields applies encoding/json's rules to fields sharing a name: the
+// shallowest wins, a tagged one breaks a tie at that depth, and a remaining
+// tie drops the name altogether.
+func dominantFields(fields []schemaField) []schemaField {
+	byName := make(map[string][]schemaField)
+	var names []string
+	for _, field := range fields {
+		if _, seen := byName[field.name]; !seen {
+			names = append(names, field.name)
+		}
+		byName[field.name] = append(byName[field.name], field)
+	}
+	dominant := make([]schemaField, 0, len(names))
+	for _, name := range names {
+		candidates := byName[name]
+		depth := candidates[0].depth
+		for _, field := range candidates[1:] {
+			if field.depth < depth {
+				depth = field.depth
+			}
+		}
+		var shallowest, tagged []schemaField
+		for _, field := range candidates {
+			if field.depth != depth {
+				continue
+			}
+			shallowest = append(shallowest, field)
+			if field.tagged {
+				tagged = append(tagged, field)
+			}
+		}
+		switch {
+		case len(shallowest) == 1:
+			dominant = append(dominant, shallowest[0])
+		case len(tagged) == 1:
+			dominant = append(dominant, tagged[0])
+		}
+	}
+	return dominant
+}
+
+func (b *schemaBuilder) buildField(field schemaField, path string) (map[string]interface{}, bool, error) {
+	fieldPath := field.name
+	if path != "" {
+		fieldPath = path + "." + field.name
+	}
+	schema, err := b.build(field.field.Type, fieldPath)
+	if err != nil {
+		return nil, false, err
+	}
+	if description := field.field.Tag.Get("description"); description != "" {
+		schema["description"] = description
+	}
+	if enum := field.field.Tag.Get("enum"); enum != "" {
+		// An enum on a slice constrains its elements.
+		target := schema
+		if items, ok := schema["items"].(map[string]interface{}); ok && schema["type"] == "array" {
+			target = items
+		}
+		values, err := parseEnumTag(enum, target["type"], fieldPath)
+		if err != nil {
+			return nil, false, err
+		}
+		target["enum"] = values
+	}
+	isRequired := !strings.Contains(","+field.options+",", ",omitempty,")
+	if value := field.field.Tag.Get("required"); value != "" {
+		isRequired = value == "true"
+	}
+	if b.strict && !isRequired {
+		schema = nullableSchema(schema)
+	}
+	return schema, isRequired, nil
+}
+
+func parseEnumTag(raw string, schemaType interface{}, path string) ([]interface{}, error) {
+	parts := strings.Split(raw, ",")
+	values := make([]interface{}, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		switch schemaType {
+		case "integer":
+			value, err := strconv.ParseInt(part, 10, 64)
+			if err != nil {
+				return nil, schemaError(path, fmt.Sprintf("invalid integer enum value %q", part))
+			}
+			values = append(values, value)
+		case "number":
+			value, err := strconv.ParseFloat(part, 64)
+			if err != nil {
+				return nil, schemaError(path, fmt.Sprintf("invalid number enum value %q", part))
+			}
+			values = append(values, value)
+		default:
+			values = append(values, part)
+		}
+	}
+	return values, nil
+}
+
+func nullableSchema(schema map[string]interface{}) map[string]interface{} {
+	if kind, ok := schema["type"].(string); ok {
+		schema["type"] = []string{kind, "null"}
+		if enum, ok := schema["enum"].([]interface{}); ok {
+			schema["enum"] = append(enum, nil)
+		}
+		return schema
+	}
+	return map[string]interface{}{
+		"anyOf": []interface{}{schema, map[string]interface{}{"type": "null"}},
+	}
+}
+
+func schemaError(path, message string) error {
+	if path == "" {
+		path = "(root)"
+	}
+	return &KitError{Kind: ErrorValidation, Message: fmt.Sprintf("schema: %s: %s", path, message)}
+}
@@ -0,0 +1,157 @@
+package aikit
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type schemaAddress struct {
+	Street string `json:"street" description:"Street line"`
+	Zip    string `json:"zip,omitempty"`
+}
+
+type schemaBase struct {
+	ID string `json:"id"`
+}
+
+type schemaOrder struct {
+	schemaBase
+	Status    string          `json:"status" enum:"open,closed"`
+	Priority  int             `json:"priority,omitempty" enum:"1,2,3"`
+	Tags      []string        `json:"tags"`
+	Address   *schemaAddress  `json:"address" required:"false"`
+	Lines     []schemaAddress `json:"lines,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Ignored   string          `json:"-"`
+	internal  string
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+func TestJSONSchemaForStruct(t *testing.T) {
+	schema, err := JSONSchemaFor[schemaOrder](SchemaOptions{})
+	if err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	props := schema["properties"].(map[string]interface{})
+	for _, name := range []string{"id", "status", "priority", "tags", "address", "lines", "createdAt", "labels"} {
+		if _, ok := props[name]; !ok {
+			t.Fatalf("missing property %s: %+v", name, props)
+		}
+	}
+	if _, ok := props["Ignored"]; ok {
+		t.Fatalf("expected json:\"-\" field to be skipped")
+	}
+	if !reflect.DeepEqual(schema["required"], []string{"id", "status", "tags", "createdAt"}) {
+		t.Fatalf("unexpected required list: %+v", schema["required"])
+	}
+	status := props["status"].(map[string]interface{})
+	if !reflect.DeepEqual(status["enum"], []interface{}{"open", "closed"}) {
+		t.Fatalf("unexpected enum: %+v", status)
+	}
+	priority := props["priority"].(map[string]interface{})
+	if priority["type"] != "integer" || !reflect.DeepEqual(priority["enum"], []interface{}{int64(1), int64(2), int64(3)}) {
+		t.Fatalf("unexpected integer enum: %+v", priority)
+	}
+	address := props["address"].(map[string]interface{})
+	street := address["properties"].(map[string]interface{})["street"].(map[string]interface{})
+	if street["description"] != "Street line" {
+		t.Fatalf("expected nested description: %+v", address)
+	}
+	lines := props["lines"].(map[string]interface{})
+	if lines["type"] != "array" || lines["items"].(map[string]interface{})["type"] != "object" {
+		t.Fatalf("expected slice of objects: %+v", lines)
+	}
+	if _, err := json.Marshal(schema); err != nil {
+		t.Fatalf("schema should marshal: %v", err)
+	}
+}
+
+// The overlapping names are untagged; vet rejects repeated json tags even
+// where encoding/json has a rule for them.
+type schemaAudit struct {
+	Label string
+	Note  string
+}
+
+type schemaStamp struct {
+	Note string
+}
+
+type schemaTicket struct {
+	schemaBase
+	schemaAudit
+	schemaStamp
+	Label string   `description:"Ticket label"`
+	Roles []string `json:"roles" enum:"admin,viewer"`
+}
+
+func TestJSONSchemaFollowsEncodingJSONFields(t *testing.T) {
+	schema, err := JSONSchemaFor[schemaTicket](SchemaOptions{})
+	if err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	props := schema["properties"].(map[string]interface{})
+	if label := props["Label"].(map[string]interface{}); label["description"] != "Ticket label" {
+		t.Fatalf("expected the shallow field to shadow the promoted one: %+v", label)
+	}
+	if _, ok := props["Note"]; ok {
+		t.Fatalf("a name promoted twice at the same depth is dropped, as encoding/json does: %+v", props)
+	}
+	if !reflect.DeepEqual(schema["required"], []string{"id", "Label", "roles"}) {
+		t.Fatalf("unexpected required list: %+v", schema["required"])
+	}
+	roles := props["roles"].(map[string]interface{})
+	items := roles["items"].(map[string]interface{})
+	if _, ok := roles["enum"]; ok || !reflect.DeepEqual(items["enum"], []interface{}{"admin", "viewer"}) {
+		t.Fatalf("expected the enum on the array items: %+v", roles)
+	}
+}
+
+func TestJSONSchemaStrictMode(t *testing.T) {
+	type strictAnswer struct {
+		Answer  string         `json:"answer"`
+		Note    string         `json:"note,omitempty"`
+		Address *schemaAddress `json:"address,omitempty"`
+	}
+	format, err := ResponseFormatFor[strictAnswer]("answer", true)
+	if err != nil {
+		t.Fatalf("response format: %v", err)
+	}
+	schema := format.JsonSchema.Schema
+	if !format.JsonSchema.Strict || schema["additionalProperties"] != false {
+		t.Fatalf("expected strict object schema: %+v", schema)
+	}
+	if !reflect.DeepEqual(schema["required"], []string{"answer", "note", "address"}) {
+		t.Fatalf("strict mode should require every field: %+v", schema["required"])
+	}
+	props := schema["properties"].(map[string]interface{})
+	if !reflect.DeepEqual(props["note"].(map[string]interface{})["type"], []string{"string", "null"}) {
+		t.Fatalf("optional field should be nullable: %+v", props["note"])
+	}
+	address := props["address"].(map[string]interface{})
+	if address["additionalProperties"] != false || !reflect.DeepEqual(address["required"], []string{"street", "zip"}) {
+		t.Fatalf("nested strict object should be closed: %+v", address)
+	}
+
+	if _, err := JSONSchemaFor[schemaOrder](SchemaOptions{Strict: true}); err == nil {
+		t.Fatalf("expected maps to be rejected in strict mode")
+	}
+}
+
+func TestToolDefinitionFor(t *testing.T) {
+	type lookupArgs struct {
+		Query string `json:"query" description:"Search text"`
+	}
+	def, err := ToolDefinitionFor[lookupArgs]("lookup", "Look something up")
+	if err != nil {
+		t.Fatalf("tool definition: %v", err)
+	}
+	if def.Name != "lookup" || def.Parameters["type"] != "object" {
+		t.Fatalf("unexpected definition: %+v", def)
+	}
+	if _, err := ToolDefinitionFor[string]("bad", ""); err == nil {
+		t.Fatalf("expected non-struct parameters to fail")
+	}
+}
@@ -0,0 +1,186 @@
+package aikit
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ValidateJSONSchema checks a decoded JSON value against the subset of JSON
+// Schema emitted by JSONSchemaOf: type, enum, properties, required,
+// additionalProperties, items and anyOf.
+func ValidateJSONSchema(schema map[string]interface{}, value interface{}) error {
+	var problems []string
+	validateSchemaValue(schema, value, "$", &problems)
+	if len(problems) == 0 {
+		return nil
+	}
+	return &KitError{
+		Kind:    ErrorValidation,
+		Message: "schema validation failed: " + strings.Join(problems, "; "),
+	}
+}
+
+func validateSchemaValue(schema map[string]interface{}, value interface{}, path string, problems *[]string) {
+	if len(schema) == 0 {
+		return
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		for _, option := range anyOf {
+			optionSchema, _ := option.(map[string]interface{})
+			var nested []string
+			validateSchemaValue(optionSchema, value, path, &nested)
+			if len(nested) == 0 {
+				return
+			}
+		}
+		*problems = append(*problems, fmt.Sprintf("%s does not match any allowed schema", path))
+		return
+	}
+	if types := schemaTypes(schema["type"]); len(types) > 0 {
+		matched := false
+		for _, kind := range types {
+			if jsonValueHasType(value, kind) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			*problems = append(*problems, fmt.Sprintf("%s should be %s, got %s", path, strings.Join(types, " or "), jsonTypeName(value)))
+			return
+		}
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && !enumContains(enum, value) {
+		*problems = append(*problems, fmt.Sprintf("%s must be one of %v", path, enum))
+	}
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		for _, name := range schemaRequired(schema["required"]) {
+			if _, ok := typed[name]; !ok {
+				*problems = append(*problems, fmt.Sprintf("%s.%s is required", path, name))
+			}
+		}
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			childPath := path + "." + key
+			if propSchema, ok := properties[key].(map[string]interface{}); ok {
+				validateSchemaValue(propSchema, typed[key], childPath, problems)
+				continue
+			}
+			switch extra := schema["additionalProperties"].(type) {
+			case bool:
+				if !extra {
+					*problems = append(*problems, fmt.Sprintf("%s is not allowed", childPath))
+				}
+			case map[string]interface{}:
+				validateSchemaValue(extra, typed[key], childPath, problems)
+			}
+		}
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for idx, item := range typed {
+				validateSchemaValue(items, item, fmt.Sprintf("%s[%d]", path, idx), problems)
+			}
+		}
+	}
+}
+
+func schemaTypes(raw interface{}) []string {
+	switch typed := raw.(type) {
+	case string:
+		return []string{typed}
+	case []string:
+		return typed
+	case []interface{}:
+		out := make([]string, 0, len(typed))
+		for _, entry := range typed {
+			if kind, ok := entry.(string); ok {
+				out = append(out, kind)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func schemaRequired(raw interface{}) []string {
+	return schemaTypes(raw)
+}
+
+func jsonValueHasType(value interface{}, kind string) bool {
+	switch kind {
+	case "null":
+		return value == nil
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		number, ok := value.(float64)
+		return ok && number == math.Trunc(number)
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	}
+	return true
+}
+
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func enumContains(enum []interface{}, value interface{}) bool {
+	for _, candidate := range enum {
+		if number, ok := toFloat(candidate); ok {
+			if actual, ok := value.(float64); ok && actual == number {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch typed := value.(type) {
+	case int:
+		return float64(typed), true
+	case int64:
+		return float64(typed), true
+	case float64:
+		return typed, true
+	}
+	return 0, false
+}
@@ -7,46 +7,64 @@ import (
 	"strings"
 )
 
+// sseEvent is one decoded server-sent event. A final event with Err set
+// reports why the body stopped before EOF.
 type sseEvent struct {
 	Event string
 	Data  string
+	Err   error
 }
 
+// streamSSE decodes body until EOF, a read error or ctx cancellation. The
+// body is closed as soon as ctx is done so a blocked read returns promptly.
 func streamSSE(ctx context.Context, body io.ReadCloser) <-chan sseEvent {
 	ch := make(chan sseEvent)
 	go func() {
 		defer close(ch)
 		defer body.Close()
+		stop := context.AfterFunc(ctx, func() { body.Close() })
+		defer stop()
+		send := func(event sseEvent) bool {
+			select {
+			case ch <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
 		reader := bufio.NewReader(body)
 		var eventName string
 		var dataBuilder strings.Builder
-		flush := func() {
+		flush := func() bool {
 			if dataBuilder.Len() == 0 {
-				return
+				return true
 			}
-			ch <- sseEvent{
+			event := sseEvent{
 				Event: eventName,
 				Data:  strings.TrimSpace(dataBuilder.String()),
 			}
 			eventName = ""
 			dataBuilder.Reset()
+			return send(event)
 		}
 		for {
 			select {
 			case <-ctx.Done():
+				send(sseEvent{Err: ctx.Err()})
 				return
 			default:
 			}
 			line, err := reader.ReadString('\n')
-			if err != nil {
-				if err != io.EOF {
-					return
+			if err != nil && err != io.EOF {
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					err = ctxErr
 				}
+				send(sseEvent{Err: err})
+				return
 			}
 			line = strings.TrimRight(line, "\r\n")
 			if line == "" {
-				flush()
-				if err == io.EOF {
+				if !flush() || err == io.EOF {
 					return
 				}
 				continue
@@ -0,0 +1,223 @@
+package aikit
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// StreamAccumulator rebuilds the GenerateOutput that Generate would have
+// returned from a sequence of StreamChunks.
+//
+// Normalized tool chunks are grouped by Index: tool_call_start opens a call,
+// tool_call_delta appends its fragment and tool_call_end sets the final
+// arguments. Legacy tool_call chunks from custom adapters are de-duplicated
+// per call ID: a chunk whose arguments extend (or equal) the arguments
+// accumulated so far for that ID updates the existing call, any other
+// arguments start a new call, and chunks with only a Delta append to the
+// latest call for that ID.
+type StreamAccumulator struct {
+	mu           sync.Mutex
+	text         strings.Builder
+	calls        []ToolCall
+	indexed      map[int]int
+	usage        *Usage
+	cost         *CostBreakdown
+	finishReason string
+	ratings      []SafetyRating
+	err          *ChunkError
+	done         chan struct{}
+	doneOnce     sync.Once
+}
+
+func NewStreamAccumulator() *StreamAccumulator {
+	return &StreamAccumulator{indexed: map[int]int{}, done: make(chan struct{})}
+}
+
+func (a *StreamAccumulator) Add(chunk StreamChunk) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	switch chunk.Type {
+	case StreamChunkDelta:
+		a.text.WriteString(chunk.TextDelta)
+	case StreamChunkToolCallStart, StreamChunkToolCallDelta, StreamChunkToolCallEnd:
+		a.addIndexedToolCall(chunk)
+	case StreamChunkToolCall:
+		a.addToolCall(chunk)
+	case StreamChunkMessageEnd:
+		if chunk.Usage != nil {
+			usage := *chunk.Usage
+			a.usage = &usage
+		}
+		if chunk.Cost != nil {
+			cost := *chunk.Cost
+			a.cost = &cost
+		}
+		if chunk.FinishReason != "" {
+			a.finishReason = chunk.FinishReason
+		}
+		if len(chunk.SafetyRatings) > 0 {
+			a.ratings = append([]SafetyRating(nil), chunk.SafetyRatings...)
+		}
+	case StreamChunkError:
+		if chunk.Error != nil && a.err == nil {
+			chunkErr := *chunk.Error
+			a.err = &chunkErr
+		}
+	}
+}
+
+func (a *StreamAccumulator) addIndexedToolCall(chunk StreamChunk) {
+	pos, ok := a.indexed[chunk.Index]
+	if !ok {
+		pos = len(a.calls)
+		a.indexed[chunk.Index] = pos
+		a.calls = append(a.calls, ToolCall{})
+	}
+	existing := &a.calls[pos]
+	if chunk.Call != nil {
+		if chunk.Call.ID != "" {
+			existing.ID = chunk.Call.ID
+		}
+		if chunk.Call.Name != "" {
+			existing.Name = chunk.Call.Name
+		}
+	}
+	switch chunk.Type {
+	case StreamChunkToolCallDelta:
+		existing.ArgumentsJSON += chunk.Delta
+	case StreamChunkToolCallEnd:
+		if chunk.Call != nil {
+			existing.ArgumentsJSON = chunk.Call.ArgumentsJSON
+		}
+	}
+}
+
+func (a *StreamAccumulator) addToolCall(chunk StreamChunk) {
+	if chunk.Call == nil {
+		return
+	}
+	call := *chunk.Call
+	idx := a.lastCallIndex(call.ID)
+	if idx < 0 {
+		if call.ArgumentsJSON == "" && chunk.Delta != "" {
+			call.ArgumentsJSON = chunk.Delta
+		}
+		a.calls = append(a.calls, call)
+		return
+	}
+	existing := &a.calls[idx]
+	switch {
+	case call.ArgumentsJSON == "" && chunk.Delta != "":
+		existing.ArgumentsJSON += chunk.Delta
+	case strings.HasPrefix(call.ArgumentsJSON, existing.ArgumentsJSON):
+		existing.ArgumentsJSON = call.ArgumentsJSON
+	case call.ArgumentsJSON == "":
+	default:
+		a.calls = append(a.calls, call)
+		return
+	}
+	if call.Name != "" {
+		existing.Name = call.Name
+	}
+}
+
+func (a *StreamAccumulator) lastCallIndex(id string) int {
+	for idx := len(a.calls) - 1; idx >= 0; idx-- {
+		if a.calls[idx].ID == id {
+			return idx
+		}
+	}
+	return -1
+}
+
+func (a *StreamAccumulator) Output() GenerateOutput {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	output := GenerateOutput{
+		Text:         a.text.String(),
+		FinishReason: a.finishReason,
+	}
+	if len(a.calls) > 0 {
+		output.ToolCalls = append([]ToolCall(nil), a.calls...)
+	}
+	if len(a.ratings) > 0 {
+		output.SafetyRatings = append([]SafetyRating(nil), a.ratings...)
+	}
+	if a.usage != nil {
+		usage := *a.usage
+		output.Usage = &usage
+	}
+	if a.cost != nil {
+		cost := *a.cost
+		output.Cost = &cost
+	}
+	return output
+}
+
+// Err reports the first error chunk seen on the stream as a KitError.
+func (a *StreamAccumulator) Err() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.err == nil {
+		return nil
+	}
+	return chunkErrorToKitError(a.err)
+}
+
+// Tee forwards every chunk from stream while accumulating it. Done is closed
+// once the source stream is drained or ctx is cancelled.
+func (a *StreamAccumulator) Tee(ctx context.Context, stream <-chan StreamChunk) <-chan StreamChunk {
+	out := make(chan StreamChunk)
+	go func() {
+		defer a.finish()
+		defer close(out)
+		for chunk := range stream {
+			a.Add(chunk)
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+func (a *StreamAccumulator) Done() <-chan struct{} {
+	return a.done
+}
+
+func (a *StreamAccumulator) finish() {
+	a.doneOnce.Do(func() { close(a.done) })
+}
+
+// CollectStream drains stream and returns the accumulated output.
+func CollectStream(ctx context.Context, stream <-chan StreamChunk) (GenerateOutput, error) {
+	acc := NewStreamAccumulator()
+	for {
+		select {
+		case chunk, ok := <-stream:
+			if !ok {
+				acc.finish()
+				return acc.Output(), acc.Err()
+			}
+			acc.Add(chunk)
+		case <-ctx.Done():
+			return acc.Output(), ctx.Err()
+		}
+	}
+}
+
+func chunkErrorToKitError(chunkErr *ChunkError) *KitError {
+	kind := ErrorKind(chunkErr.Kind)
+	if kind == "" {
+		kind = ErrorUnknown
+	}
+	return &KitError{
+		Kind:         kind,
+		Message:      chunkErr.Message,
+		UpstreamCode: chunkErr.UpstreamCode,
+		RequestID:    chunkErr.RequestID,
+	}
+}
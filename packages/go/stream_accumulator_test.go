@@ -0,0 +1,161 @@
+package aikit
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func sseHTTPResponse(events ...string) *http.Response {
+	var body strings.Builder
+	for _, event := range events {
+		body.WriteString(event)
+		body.WriteString("\n\n")
+	}
+	return &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+		Body:       io.NopCloser(bytes.NewBufferString(body.String())),
+	}
+}
+
+func newSSEKit(t *testing.T, stream, generate map[string]string) *Kit {
+	t.Helper()
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		raw, _ := io.ReadAll(req.Body)
+		streaming := bytes.Contains(raw, []byte(`"stream":true`)) || strings.Contains(req.URL.Path, "streamGenerateContent")
+		bodies := generate
+		if streaming {
+			bodies = stream
+		}
+		for host, body := range bodies {
+			if strings.Contains(req.URL.Host, host) {
+				if streaming {
+					return sseHTTPResponse(strings.Split(body, "\n\n")...), nil
+				}
+				return jsonHTTPResponse(body), nil
+			}
+		}
+		t.Fatalf("unexpected request: %s", req.URL)
+		return nil, nil
+	})}
+	kit, err := New(Config{
+		OpenAI:     &OpenAIConfig{APIKey: "openai"},
+		Anthropic:  &AnthropicConfig{APIKey: "anthropic"},
+		Google:     &GoogleConfig{APIKey: "google"},
+		HTTPClient: client,
+	})
+	if err != nil {
+		t.Fatalf("new kit: %v", err)
+	}
+	return kit
+}
+
+func TestStreamAccumulatorMatchesGenerate(t *testing.T) {
+	stream := map[string]string{
+		"api.openai.com": strings.Join([]string{
+			`data: {"choices":[{"delta":{"content":"Hel"}}]}`,
+			`data: {"choices":[{"delta":{"content":"lo"}}]}`,
+			`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","function":{"name":"weather","arguments":""}}]}}]}`,
+			`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":"}}]}}]}`,
+			`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]}}]}`,
+			`data: {"choices":[{"delta":{"tool_calls":[{"index":1,"id":"call_2","function":{"name":"weather","arguments":"{\"city\":\"Rome\"}"}}]}}]}`,
+			`data: {"choices":[{"finish_reason":"tool_calls","delta":{}}],"usage":{"prompt_tokens":7,"completion_tokens":9,"total_tokens":16}}`,
+			`data: [DONE]`,
+		}, "\n\n"),
+		"api.anthropic.com": strings.Join([]string{
+			"event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"usage\":{\"input_tokens\":7,\"output_tokens\":1}}}",
+			"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}",
+			"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"Hello\"}}",
+			"event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":0}",
+			"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":1,\"content_block\":{\"type\":\"tool_use\",\"id\":\"toolu_1\",\"name\":\"weather\",\"input\":{}}}",
+			"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":1,\"delta\":{\"type\":\"input_json_delta\",\"partial_json\":\"{\\\"city\\\":\"}}",
+			"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":1,\"delta\":{\"type\":\"input_json_delta\",\"partial_json\":\"\\\"Paris\\\"}\"}}",
+			"event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":1}",
+			"event: message_delta\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"tool_use\"},\"usage\":{\"output_tokens\":9}}",
+			"event: message_stop\ndata: {\"type\":\"message_stop\"}",
+		}, "\n\n"),
+		"generativelanguage.googleapis.com": strings.Join([]string{
+			`data: {"candidates":[{"content":{"parts":[{"text":"Hel"}]}}],"usageMetadata":{"promptTokenCount":7,"candidatesTokenCount":1,"totalTokenCount":8}}`,
+			`data: {"candidates":[{"content":{"parts":[{"text":"lo"}]}}],"usageMetadata":{"promptTokenCount":7,"candidatesTokenCount":3,"totalTokenCount":10}}`,
+			`data: {"candidates":[{"content":{"parts":[{"functionCall":{"name":"weather","args":{"city":"Paris"}}}]},"finishReason":"MAX_TOKENS","safetyRatings":[{"category":"HARM_CATEGORY_HARASSMENT","probability":"NEGLIGIBLE"}]}],"usageMetadata":{"promptTokenCount":7,"candidatesTokenCount":9,"totalTokenCount":16}}`,
+		}, "\n\n"),
+	}
+	generate := map[string]string{
+		"api.openai.com":                    `{"choices":[{"finish_reason":"tool_calls","message":{"content":"Hello","tool_calls":[{"id":"call_1","function":{"name":"weather","arguments":"{\"city\":\"Paris\"}"}},{"id":"call_2","function":{"name":"weather","arguments":"{\"city\":\"Rome\"}"}}]}}],"usage":{"prompt_tokens":7,"completion_tokens":9,"total_tokens":16}}`,
+		"api.anthropic.com":                 `{"content":[{"type":"text","text":"Hello"},{"type":"tool_use","id":"toolu_1","name":"weather","input":{"city":"Paris"}}],"stop_reason":"tool_use","usage":{"input_tokens":7,"output_tokens":9}}`,
+		"generativelanguage.googleapis.com": `{"candidates":[{"content":{"parts":[{"text":"Hello"},{"functionCall":{"name":"weather","args":{"city":"Paris"}}}]},"finishReason":"MAX_TOKENS","safetyRatings":[{"category":"HARM_CATEGORY_HARASSMENT","probability":"NEGLIGIBLE"}]}],"usageMetadata":{"promptTokenCount":7,"candidatesTokenCount":9,"totalTokenCount":16}}`,
+	}
+	kit := newSSEKit(t, stream, generate)
+	models := map[Provider]string{
+		ProviderOpenAI:    "gpt-4o-mini",
+		ProviderAnthropic: "claude-sonnet-4-5",
+		ProviderGoogle:    "gemini-2.5-flash",
+	}
+	for _, provider := range []Provider{ProviderOpenAI, ProviderAnthropic, ProviderGoogle} {
+		input := GenerateInput{Provider: provider, Model: models[provider]}
+		expected, err := kit.Generate(context.Background(), input)
+		if err != nil {
+			t.Fatalf("%s generate: %v", provider, err)
+		}
+		expected.Raw = nil
+		if provider == ProviderGoogle && (expected.Cost == nil || len(expected.SafetyRatings) != 1) {
+			t.Fatalf("expected priced gemini output with safety ratings, got %+v", expected)
+		}
+		ch, err := kit.StreamGenerate(context.Background(), input)
+		if err != nil {
+			t.Fatalf("%s stream: %v", provider, err)
+		}
+		acc := NewStreamAccumulator()
+		forwarded := 0
+		for range acc.Tee(context.Background(), ch) {
+			forwarded++
+		}
+		<-acc.Done()
+		if forwarded == 0 || acc.Err() != nil {
+			t.Fatalf("%s: expected forwarded chunks without error, got %d, %v", provider, forwarded, acc.Err())
+		}
+		if got := acc.Output(); !reflect.DeepEqual(got, expected) {
+			t.Fatalf("%s: accumulated output differs\n got: %+v\nwant: %+v", provider, got, expected)
+		}
+	}
+}
+
+func TestStreamAccumulatorGeminiWholeCalls(t *testing.T) {
+	stream := map[string]string{
+		"generativelanguage.googleapis.com": strings.Join([]string{
+			`data: {"candidates":[{"content":{"parts":[{"text":"Checking"}]}}]}`,
+			`data: {"candidates":[{"content":{"parts":[{"functionCall":{"name":"weather","args":{"city":"Paris"}}},{"functionCall":{"name":"weather","args":{"city":"Rome"}}}]},"finishReason":"STOP"}]}`,
+		}, "\n\n"),
+	}
+	kit := newSSEKit(t, stream, nil)
+	ch, err := kit.StreamGenerate(context.Background(), GenerateInput{Provider: ProviderGoogle, Model: "gemini-2.5-flash"})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	output, err := CollectStream(context.Background(), ch)
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	want := []ToolCall{
+		{ID: "weather", Name: "weather", ArgumentsJSON: `{"city":"Paris"}`},
+		{ID: "weather", Name: "weather", ArgumentsJSON: `{"city":"Rome"}`},
+	}
+	if output.Text != "Checking" || !reflect.DeepEqual(output.ToolCalls, want) {
+		t.Fatalf("unexpected gemini output: %+v", output)
+	}
+}
+
+func TestStreamAccumulatorReportsErrors(t *testing.T) {
+	acc := NewStreamAccumulator()
+	acc.Add(StreamChunk{Type: StreamChunkDelta, TextDelta: "partial"})
+	acc.Add(StreamChunk{Type: StreamChunkError, Error: &ChunkError{Kind: string(ErrorProviderRateLimit), Message: "slow down"}})
+	kitErr, ok := acc.Err().(*KitError)
+	if !ok || kitErr.Kind != ErrorProviderRateLimit || acc.Output().Text != "partial" {
+		t.Fatalf("unexpected error state: %v, %+v", acc.Err(), acc.Output())
+	}
+}
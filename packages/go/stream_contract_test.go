@@ -0,0 +1,189 @@
+package aikit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	aikit "github.com/Volpestyle/ai-kit/packages/go"
+	"github.com/Volpestyle/ai-kit/packages/go/testkit"
+)
+
+type sseTransport map[string][]string
+
+func (t sseTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for path, events := range t {
+		if strings.Contains(req.URL.Path, path) {
+			return &http.Response{
+				StatusCode: 200,
+				Header: http.Header{
+					"Content-Type": []string{"text/event-stream"},
+					"X-Request-Id": []string{"req_" + strings.Trim(path, "/:")},
+				},
+				Body:    io.NopCloser(bytes.NewBufferString(strings.Join(events, "\n\n") + "\n\n")),
+				Request: req,
+			}, nil
+		}
+	}
+	return &http.Response{StatusCode: 404, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{}`)), Request: req}, nil
+}
+
+var contractStreams = sseTransport{
+	"/v1/chat/completions": {
+		`data: {"id":"chatcmpl_1","model":"upstream-model","choices":[{"delta":{"content":"Hel"}}]}`,
+		`data: {"choices":[{"delta":{"content":"lo"}}]}`,
+		`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","function":{"name":"weather","arguments":""}}]}}]}`,
+		`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":"}}]}}]}`,
+		`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]}}]}`,
+		`data: {"choices":[{"finish_reason":"tool_calls","delta":{}}]}`,
+		`data: [DONE]`,
+	},
+	"/v1/responses": {
+		"event: response.created\ndata: {\"response\":{\"id\":\"resp_1\",\"model\":\"upstream-model\",\"status\":\"in_progress\"}}",
+		"event: response.output_text.delta\ndata: {\"delta\":{\"text\":\"Hello\"}}",
+		"event: response.tool_call.delta\ndata: {\"tool_call_id\":\"call_1\",\"delta\":{\"name\":\"weather\",\"arguments\":\"{\\\"city\\\":\"}}",
+		"event: response.tool_call.delta\ndata: {\"tool_call_id\":\"call_1\",\"delta\":{\"arguments\":\"\\\"Paris\\\"}\"}}",
+		"event: response.completed\ndata: {\"response\":{\"status\":\"completed\"}}",
+	},
+	"/v1/messages": {
+		"event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_1\",\"model\":\"upstream-model\",\"usage\":{\"input_tokens\":7}}}",
+		"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}",
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"Hello\"}}",
+		"event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":0}",
+		"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":1,\"content_block\":{\"type\":\"tool_use\",\"id\":\"call_1\",\"name\":\"weather\",\"input\":{}}}",
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":1,\"delta\":{\"type\":\"input_json_delta\",\"partial_json\":\"{\\\"city\\\":\"}}",
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":1,\"delta\":{\"type\":\"input_json_delta\",\"partial_json\":\"\\\"Paris\\\"}\"}}",
+		"event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":1}",
+		"event: message_delta\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"tool_use\"},\"usage\":{\"output_tokens\":9}}",
+		"event: message_stop\ndata: {\"type\":\"message_stop\"}",
+	},
+	":streamGenerateContent": {
+		`data: {"responseId":"gem_1","modelVersion":"upstream-model","candidates":[{"content":{"parts":[{"text":"Hello"}]}}]}`,
+		`data: {"candidates":[{"content":{"parts":[{"functionCall":{"name":"weather","args":{"city":"Paris"}}}]},"finishReason":"STOP"}]}`,
+	},
+}
+
+func TestAdaptersFollowStreamContract(t *testing.T) {
+	fixtures := &testkit.FixtureAdapter{ChunkSize: 4}
+	fixtureInput := aikit.GenerateInput{Provider: aikit.ProviderOpenAI, Model: "fixture-model"}
+	fixtures.Fixtures = map[string]testkit.Fixture{
+		testkit.StreamKey(fixtureInput): {Generate: &aikit.GenerateOutput{
+			Text:         "Hello",
+			ToolCalls:    []aikit.ToolCall{{ID: "call_1", Name: "weather", ArgumentsJSON: `{"city":"Paris"}`}},
+			FinishReason: "tool_calls",
+		}},
+	}
+	client := &http.Client{Transport: contractStreams}
+	kit, err := aikit.New(aikit.Config{
+		OpenAI:     &aikit.OpenAIConfig{APIKey: "openai"},
+		Anthropic:  &aikit.AnthropicConfig{APIKey: "anthropic"},
+		Google:     &aikit.GoogleConfig{APIKey: "google"},
+		XAI:        &aikit.XAIConfig{APIKey: "xai"},
+		Ollama:     &aikit.OllamaConfig{},
+		HTTPClient: client,
+	})
+	if err != nil {
+		t.Fatalf("new kit: %v", err)
+	}
+	fixtureKit, err := aikit.New(aikit.Config{Adapters: map[aikit.Provider]aikit.ProviderAdapter{aikit.ProviderOpenAI: fixtures}})
+	if err != nil {
+		t.Fatalf("new fixture kit: %v", err)
+	}
+	jsonSchema := &aikit.ResponseFormat{Type: "json_schema", JsonSchema: &aikit.JsonSchemaFormat{Name: "out", Schema: map[string]interface{}{"type": "object"}}}
+	cases := []struct {
+		name string
+		kit  *aikit.Kit
+		in   aikit.GenerateInput
+	}{
+		{"openai chat", kit, aikit.GenerateInput{Provider: aikit.ProviderOpenAI, Model: "gpt-4o-mini"}},
+		{"openai responses", kit, aikit.GenerateInput{Provider: aikit.ProviderOpenAI, Model: "gpt-4o-mini", ResponseFormat: jsonSchema}},
+		{"anthropic", kit, aikit.GenerateInput{Provider: aikit.ProviderAnthropic, Model: "claude-sonnet-4-5"}},
+		{"gemini", kit, aikit.GenerateInput{Provider: aikit.ProviderGoogle, Model: "gemini-2.5-flash"}},
+		{"xai openai mode", kit, aikit.GenerateInput{Provider: aikit.ProviderXAI, Model: "grok-4"}},
+		{"xai anthropic mode", kit, aikit.GenerateInput{Provider: aikit.ProviderXAI, Model: "grok-4", Metadata: map[string]string{"xai:compatibility": "anthropic"}}},
+		{"ollama", kit, aikit.GenerateInput{Provider: aikit.ProviderOllama, Model: "llama3"}},
+		{"fixture", fixtureKit, fixtureInput},
+	}
+	wantCalls := []aikit.ToolCall{{ID: "call_1", Name: "weather", ArgumentsJSON: `{"city":"Paris"}`}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			stream, err := tc.kit.StreamGenerate(context.Background(), tc.in)
+			if err != nil {
+				t.Fatalf("stream: %v", err)
+			}
+			var chunks []aikit.StreamChunk
+			acc := aikit.NewStreamAccumulator()
+			for chunk := range stream {
+				chunks = append(chunks, chunk)
+				acc.Add(chunk)
+			}
+			if err := testkit.ValidateStreamChunks(chunks); err != nil {
+				t.Fatalf("contract violation: %v\nchunks: %+v", err, chunks)
+			}
+			if tc.name != "fixture" && (chunks[0].Model != "upstream-model" || chunks[0].RequestID == "") {
+				t.Fatalf("message_start missing upstream identifiers: %+v", chunks[0])
+			}
+			if acc.Err() != nil {
+				t.Fatalf("unexpected stream error: %v", acc.Err())
+			}
+			output := acc.Output()
+			calls := output.ToolCalls
+			if tc.name == "gemini" {
+				// Gemini reuses the function name as call ID.
+				calls = []aikit.ToolCall{{ID: "call_1", Name: calls[0].Name, ArgumentsJSON: calls[0].ArgumentsJSON}}
+			}
+			if output.Text != "Hello" || !reflect.DeepEqual(calls, wantCalls) {
+				t.Fatalf("unexpected accumulated output: %+v", output)
+			}
+		})
+	}
+}
+
+func TestValidateStreamChunksRejectsViolations(t *testing.T) {
+	start := aikit.StreamChunk{Type: aikit.StreamChunkMessageStart, Model: "m"}
+	end := aikit.StreamChunk{Type: aikit.StreamChunkMessageEnd}
+	call := &aikit.ToolCall{ID: "c1", Name: "t"}
+	cases := map[string][]aikit.StreamChunk{
+		"missing start": {end},
+		"missing end":   {start},
+		"open tool":     {start, {Type: aikit.StreamChunkToolCallStart, Call: call}, end},
+		"skipped index": {start, {Type: aikit.StreamChunkToolCallStart, Index: 1, Call: call}},
+		"delta mismatch": {start,
+			{Type: aikit.StreamChunkToolCallStart, Call: call},
+			{Type: aikit.StreamChunkToolCallDelta, Call: call, Delta: `{"a"`},
+			{Type: aikit.StreamChunkToolCallEnd, Call: &aikit.ToolCall{ID: "c1", ArgumentsJSON: `{"b":1}`}},
+			end,
+		},
+		"legacy tool chunk": {start, {Type: aikit.StreamChunkToolCall, Call: call}, end},
+		"chunk after end":   {start, end, {Type: aikit.StreamChunkDelta, TextDelta: "x"}},
+	}
+	for name, chunks := range cases {
+		if err := testkit.ValidateStreamChunks(chunks); err == nil {
+			t.Fatalf("%s: expected contract violation", name)
+		}
+	}
+}
+
+func TestStreamChunkEncodesIndexOnToolCallsOnly(t *testing.T) {
+	delta, err := json.Marshal(aikit.StreamChunk{Type: aikit.StreamChunkDelta, TextDelta: "hi"})
+	if err != nil || strings.Contains(string(delta), `"index"`) {
+		t.Fatalf("expected no index on a text delta, got %s %v", delta, err)
+	}
+	start := aikit.StreamChunk{Type: aikit.StreamChunkToolCallStart, Call: &aikit.ToolCall{ID: "call_1", Name: "weather"}}
+	encoded, err := json.Marshal(start)
+	if err != nil || !strings.Contains(string(encoded), `"index":0`) {
+		t.Fatalf("expected the first tool call's index, got %s %v", encoded, err)
+	}
+	var decoded aikit.StreamChunk
+	second := start
+	second.Index = 1
+	encoded, _ = json.Marshal(second)
+	if err := json.Unmarshal(encoded, &decoded); err != nil || !reflect.DeepEqual(decoded, second) {
+		t.Fatalf("round trip: %+v %v", decoded, err)
+	}
+}
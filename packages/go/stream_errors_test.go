@@ -0,0 +1,190 @@
+package aikit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// faultyBody serves data and then fails with err instead of io.EOF.
+type faultyBody struct {
+	data io.Reader
+	err  error
+}
+
+func (b *faultyBody) Read(p []byte) (int, error) {
+	n, err := b.data.Read(p)
+	if err == io.EOF {
+		return n, b.err
+	}
+	return n, err
+}
+
+func (b *faultyBody) Close() error { return nil }
+
+func streamWithBody(t *testing.T, in GenerateInput, body func() io.ReadCloser) []StreamChunk {
+	t.Helper()
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+			Body:       body(),
+		}, nil
+	})}
+	kit, err := New(Config{
+		OpenAI:     &OpenAIConfig{APIKey: "openai"},
+		Anthropic:  &AnthropicConfig{APIKey: "anthropic"},
+		Google:     &GoogleConfig{APIKey: "google"},
+		HTTPClient: client,
+	})
+	if err != nil {
+		t.Fatalf("new kit: %v", err)
+	}
+	stream, err := kit.StreamGenerate(context.Background(), in)
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	var chunks []StreamChunk
+	for chunk := range stream {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+func streamErrorKinds(chunks []StreamChunk) []string {
+	var kinds []string
+	for _, chunk := range chunks {
+		if chunk.Type == StreamChunkError && chunk.Error != nil {
+			kinds = append(kinds, chunk.Error.Kind)
+		}
+	}
+	return kinds
+}
+
+func lastChunkType(chunks []StreamChunk) StreamChunkType {
+	if len(chunks) == 0 {
+		return ""
+	}
+	return chunks[len(chunks)-1].Type
+}
+
+var truncatedStreams = []struct {
+	name   string
+	input  GenerateInput
+	events string
+}{
+	{
+		name:   "openai chat",
+		input:  GenerateInput{Provider: ProviderOpenAI, Model: "gpt-4o-mini"},
+		events: "data: {\"choices\":[{\"delta\":{\"content\":\"Hel\"}}]}\n\n",
+	},
+	{
+		name:   "openai responses",
+		input:  GenerateInput{Provider: ProviderOpenAI, Model: "gpt-4o-mini", ResponseFormat: &ResponseFormat{Type: "json_schema", JsonSchema: &JsonSchemaFormat{Name: "out"}}},
+		events: "event: response.output_text.delta\ndata: {\"delta\":{\"text\":\"Hel\"}}\n\n",
+	},
+	{
+		name:   "anthropic",
+		input:  GenerateInput{Provider: ProviderAnthropic, Model: "claude-sonnet-4-5"},
+		events: "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"Hel\"}}\n\n",
+	},
+	{
+		name:   "gemini",
+		input:  GenerateInput{Provider: ProviderGoogle, Model: "gemini-2.5-flash"},
+		events: "data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"Hel\"}]}}]}\n\n",
+	},
+}
+
+func TestStreamReadErrorsSurfaceAsInterrupted(t *testing.T) {
+	for _, tc := range truncatedStreams {
+		chunks := streamWithBody(t, tc.input, func() io.ReadCloser {
+			return &faultyBody{data: strings.NewReader(tc.events), err: errors.New("connection reset by peer")}
+		})
+		kinds := streamErrorKinds(chunks)
+		if len(kinds) != 1 || kinds[0] != string(ErrorStreamInterrupted) || lastChunkType(chunks) != StreamChunkError {
+			t.Fatalf("%s: expected a trailing stream_interrupted error, got %+v", tc.name, chunks)
+		}
+		output, err := CollectStream(context.Background(), streamFromSlice(chunks))
+		if output.Text != "Hel" || !strings.Contains(err.Error(), "connection reset") {
+			t.Fatalf("%s: expected partial text and read error, got %q, %v", tc.name, output.Text, err)
+		}
+	}
+}
+
+func TestStreamWithoutTerminalEventIsIncomplete(t *testing.T) {
+	for _, tc := range truncatedStreams {
+		chunks := streamWithBody(t, tc.input, func() io.ReadCloser {
+			return io.NopCloser(strings.NewReader(tc.events))
+		})
+		kinds := streamErrorKinds(chunks)
+		if len(kinds) != 1 || kinds[0] != string(ErrorStreamIncomplete) || lastChunkType(chunks) != StreamChunkError {
+			t.Fatalf("%s: expected a trailing stream_incomplete error, got %+v", tc.name, chunks)
+		}
+		for _, chunk := range chunks {
+			if chunk.Type == StreamChunkMessageEnd {
+				t.Fatalf("%s: truncated stream must not report message_end", tc.name)
+			}
+		}
+	}
+}
+
+func TestStreamUndecodableEventsAreReported(t *testing.T) {
+	events := strings.Join([]string{
+		`data: {"choices":[{"delta":{"content":"Hi"}}]}`,
+		`data: {"choices":[{"delta":`,
+		`data: {"choices":[{"finish_reason":"stop","delta":{}}]}`,
+		`data: [DONE]`,
+	}, "\n\n") + "\n\n"
+	chunks := streamWithBody(t, GenerateInput{Provider: ProviderOpenAI, Model: "gpt-4o-mini"}, func() io.ReadCloser {
+		return io.NopCloser(strings.NewReader(events))
+	})
+	kinds := streamErrorKinds(chunks)
+	if len(kinds) != 1 || kinds[0] != string(ErrorStreamDecode) || lastChunkType(chunks) != StreamChunkMessageEnd {
+		t.Fatalf("expected a decode error followed by message_end, got %+v", chunks)
+	}
+}
+
+func TestStreamUpstreamErrorEvents(t *testing.T) {
+	cases := []struct {
+		input  GenerateInput
+		events string
+		kind   ErrorKind
+	}{
+		{
+			input:  GenerateInput{Provider: ProviderAnthropic, Model: "claude-sonnet-4-5"},
+			events: "event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n",
+			kind:   ErrorProviderUnavailable,
+		},
+		{
+			input:  GenerateInput{Provider: ProviderOpenAI, Model: "gpt-4o-mini"},
+			events: "data: {\"error\":{\"message\":\"Rate limit reached\",\"type\":\"rate_limit_error\",\"code\":\"rate_limit_exceeded\"}}\n\n",
+			kind:   ErrorProviderRateLimit,
+		},
+		{
+			input:  GenerateInput{Provider: ProviderGoogle, Model: "gemini-2.5-flash"},
+			events: "data: {\"promptFeedback\":{\"blockReason\":\"SAFETY\",\"safetyRatings\":[{\"category\":\"HARM_CATEGORY_DANGEROUS_CONTENT\",\"probability\":\"HIGH\",\"blocked\":true}]}}\n\n",
+			kind:   ErrorValidation,
+		},
+	}
+	for _, tc := range cases {
+		chunks := streamWithBody(t, tc.input, func() io.ReadCloser {
+			return io.NopCloser(strings.NewReader(tc.events))
+		})
+		kinds := streamErrorKinds(chunks)
+		if len(kinds) != 1 || kinds[0] != string(tc.kind) {
+			t.Fatalf("%s: expected a single %s error, got %+v", tc.input.Provider, tc.kind, chunks)
+		}
+	}
+}
+
+func streamFromSlice(chunks []StreamChunk) <-chan StreamChunk {
+	ch := make(chan StreamChunk, len(chunks))
+	for _, chunk := range chunks {
+		ch <- chunk
+	}
+	close(ch)
+	return ch
+}
@@ -0,0 +1,269 @@
+package aikit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// streamChunkFields is StreamChunk without its methods, for the wire form.
+type streamChunkFields StreamChunk
+
+type streamChunkJSON struct {
+	streamChunkFields
+	Index *int `json:"index,omitempty"`
+}
+
+// MarshalJSON writes index on tool call chunks only, so other chunks keep
+// the shape they had before tool calls were indexed.
+func (c StreamChunk) MarshalJSON() ([]byte, error) {
+	wire := streamChunkJSON{streamChunkFields: streamChunkFields(c)}
+	if isToolCallChunk(c.Type) {
+		wire.Index = &c.Index
+	}
+	return json.Marshal(wire)
+}
+
+func (c *StreamChunk) UnmarshalJSON(data []byte) error {
+	var wire streamChunkJSON
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	*c = StreamChunk(wire.streamChunkFields)
+	if wire.Index != nil {
+		c.Index = *wire.Index
+	}
+	return nil
+}
+
+func isToolCallChunk(kind StreamChunkType) bool {
+	switch kind {
+	case StreamChunkToolCallStart, StreamChunkToolCallDelta, StreamChunkToolCallEnd:
+		return true
+	}
+	return false
+}
+
+// streamEmitter enforces the normalized stream contract for adapters:
+//
+//   - message_start is always the first chunk and carries the model and
+//     request ID;
+//   - each tool call gets exactly one tool_call_start, zero or more
+//     tool_call_delta chunks with argument fragments, and exactly one
+//     tool_call_end carrying the final arguments, all tagged with the call's
+//     index in the message;
+//   - message_end is the last chunk and closes any tool calls still open;
+//   - a stream that stops before its terminal upstream event ends with an
+//     error chunk instead of message_end.
+//
+// Sends give up once ctx is done, so an abandoned stream never blocks the
+// producing goroutine.
+type streamEmitter struct {
+	ctx       context.Context
+	ch        chan<- StreamChunk
+	provider  Provider
+	model     string
+	requestID string
+	started   bool
+	finished  bool
+	cancelled bool
+	tools     map[string]*streamToolState
+	order     []string
+}
+
+type streamToolState struct {
+	index int
+	call  ToolCall
+	ended bool
+}
+
+func newStreamEmitter(ctx context.Context, ch chan<- StreamChunk, provider Provider, model string, resp *http.Response) *streamEmitter {
+	return &streamEmitter{
+		ctx:       ctx,
+		ch:        ch,
+		provider:  provider,
+		model:     model,
+		requestID: requestIDFromResponse(resp),
+		tools:     map[string]*streamToolState{},
+	}
+}
+
+// start emits message_start, preferring upstream identifiers when known.
+func (e *streamEmitter) start(model, requestID string) {
+	if e.started {
+		return
+	}
+	if model != "" {
+		e.model = model
+	}
+	if e.requestID == "" {
+		e.requestID = requestID
+	}
+	e.started = true
+	e.emit(StreamChunk{Type: StreamChunkMessageStart, Model: e.model, RequestID: e.requestID})
+}
+
+func (e *streamEmitter) send(chunk StreamChunk) {
+	e.start("", "")
+	e.emit(chunk)
+}
+
+func (e *streamEmitter) emit(chunk StreamChunk) {
+	if e.cancelled {
+		return
+	}
+	select {
+	case e.ch <- chunk:
+	case <-e.ctx.Done():
+		e.cancelled = true
+	}
+}
+
+func (e *streamEmitter) text(delta string) {
+	if delta == "" {
+		return
+	}
+	e.send(StreamChunk{Type: StreamChunkDelta, TextDelta: delta})
+}
+
+func (e *streamEmitter) toolStart(key, id, name string) *streamToolState {
+	if state, ok := e.tools[key]; ok {
+		if state.call.ID == "" {
+			state.call.ID = id
+		}
+		if state.call.Name == "" {
+			state.call.Name = name
+		}
+		return state
+	}
+	state := &streamToolState{index: len(e.order), call: ToolCall{ID: id, Name: name}}
+	e.tools[key] = state
+	e.order = append(e.order, key)
+	e.send(StreamChunk{
+		Type:  StreamChunkToolCallStart,
+		Index: state.index,
+		Call:  &ToolCall{ID: id, Name: name},
+	})
+	return state
+}
+
+func (e *streamEmitter) toolDelta(key, fragment string) {
+	state, ok := e.tools[key]
+	if !ok || state.ended || fragment == "" {
+		return
+	}
+	state.call.ArgumentsJSON += fragment
+	e.send(StreamChunk{
+		Type:  StreamChunkToolCallDelta,
+		Index: state.index,
+		Call:  &ToolCall{ID: state.call.ID, Name: state.call.Name},
+		Delta: fragment,
+	})
+}
+
+// toolArguments reconciles producers that report cumulative arguments by
+// emitting only the new suffix as a delta.
+func (e *streamEmitter) toolArguments(key, id, name, arguments string) {
+	state := e.toolStart(key, id, name)
+	if state.ended {
+		return
+	}
+	previous := state.call.ArgumentsJSON
+	if len(arguments) > len(previous) && arguments[:len(previous)] == previous {
+		e.toolDelta(key, arguments[len(previous):])
+		return
+	}
+	if arguments != previous {
+		state.call.ArgumentsJSON = arguments
+	}
+}
+
+func (e *streamEmitter) toolEnd(key string) {
+	state, ok := e.tools[key]
+	if !ok || state.ended {
+		return
+	}
+	state.ended = true
+	call := state.call
+	if call.ArgumentsJSON == "" {
+		call.ArgumentsJSON = "{}"
+	}
+	e.send(StreamChunk{Type: StreamChunkToolCallEnd, Index: state.index, Call: &call})
+}
+
+func (e *streamEmitter) toolCall(key string, call ToolCall) {
+	e.toolStart(key, call.ID, call.Name)
+	e.toolDelta(key, call.ArgumentsJSON)
+	e.toolEnd(key)
+}
+
+func (e *streamEmitter) endTools() {
+	for _, key := range e.order {
+		e.toolEnd(key)
+	}
+}
+
+func (e *streamEmitter) fail(chunkErr *ChunkError) {
+	e.send(StreamChunk{Type: StreamChunkError, Error: chunkErr})
+}
+
+// abort reports an upstream error event that terminates the stream.
+func (e *streamEmitter) abort(chunkErr *ChunkError) {
+	if chunkErr.RequestID == "" {
+		chunkErr.RequestID = e.requestID
+	}
+	e.finished = true
+	e.fail(chunkErr)
+}
+
+func (e *streamEmitter) failKind(kind ErrorKind, message string) {
+	e.fail(&ChunkError{Kind: string(kind), Message: message, RequestID: e.requestID})
+}
+
+// decodeError reports an event payload that could not be parsed; the stream
+// keeps going since later events may still be usable.
+func (e *streamEmitter) decodeError(err error) {
+	e.failKind(ErrorStreamDecode, fmt.Sprintf("%s stream: undecodable event: %v", e.provider, err))
+}
+
+// incomplete reports a stream that stopped before its terminal event, either
+// because reading the body failed (cause) or because it ended early.
+func (e *streamEmitter) incomplete(cause error) {
+	if e.finished {
+		return
+	}
+	e.finished = true
+	if cause != nil {
+		e.failKind(ErrorStreamInterrupted, fmt.Sprintf("%s stream interrupted: %v", e.provider, cause))
+		return
+	}
+	e.failKind(ErrorStreamIncomplete, fmt.Sprintf("%s stream ended before a terminal event", e.provider))
+}
+
+func (e *streamEmitter) end(finishReason string, usage *Usage) {
+	e.finish(StreamChunk{FinishReason: finishReason, Usage: usage})
+}
+
+// finish emits message_end from a template carrying the terminal fields.
+func (e *streamEmitter) finish(chunk StreamChunk) {
+	if e.finished {
+		return
+	}
+	e.endTools()
+	e.finished = true
+	chunk.Type = StreamChunkMessageEnd
+	e.send(chunk)
+}
+
+func requestIDFromResponse(resp *http.Response) string {
+	if resp == nil {
+		return ""
+	}
+	for _, header := range []string{"x-request-id", "request-id"} {
+		if value := resp.Header.Get(header); value != "" {
+			return value
+		}
+	}
+	return ""
+}
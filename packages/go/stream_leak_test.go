@@ -0,0 +1,239 @@
+package aikit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// endlessBody repeats one SSE event until closed, like an upstream that keeps
+// streaming after the consumer has gone away.
+type endlessBody struct {
+	event   string
+	pending string
+	closed  chan struct{}
+	once    sync.Once
+}
+
+func newEndlessBody(event string) *endlessBody {
+	return &endlessBody{event: event + "\n\n", closed: make(chan struct{})}
+}
+
+func (b *endlessBody) Read(p []byte) (int, error) {
+	if b.pending == "" {
+		select {
+		case <-b.closed:
+			return 0, errors.New("read on closed body")
+		case <-time.After(time.Millisecond):
+		}
+		b.pending = b.event
+	}
+	n := copy(p, b.pending)
+	b.pending = b.pending[n:]
+	return n, nil
+}
+
+func (b *endlessBody) Close() error {
+	b.once.Do(func() { close(b.closed) })
+	return nil
+}
+
+// goroutineStacks returns the stacks of goroutines running package code,
+// keyed by goroutine header, excluding test runners.
+func goroutineStacks() map[string]string {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	stacks := map[string]string{}
+	for _, stack := range strings.Split(string(buf), "\n\n") {
+		if !strings.Contains(stack, "ai-kit/packages/go.") || strings.Contains(stack, "testing.tRunner") {
+			continue
+		}
+		header := stack
+		if idx := strings.Index(stack, " ["); idx >= 0 {
+			header = stack[:idx]
+		}
+		stacks[header] = stack
+	}
+	return stacks
+}
+
+// verifyNoLeaks fails the test if goroutines started after before are still
+// running package code once the deadline passes.
+func verifyNoLeaks(t *testing.T, before map[string]string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		var leaked []string
+		for header, stack := range goroutineStacks() {
+			if _, ok := before[header]; !ok {
+				leaked = append(leaked, stack)
+			}
+		}
+		if len(leaked) == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("leaked goroutines:\n%s", strings.Join(leaked, "\n\n"))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+var endlessStreams = []struct {
+	name  string
+	path  string
+	input GenerateInput
+	event string
+}{
+	{"openai chat", "/v1/chat/completions", GenerateInput{Provider: ProviderOpenAI, Model: "gpt-4o-mini"},
+		`data: {"choices":[{"delta":{"content":"tick"}}]}`},
+	{"openai responses", "/v1/responses", GenerateInput{Provider: ProviderOpenAI, Model: "gpt-4o-mini", ResponseFormat: &ResponseFormat{Type: "json_schema", JsonSchema: &JsonSchemaFormat{Name: "out"}}},
+		"event: response.output_text.delta\ndata: {\"delta\":{\"text\":\"tick\"}}"},
+	{"anthropic", "/v1/messages", GenerateInput{Provider: ProviderAnthropic, Model: "claude-sonnet-4-5"},
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"tick\"}}"},
+	{"gemini", ":streamGenerateContent", GenerateInput{Provider: ProviderGoogle, Model: "gemini-2.5-flash"},
+		`data: {"candidates":[{"content":{"parts":[{"text":"tick"}]}}]}`},
+	{"xai", "/v1/chat/completions", GenerateInput{Provider: ProviderXAI, Model: "grok-4"},
+		`data: {"choices":[{"delta":{"content":"tick"}}]}`},
+	{"xai anthropic mode", "/v1/messages", GenerateInput{Provider: ProviderXAI, Model: "grok-4", Metadata: map[string]string{xaiCompatibilityKey: "anthropic"}},
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"tick\"}}"},
+	{"ollama", "/v1/chat/completions", GenerateInput{Provider: ProviderOllama, Model: "llama3"},
+		`data: {"choices":[{"delta":{"content":"tick"}}]}`},
+}
+
+func newEndlessKit(t *testing.T, path, event string) (*Kit, chan *endlessBody) {
+	t.Helper()
+	bodies := make(chan *endlessBody, 1)
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if !strings.Contains(req.URL.Path, path) {
+			t.Errorf("unexpected request: %s", req.URL)
+		}
+		body := newEndlessBody(event)
+		bodies <- body
+		return &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+			Body:       body,
+		}, nil
+	})}
+	kit, err := New(Config{
+		OpenAI:     &OpenAIConfig{APIKey: "openai"},
+		Anthropic:  &AnthropicConfig{APIKey: "anthropic"},
+		Google:     &GoogleConfig{APIKey: "google"},
+		XAI:        &XAIConfig{APIKey: "xai"},
+		Ollama:     &OllamaConfig{},
+		HTTPClient: client,
+	})
+	if err != nil {
+		t.Fatalf("new kit: %v", err)
+	}
+	return kit, bodies
+}
+
+func waitClosed(t *testing.T, name string, body *endlessBody) {
+	t.Helper()
+	select {
+	case <-body.closed:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("%s: upstream body was not closed after cancellation", name)
+	}
+}
+
+func TestAbandonedStreamsDoNotLeak(t *testing.T) {
+	consumers := map[string]func(ctx context.Context, stream <-chan StreamChunk) <-chan StreamChunk{
+		"direct": func(ctx context.Context, stream <-chan StreamChunk) <-chan StreamChunk {
+			return stream
+		},
+		"tee": func(ctx context.Context, stream <-chan StreamChunk) <-chan StreamChunk {
+			return NewStreamAccumulator().Tee(ctx, stream)
+		},
+		"partial json": func(ctx context.Context, stream <-chan StreamChunk) <-chan StreamChunk {
+			out := make(chan StreamChunk)
+			go func() {
+				defer close(out)
+				for chunk := range StreamPartialJSON(ctx, stream) {
+					select {
+					case out <- chunk.StreamChunk:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+			return out
+		},
+	}
+	for _, tc := range endlessStreams {
+		for consumerName, consume := range consumers {
+			name := tc.name + "/" + consumerName
+			before := goroutineStacks()
+			kit, bodies := newEndlessKit(t, tc.path, tc.event)
+			ctx, cancel := context.WithCancel(context.Background())
+			stream, err := kit.StreamGenerate(ctx, tc.input)
+			if err != nil {
+				cancel()
+				t.Fatalf("%s: stream: %v", name, err)
+			}
+			chunks := consume(ctx, stream)
+			for i := 0; i < 3; i++ {
+				if _, ok := <-chunks; !ok {
+					t.Fatalf("%s: stream closed early", name)
+				}
+			}
+			// Stop reading without draining, as a disconnected client would.
+			cancel()
+			waitClosed(t, name, <-bodies)
+			verifyNoLeaks(t, before)
+		}
+	}
+}
+
+func TestStreamCancelledBeforeFirstReadDoesNotLeak(t *testing.T) {
+	for _, tc := range endlessStreams {
+		before := goroutineStacks()
+		kit, bodies := newEndlessKit(t, tc.path, tc.event)
+		ctx, cancel := context.WithCancel(context.Background())
+		if _, err := kit.StreamGenerate(ctx, tc.input); err != nil {
+			cancel()
+			t.Fatalf("%s: stream: %v", tc.name, err)
+		}
+		time.Sleep(5 * time.Millisecond)
+		cancel()
+		waitClosed(t, tc.name, <-bodies)
+		verifyNoLeaks(t, before)
+	}
+}
+
+func TestCancelledStreamStillClosesChannel(t *testing.T) {
+	tc := endlessStreams[0]
+	kit, _ := newEndlessKit(t, tc.path, tc.event)
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := kit.StreamGenerate(ctx, tc.input)
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	<-stream
+	cancel()
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case _, ok := <-stream:
+			if !ok {
+				return
+			}
+		case <-deadline:
+			t.Fatalf("stream channel was not closed after cancellation")
+		}
+	}
+}
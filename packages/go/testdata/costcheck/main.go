@@ -0,0 +1,53 @@
+// Command costcheck prints the cost Kit attaches to a canned gpt-4 reply.
+// catalog_test.go builds it with -trimpath and runs it away from the source
+// tree to prove the curated catalog is embedded in binaries.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	aikit "github.com/Volpestyle/ai-kit/packages/go"
+)
+
+type cannedAdapter struct{}
+
+func (cannedAdapter) ListModels(ctx context.Context) ([]aikit.ModelMetadata, error) {
+	return nil, nil
+}
+
+func (cannedAdapter) Generate(ctx context.Context, in aikit.GenerateInput) (aikit.GenerateOutput, error) {
+	return aikit.GenerateOutput{Text: "ok", Usage: &aikit.Usage{InputTokens: 1000, OutputTokens: 1000}}, nil
+}
+
+func (cannedAdapter) GenerateImage(ctx context.Context, in aikit.ImageGenerateInput) (aikit.ImageGenerateOutput, error) {
+	return aikit.ImageGenerateOutput{}, errors.New("unsupported")
+}
+
+func (cannedAdapter) GenerateMesh(ctx context.Context, in aikit.MeshGenerateInput) (aikit.MeshGenerateOutput, error) {
+	return aikit.MeshGenerateOutput{}, errors.New("unsupported")
+}
+
+func (cannedAdapter) Transcribe(ctx context.Context, in aikit.TranscribeInput) (aikit.TranscribeOutput, error) {
+	return aikit.TranscribeOutput{}, errors.New("unsupported")
+}
+
+func (cannedAdapter) Stream(ctx context.Context, in aikit.GenerateInput) (<-chan aikit.StreamChunk, error) {
+	return nil, errors.New("unsupported")
+}
+
+func main() {
+	kit, err := aikit.New(aikit.Config{Adapters: map[aikit.Provider]aikit.ProviderAdapter{aikit.ProviderOpenAI: cannedAdapter{}}})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	out, err := kit.Generate(context.Background(), aikit.GenerateInput{Provider: aikit.ProviderOpenAI, Model: "gpt-4"})
+	if err != nil || out.Cost == nil {
+		fmt.Fprintln(os.Stderr, "no cost:", err)
+		os.Exit(1)
+	}
+	fmt.Println(out.Cost.TotalCostUSD)
+}
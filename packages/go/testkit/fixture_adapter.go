@@ -113,7 +113,7 @@ func (f *FixtureAdapter) Stream(ctx context.Context, in aikit.GenerateInput) (<-
 	if entry.Generate == nil {
 		return nil, fmt.Errorf("fixture for stream is missing (key: %s)", f.keyFor("stream", in.Provider, in.Model, in))
 	}
-	return streamFromChunks(buildStreamChunks(entry.Generate, f.chunkSize())), nil
+	return streamFromChunks(buildStreamChunks(in.Model, entry.Generate, f.chunkSize())), nil
 }
 
 func DefaultFixtureKey(input FixtureKeyInput) string {
@@ -181,19 +181,28 @@ func TranscribeKey(input aikit.TranscribeInput) string {
 	})
 }
 
-func buildStreamChunks(output *aikit.GenerateOutput, chunkSize int) []aikit.StreamChunk {
+func buildStreamChunks(model string, output *aikit.GenerateOutput, chunkSize int) []aikit.StreamChunk {
 	if output == nil {
 		return nil
 	}
-	chunks := make([]aikit.StreamChunk, 0)
+	chunks := []aikit.StreamChunk{{Type: aikit.StreamChunkMessageStart, Model: model}}
 	if output.Text != "" {
 		for _, part := range chunkText(output.Text, chunkSize) {
 			chunks = append(chunks, aikit.StreamChunk{Type: aikit.StreamChunkDelta, TextDelta: part})
 		}
 	}
-	for idx := range output.ToolCalls {
-		call := output.ToolCalls[idx]
-		chunks = append(chunks, aikit.StreamChunk{Type: aikit.StreamChunkToolCall, Call: &call})
+	for idx, call := range output.ToolCalls {
+		header := aikit.ToolCall{ID: call.ID, Name: call.Name}
+		chunks = append(chunks, aikit.StreamChunk{Type: aikit.StreamChunkToolCallStart, Index: idx, Call: &header})
+		for _, part := range chunkText(call.ArgumentsJSON, chunkSize) {
+			delta := header
+			chunks = append(chunks, aikit.StreamChunk{Type: aikit.StreamChunkToolCallDelta, Index: idx, Call: &delta, Delta: part})
+		}
+		final := call
+		if final.ArgumentsJSON == "" {
+			final.ArgumentsJSON = "{}"
+		}
+		chunks = append(chunks, aikit.StreamChunk{Type: aikit.StreamChunkToolCallEnd, Index: idx, Call: &final})
 	}
 	chunks = append(chunks, aikit.StreamChunk{
 		Type:         aikit.StreamChunkMessageEnd,
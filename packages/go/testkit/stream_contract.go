@@ -0,0 +1,99 @@
+package testkit
+
+import (
+	"fmt"
+
+	aikit "github.com/Volpestyle/ai-kit/packages/go"
+)
+
+// ValidateStreamChunks checks a drained stream against the normalized event
+// contract documented on aikit.StreamChunk. Adapters, fixtures and custom
+// ProviderAdapter implementations can share it in their tests.
+func ValidateStreamChunks(chunks []aikit.StreamChunk) error {
+	if len(chunks) == 0 {
+		return fmt.Errorf("stream produced no chunks")
+	}
+	if chunks[0].Type != aikit.StreamChunkMessageStart {
+		return fmt.Errorf("chunk 0: expected %s, got %s", aikit.StreamChunkMessageStart, chunks[0].Type)
+	}
+	if chunks[0].Model == "" {
+		return fmt.Errorf("chunk 0: %s is missing the model", aikit.StreamChunkMessageStart)
+	}
+	type toolCall struct {
+		started   bool
+		ended     bool
+		deltas    string
+		deltaSeen bool
+	}
+	calls := map[int]*toolCall{}
+	nextIndex := 0
+	ended := false
+	failed := false
+	for idx, chunk := range chunks {
+		if chunk.Seq != 0 && chunk.Seq != idx+1 {
+			return fmt.Errorf("chunk %d: expected seq %d, got %d", idx, idx+1, chunk.Seq)
+		}
+		if ended {
+			return fmt.Errorf("chunk %d: %s after %s", idx, chunk.Type, aikit.StreamChunkMessageEnd)
+		}
+		switch chunk.Type {
+		case aikit.StreamChunkMessageStart:
+			if idx != 0 {
+				return fmt.Errorf("chunk %d: duplicate %s", idx, chunk.Type)
+			}
+		case aikit.StreamChunkDelta:
+			if chunk.TextDelta == "" {
+				return fmt.Errorf("chunk %d: empty text delta", idx)
+			}
+		case aikit.StreamChunkToolCallStart:
+			if chunk.Index != nextIndex {
+				return fmt.Errorf("chunk %d: expected tool call index %d, got %d", idx, nextIndex, chunk.Index)
+			}
+			if chunk.Call == nil || (chunk.Call.ID == "" && chunk.Call.Name == "") {
+				return fmt.Errorf("chunk %d: %s without call id or name", idx, chunk.Type)
+			}
+			calls[chunk.Index] = &toolCall{started: true}
+			nextIndex++
+		case aikit.StreamChunkToolCallDelta:
+			call := calls[chunk.Index]
+			if call == nil || call.ended {
+				return fmt.Errorf("chunk %d: %s for tool call %d that is not open", idx, chunk.Type, chunk.Index)
+			}
+			if chunk.Call == nil || chunk.Delta == "" {
+				return fmt.Errorf("chunk %d: %s without call or fragment", idx, chunk.Type)
+			}
+			call.deltas += chunk.Delta
+			call.deltaSeen = true
+		case aikit.StreamChunkToolCallEnd:
+			call := calls[chunk.Index]
+			if call == nil || call.ended {
+				return fmt.Errorf("chunk %d: %s for tool call %d that is not open", idx, chunk.Type, chunk.Index)
+			}
+			if chunk.Call == nil || chunk.Call.ArgumentsJSON == "" {
+				return fmt.Errorf("chunk %d: %s without final arguments", idx, chunk.Type)
+			}
+			if call.deltaSeen && call.deltas != chunk.Call.ArgumentsJSON {
+				return fmt.Errorf("chunk %d: tool call %d deltas %q do not match final arguments %q", idx, chunk.Index, call.deltas, chunk.Call.ArgumentsJSON)
+			}
+			call.ended = true
+		case aikit.StreamChunkMessageEnd:
+			for index, call := range calls {
+				if !call.ended {
+					return fmt.Errorf("chunk %d: tool call %d still open at %s", idx, index, chunk.Type)
+				}
+			}
+			ended = true
+		case aikit.StreamChunkError:
+			if chunk.Error == nil {
+				return fmt.Errorf("chunk %d: error chunk without error", idx)
+			}
+			failed = true
+		default:
+			return fmt.Errorf("chunk %d: unexpected chunk type %q", idx, chunk.Type)
+		}
+	}
+	if !ended && !failed {
+		return fmt.Errorf("stream ended without %s", aikit.StreamChunkMessageEnd)
+	}
+	return nil
+}
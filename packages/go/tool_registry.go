@@ -0,0 +1,113 @@
+package aikit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+type ToolHandler func(ctx context.Context, argumentsJSON string) (string, error)
+
+type RegisteredTool struct {
+	Definition ToolDefinition
+	Handler    ToolHandler
+}
+
+type ToolRegistry struct {
+	mu    sync.RWMutex
+	tools map[string]RegisteredTool
+	order []string
+}
+
+func NewToolRegistry() *ToolRegistry {
+	return &ToolRegistry{tools: make(map[string]RegisteredTool)}
+}
+
+func (r *ToolRegistry) Register(def ToolDefinition, handler ToolHandler) error {
+	name := strings.TrimSpace(def.Name)
+	if name == "" {
+		return &KitError{Kind: ErrorValidation, Message: "tool name is required"}
+	}
+	if handler == nil {
+		return &KitError{Kind: ErrorValidation, Message: fmt.Sprintf("tool %s requires a handler", name)}
+	}
+	def.Name = name
+	if def.Parameters == nil {
+		def.Parameters = map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{},
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tools == nil {
+		r.tools = make(map[string]RegisteredTool)
+	}
+	if _, exists := r.tools[name]; exists {
+		return &KitError{Kind: ErrorValidation, Message: fmt.Sprintf("tool %s is already registered", name)}
+	}
+	r.tools[name] = RegisteredTool{Definition: def, Handler: handler}
+	r.order = append(r.order, name)
+	return nil
+}
+
+func (r *ToolRegistry) Lookup(name string) (RegisteredTool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tool, ok := r.tools[name]
+	return tool, ok
+}
+
+func (r *ToolRegistry) Definitions() []ToolDefinition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	defs := make([]ToolDefinition, 0, len(r.order))
+	for _, name := range r.order {
+		defs = append(defs, r.tools[name].Definition)
+	}
+	return defs
+}
+
+// RegisterTypedTool decodes tool arguments into Args and encodes the handler
+// result as JSON (strings are passed through unchanged). When def.Parameters
+// is nil the schema is derived from Args.
+func RegisterTypedTool[Args any, Result any](r *ToolRegistry, def ToolDefinition, fn func(ctx context.Context, args Args) (Result, error)) error {
+	if fn == nil {
+		return r.Register(def, nil)
+	}
+	if def.Parameters == nil {
+		schema, err := JSONSchemaFor[Args](SchemaOptions{})
+		if err != nil {
+			return err
+		}
+		if schema["type"] == "object" {
+			def.Parameters = schema
+		}
+	}
+	return r.Register(def, func(ctx context.Context, argumentsJSON string) (string, error) {
+		var args Args
+		if strings.TrimSpace(argumentsJSON) != "" {
+			if err := json.Unmarshal([]byte(argumentsJSON), &args); err != nil {
+				return "", &KitError{
+					Kind:    ErrorValidation,
+					Message: fmt.Sprintf("invalid arguments for tool %s: %v", def.Name, err),
+					Cause:   err,
+				}
+			}
+		}
+		result, err := fn(ctx, args)
+		if err != nil {
+			return "", err
+		}
+		if text, ok := any(result).(string); ok {
+			return text, nil
+		}
+		data, err := json.Marshal(result)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	})
+}
@@ -0,0 +1,234 @@
+package aikit
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// TransportConfig tunes the HTTP connections one provider uses. Settings
+// that change the connection itself (the connect, response-header and idle
+// timeouts, ProxyURL, CAFile and MaxConnsPerHost) clone the transport of
+// Config.HTTPClient, which must then be nil or an *http.Transport.
+type TransportConfig struct {
+	ConnectTimeout        time.Duration
+	ResponseHeaderTimeout time.Duration
+	IdleConnTimeout       time.Duration
+	// StreamTimeout bounds a whole streamed response in place of the
+	// provider's Timeout. Zero leaves streams bounded only by the caller's
+	// context and StreamIdleTimeout.
+	StreamTimeout time.Duration
+	// StreamIdleTimeout aborts a stream with stream_interrupted when no
+	// bytes arrive for this long.
+	StreamIdleTimeout time.Duration
+	// ProxyURL replaces the HTTP_PROXY/HTTPS_PROXY environment settings.
+	ProxyURL string
+	// CAFile is a PEM bundle trusted in addition to the system roots.
+	CAFile          string
+	MaxConnsPerHost int
+	// Headers are sent with every request unless the adapter sets them.
+	Headers map[string]string
+}
+
+func (t TransportConfig) tunesConnection() bool {
+	return t.ConnectTimeout > 0 || t.ResponseHeaderTimeout > 0 || t.IdleConnTimeout > 0 ||
+		t.ProxyURL != "" || t.CAFile != "" || t.MaxConnsPerHost > 0
+}
+
+func (t TransportConfig) validate(label string, problems *configProblems) {
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"connectTimeout", t.ConnectTimeout},
+		{"responseHeaderTimeout", t.ResponseHeaderTimeout},
+		{"idleConnTimeout", t.IdleConnTimeout},
+		{"streamTimeout", t.StreamTimeout},
+		{"streamIdleTimeout", t.StreamIdleTimeout},
+	}
+	for _, d := range durations {
+		if d.value < 0 {
+			problems.add("%s transport %s must not be negative", label, d.name)
+		}
+	}
+	if t.MaxConnsPerHost < 0 {
+		problems.add("%s transport maxConnsPerHost must not be negative", label)
+	}
+	if t.ProxyURL != "" {
+		if parsed, err := url.Parse(t.ProxyURL); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			problems.add("%s transport proxyURL %q is not an absolute URL", label, t.ProxyURL)
+		}
+	}
+}
+
+// newProviderClient derives the client one provider uses from the shared
+// base client. It returns base itself when nothing is tuned.
+func newProviderClient(base *http.Client, timeout time.Duration, cfg TransportConfig) (*http.Client, error) {
+	if timeout <= 0 && !cfg.tunesConnection() && cfg.StreamTimeout <= 0 && cfg.StreamIdleTimeout <= 0 && len(cfg.Headers) == 0 {
+		return base, nil
+	}
+	next := base.Transport
+	if cfg.tunesConnection() {
+		tuned, err := tuneTransport(base.Transport, cfg)
+		if err != nil {
+			return nil, err
+		}
+		next = tuned
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	client := *base
+	client.Transport = &providerTransport{
+		next:              next,
+		headers:           cfg.Headers,
+		timeout:           timeout,
+		streamTimeout:     cfg.StreamTimeout,
+		streamIdleTimeout: cfg.StreamIdleTimeout,
+	}
+	return &client, nil
+}
+
+func tuneTransport(base http.RoundTripper, cfg TransportConfig) (*http.Transport, error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	httpTransport, ok := base.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("transport settings need an *http.Transport, HTTPClient uses %T", base)
+	}
+	transport := httpTransport.Clone()
+	if cfg.ConnectTimeout > 0 {
+		transport.DialContext = (&net.Dialer{Timeout: cfg.ConnectTimeout, KeepAlive: 30 * time.Second}).DialContext
+	}
+	if cfg.ResponseHeaderTimeout > 0 {
+		transport.ResponseHeaderTimeout = cfg.ResponseHeaderTimeout
+	}
+	if cfg.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = cfg.IdleConnTimeout
+	}
+	if cfg.MaxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = cfg.MaxConnsPerHost
+	}
+	if cfg.ProxyURL != "" {
+		proxy, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("proxyURL: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("caFile: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("caFile %s contains no PEM certificates", cfg.CAFile)
+		}
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+	return transport, nil
+}
+
+type streamRequestKey struct{}
+
+// withStreamRequest marks ctx as carrying a streaming request, so provider
+// transports apply StreamTimeout and StreamIdleTimeout instead of Timeout.
+func withStreamRequest(ctx context.Context) context.Context {
+	return context.WithValue(ctx, streamRequestKey{}, true)
+}
+
+func isStreamRequest(ctx context.Context) bool {
+	stream, _ := ctx.Value(streamRequestKey{}).(bool)
+	return stream
+}
+
+// providerTransport applies per-provider deadlines and headers. Deadlines
+// cover reading the body, so they are enforced with a context that is
+// cancelled when the body is closed rather than with http.Client.Timeout.
+type providerTransport struct {
+	next              http.RoundTripper
+	headers           map[string]string
+	timeout           time.Duration
+	streamTimeout     time.Duration
+	streamIdleTimeout time.Duration
+}
+
+func (t *providerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	stream := isStreamRequest(req.Context())
+	timeout := t.timeout
+	if stream {
+		timeout = t.streamTimeout
+	}
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(req.Context(), timeout)
+	} else {
+		ctx, cancel = context.WithCancel(req.Context())
+	}
+	req = req.Clone(ctx)
+	for name, value := range t.headers {
+		if req.Header.Get(name) == "" {
+			req.Header.Set(name, value)
+		}
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	body := &providerBody{ReadCloser: resp.Body, cancel: cancel}
+	if stream && t.streamIdleTimeout > 0 {
+		body.idle = t.streamIdleTimeout
+		body.timer = time.AfterFunc(body.idle, func() {
+			body.idled.Store(true)
+			cancel()
+		})
+	}
+	resp.Body = body
+	return resp, nil
+}
+
+type providerBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+	idle   time.Duration
+	timer  *time.Timer
+	idled  atomic.Bool
+}
+
+func (b *providerBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if b.timer != nil && n > 0 {
+		b.timer.Reset(b.idle)
+	}
+	if err != nil && err != io.EOF && b.idled.Load() {
+		err = fmt.Errorf("no data received for %s", b.idle)
+	}
+	return n, err
+}
+
+func (b *providerBody) Close() error {
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,176 @@
+package aikit
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// sseServer streams one chat delta, then waits for pause before finishing.
+func sseServer(t *testing.T, pause time.Duration) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"hi\"}}]}\n\n"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-time.After(pause):
+		case <-r.Context().Done():
+			return
+		}
+		w.Write([]byte("data: {\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"stop\"}]}\n\ndata: [DONE]\n\n"))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTransportStreamTimeoutsAreSeparate(t *testing.T) {
+	server := sseServer(t, 150*time.Millisecond)
+	kit, err := New(Config{OpenAI: &OpenAIConfig{APIKey: "k", BaseURL: server.URL, Timeout: 50 * time.Millisecond}})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	stream, err := kit.StreamGenerate(context.Background(), textInput(ProviderOpenAI))
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	out, err := CollectStream(context.Background(), stream)
+	if err != nil || out.Text != "hi" {
+		t.Fatalf("the non-streaming timeout should not cut the stream: %q %v", out.Text, err)
+	}
+}
+
+func TestTransportStreamIdleTimeout(t *testing.T) {
+	server := sseServer(t, time.Second)
+	kit, err := New(Config{OpenAI: &OpenAIConfig{APIKey: "k", BaseURL: server.URL, Transport: TransportConfig{StreamIdleTimeout: 50 * time.Millisecond}}})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	start := time.Now()
+	stream, err := kit.StreamGenerate(context.Background(), textInput(ProviderOpenAI))
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	var last StreamChunk
+	for chunk := range stream {
+		last = chunk
+	}
+	if last.Type != StreamChunkError || last.Error.Kind != string(ErrorStreamInterrupted) || !strings.Contains(last.Error.Message, "no data received for 50ms") {
+		t.Fatalf("expected an idle interruption, got %+v", last)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatalf("idle stream was not aborted promptly")
+	}
+}
+
+func TestTransportHeadersAndProxy(t *testing.T) {
+	var proxied atomic.Bool
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Store(true)
+		if r.Header.Get("X-Team") != "search" || r.Header.Get("Authorization") != "Bearer k" {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"via proxy"},"finish_reason":"stop"}]}`))
+	}))
+	defer proxy.Close()
+	kit, err := New(Config{OpenAI: &OpenAIConfig{
+		APIKey:  "k",
+		BaseURL: "http://upstream.invalid",
+		Transport: TransportConfig{
+			ProxyURL:       proxy.URL,
+			ConnectTimeout: time.Second,
+			Headers:        map[string]string{"X-Team": "search", "Authorization": "Bearer ignored"},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	out, err := kit.Generate(context.Background(), textInput(ProviderOpenAI))
+	if err != nil || out.Text != "via proxy" || !proxied.Load() {
+		t.Fatalf("expected the request to go through the proxy: %q %v", out.Text, err)
+	}
+}
+
+func TestTransportCAFile(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"trusted"},"finish_reason":"stop"}]}`))
+	}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	untrusted, err := New(Config{OpenAI: &OpenAIConfig{APIKey: "k", BaseURL: server.URL}})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	if _, err := untrusted.Generate(context.Background(), textInput(ProviderOpenAI)); err == nil {
+		t.Fatalf("expected an unknown authority error without the CA bundle")
+	}
+	trusted, err := New(Config{OpenAI: &OpenAIConfig{APIKey: "k", BaseURL: server.URL, Transport: TransportConfig{CAFile: caFile, MaxConnsPerHost: 2}}})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	if out, err := trusted.Generate(context.Background(), textInput(ProviderOpenAI)); err != nil || out.Text != "trusted" {
+		t.Fatalf("generate with CA bundle: %q %v", out.Text, err)
+	}
+}
+
+func TestTransportConfigProblems(t *testing.T) {
+	_, err := New(Config{OpenAI: &OpenAIConfig{APIKey: "k", Transport: TransportConfig{StreamIdleTimeout: -time.Second, ProxyURL: "proxy:3128"}}})
+	var cfgErr *ConfigError
+	if !errors.As(err, &cfgErr) || len(cfgErr.Problems) != 2 {
+		t.Fatalf("expected two validation problems, got %v", err)
+	}
+
+	_, err = New(Config{Google: &GoogleConfig{APIKey: "k", Transport: TransportConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")}}})
+	if !errors.As(err, &cfgErr) || !strings.Contains(err.Error(), "google caFile") {
+		t.Fatalf("expected a CA bundle error, got %v", err)
+	}
+
+	_, err = New(Config{
+		HTTPClient: &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) { return nil, nil })},
+		OpenAI:     &OpenAIConfig{APIKey: "k", Transport: TransportConfig{ConnectTimeout: time.Second}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "need an *http.Transport") {
+		t.Fatalf("expected a transport type error, got %v", err)
+	}
+}
+
+func TestLoadConfigTransportSection(t *testing.T) {
+	clearProviderEnv(t)
+	path := writeConfigFile(t, "aikit.yaml", `
+anthropic:
+  apiKey: k
+  transport:
+    connectTimeout: 5s
+    streamIdleTimeout: 30s
+    proxyURL: http://proxy.internal:3128
+    headers:
+      X-Team: search
+`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	transport := cfg.Anthropic.Transport
+	if transport.ConnectTimeout != 5*time.Second || transport.StreamIdleTimeout != 30*time.Second || transport.Headers["X-Team"] != "search" {
+		t.Fatalf("unexpected transport %+v", transport)
+	}
+}
@@ -1,5 +1,7 @@
 package aikit
 
+import "time"
+
 type Provider string
 
 const (
@@ -11,13 +13,20 @@ const (
 	ProviderLocal       Provider = "local"
 )
 
+// ModelCapabilities describes what a model supports. A nil field is unknown:
+// neither the provider listing nor the curated catalog reports it.
 type ModelCapabilities struct {
-	Text             bool `json:"text"`
-	Vision           bool `json:"vision"`
-	Image            bool `json:"image,omitempty"`
-	ToolUse          bool `json:"tool_use"`
-	StructuredOutput bool `json:"structured_output"`
-	Reasoning        bool `json:"reasoning"`
+	Text             *bool `json:"text,omitempty"`
+	Vision           *bool `json:"vision,omitempty"`
+	Image            *bool `json:"image,omitempty"`
+	AudioIn          *bool `json:"audio_in,omitempty"`
+	AudioOut         *bool `json:"audio_out,omitempty"`
+	ToolUse          *bool `json:"tool_use,omitempty"`
+	JSONMode         *bool `json:"json_mode,omitempty"`
+	StructuredOutput *bool `json:"structured_output,omitempty"`
+	Streaming        *bool `json:"streaming,omitempty"`
+	Batch            *bool `json:"batch,omitempty"`
+	Reasoning        *bool `json:"reasoning,omitempty"`
 }
 
 type TokenPrices struct {
@@ -26,16 +35,17 @@ type TokenPrices struct {
 }
 
 type ModelMetadata struct {
-	ID            string            `json:"id"`
-	DisplayName   string            `json:"displayName"`
-	Provider      Provider          `json:"provider"`
-	Family        string            `json:"family,omitempty"`
-	Capabilities  ModelCapabilities `json:"capabilities"`
-	ContextWindow int               `json:"contextWindow,omitempty"`
-	TokenPrices   *TokenPrices      `json:"tokenPrices,omitempty"`
-	Deprecated    bool              `json:"deprecated,omitempty"`
-	InPreview     bool              `json:"inPreview,omitempty"`
-	Available     bool              `json:"available,omitempty"`
+	ID              string            `json:"id"`
+	DisplayName     string            `json:"displayName"`
+	Provider        Provider          `json:"provider"`
+	Family          string            `json:"family,omitempty"`
+	Capabilities    ModelCapabilities `json:"capabilities"`
+	ContextWindow   int               `json:"contextWindow,omitempty"`
+	MaxOutputTokens int               `json:"maxOutputTokens,omitempty"`
+	TokenPrices     *TokenPrices      `json:"tokenPrices,omitempty"`
+	Deprecated      bool              `json:"deprecated,omitempty"`
+	InPreview       bool              `json:"inPreview,omitempty"`
+	Available       bool              `json:"available,omitempty"`
 }
 
 type EntitlementContext struct {
@@ -49,20 +59,22 @@ type EntitlementContext struct {
 	UserID            string   `json:"userId,omitempty"`
 }
 
+// ModelModalities and ModelFeatures leave a field nil, and omit it from
+// JSON, when it is unknown rather than unsupported.
 type ModelModalities struct {
-	Text     bool `json:"text"`
-	Vision   bool `json:"vision,omitempty"`
-	AudioIn  bool `json:"audioIn,omitempty"`
-	AudioOut bool `json:"audioOut,omitempty"`
-	ImageOut bool `json:"imageOut,omitempty"`
+	Text     *bool `json:"text,omitempty"`
+	Vision   *bool `json:"vision,omitempty"`
+	AudioIn  *bool `json:"audioIn,omitempty"`
+	AudioOut *bool `json:"audioOut,omitempty"`
+	ImageOut *bool `json:"imageOut,omitempty"`
 }
 
 type ModelFeatures struct {
-	Tools      bool `json:"tools,omitempty"`
-	JSONMode   bool `json:"jsonMode,omitempty"`
-	JSONSchema bool `json:"jsonSchema,omitempty"`
-	Streaming  bool `json:"streaming,omitempty"`
-	Batch      bool `json:"batch,omitempty"`
+	Tools      *bool `json:"tools,omitempty"`
+	JSONMode   *bool `json:"jsonMode,omitempty"`
+	JSONSchema *bool `json:"jsonSchema,omitempty"`
+	Streaming  *bool `json:"streaming,omitempty"`
+	Batch      *bool `json:"batch,omitempty"`
 }
 
 type ModelLimits struct {
@@ -86,6 +98,9 @@ const (
 	AvailabilityListed   AvailabilityConfidence = "listed"
 	AvailabilityInferred AvailabilityConfidence = "inferred"
 	AvailabilityLearned  AvailabilityConfidence = "learned"
+	// AvailabilityVerified means Kit.VerifyModels probed the model with a
+	// minimal request; LastVerifiedAt is when.
+	AvailabilityVerified AvailabilityConfidence = "verified"
 )
 
 type ModelAvailability struct {
@@ -99,6 +114,7 @@ type ModelRecord struct {
 	ID              string            `json:"id"`
 	Provider        Provider          `json:"provider"`
 	ProviderModelID string            `json:"providerModelId"`
+	Aliases         []string          `json:"aliases,omitempty"`
 	DisplayName     string            `json:"displayName,omitempty"`
 	Modalities      ModelModalities   `json:"modalities"`
 	Features        ModelFeatures     `json:"features"`
@@ -142,6 +158,7 @@ type ImageOutput struct {
 type Message struct {
 	Role       string        `json:"role"`
 	Content    []ContentPart `json:"content"`
+	ToolCalls  []ToolCall    `json:"toolCalls,omitempty"`
 	ToolCallID string        `json:"toolCallId,omitempty"`
 	Name       string        `json:"name,omitempty"`
 }
@@ -264,22 +281,37 @@ type CostBreakdown struct {
 	PricingPerMillion *TokenPrices `json:"pricing_per_million,omitempty"`
 }
 
+// SafetyRating is a provider's content-safety assessment (currently Gemini).
+type SafetyRating struct {
+	Category    string `json:"category"`
+	Probability string `json:"probability"`
+	Blocked     bool   `json:"blocked,omitempty"`
+}
+
 type GenerateOutput struct {
-	Text         string         `json:"text,omitempty"`
-	ToolCalls    []ToolCall     `json:"toolCalls,omitempty"`
-	Usage        *Usage         `json:"usage,omitempty"`
-	FinishReason string         `json:"finishReason,omitempty"`
-	Cost         *CostBreakdown `json:"cost,omitempty"`
-	Raw          interface{}    `json:"raw,omitempty"`
+	Text          string         `json:"text,omitempty"`
+	ToolCalls     []ToolCall     `json:"toolCalls,omitempty"`
+	Usage         *Usage         `json:"usage,omitempty"`
+	FinishReason  string         `json:"finishReason,omitempty"`
+	SafetyRatings []SafetyRating `json:"safetyRatings,omitempty"`
+	Cost          *CostBreakdown `json:"cost,omitempty"`
+	Raw           interface{}    `json:"raw,omitempty"`
 }
 
 type StreamChunkType string
 
 const (
-	StreamChunkDelta      StreamChunkType = "delta"
-	StreamChunkToolCall   StreamChunkType = "tool_call"
-	StreamChunkMessageEnd StreamChunkType = "message_end"
-	StreamChunkError      StreamChunkType = "error"
+	StreamChunkMessageStart  StreamChunkType = "message_start"
+	StreamChunkDelta         StreamChunkType = "delta"
+	StreamChunkToolCallStart StreamChunkType = "tool_call_start"
+	StreamChunkToolCallDelta StreamChunkType = "tool_call_delta"
+	StreamChunkToolCallEnd   StreamChunkType = "tool_call_end"
+	StreamChunkMessageEnd    StreamChunkType = "message_end"
+	StreamChunkError         StreamChunkType = "error"
+
+	// StreamChunkToolCall is the pre-normalization tool chunk. Adapters no
+	// longer emit it; consumers still accept it from custom adapters.
+	StreamChunkToolCall StreamChunkType = "tool_call"
 )
 
 type ChunkError struct {
@@ -289,19 +321,50 @@ type ChunkError struct {
 	RequestID    string `json:"requestId,omitempty"`
 }
 
+// StreamChunk is one event of a normalized stream. Every stream starts with
+// message_start (Model, RequestID) and ends with message_end. Each tool call
+// is reported as tool_call_start, tool_call_delta fragments and a final
+// tool_call_end with the complete arguments, all sharing the call's Index.
+// Index is only encoded on those chunks, where 0 is the first call.
+// Seq numbers chunks from 1 in the order Kit delivered them.
 type StreamChunk struct {
-	Type         StreamChunkType `json:"type"`
-	TextDelta    string          `json:"textDelta,omitempty"`
-	Call         *ToolCall       `json:"call,omitempty"`
-	Delta        string          `json:"delta,omitempty"`
-	Usage        *Usage          `json:"usage,omitempty"`
-	FinishReason string          `json:"finishReason,omitempty"`
-	Cost         *CostBreakdown  `json:"cost,omitempty"`
-	Error        *ChunkError     `json:"error,omitempty"`
+	Type          StreamChunkType `json:"type"`
+	Seq           int             `json:"seq,omitempty"`
+	Model         string          `json:"model,omitempty"`
+	RequestID     string          `json:"requestId,omitempty"`
+	TextDelta     string          `json:"textDelta,omitempty"`
+	Index         int             `json:"-"`
+	Call          *ToolCall       `json:"call,omitempty"`
+	Delta         string          `json:"delta,omitempty"`
+	Usage         *Usage          `json:"usage,omitempty"`
+	FinishReason  string          `json:"finishReason,omitempty"`
+	SafetyRatings []SafetyRating  `json:"safetyRatings,omitempty"`
+	Cost          *CostBreakdown  `json:"cost,omitempty"`
+	Error         *ChunkError     `json:"error,omitempty"`
 }
 
 type ListModelsOptions struct {
 	Providers   []Provider
 	Refresh     bool
 	Entitlement *EntitlementContext
+	// Partial returns the models of the providers that answered instead of
+	// failing the whole listing when some did not.
+	Partial bool
+	// ProviderTimeout bounds each provider's listing. Providers are listed
+	// concurrently, so it also bounds the whole call.
+	ProviderTimeout time.Duration
+}
+
+// ListModelsResult is a model listing together with the providers that
+// could not be listed.
+type ListModelsResult struct {
+	Models []ModelMetadata     `json:"models"`
+	Errors []ProviderListError `json:"errors,omitempty"`
+}
+
+type ProviderListError struct {
+	Provider     Provider `json:"provider"`
+	Kind         string   `json:"kind"`
+	Message      string   `json:"message"`
+	UpstreamCode string   `json:"upstreamCode,omitempty"`
 }
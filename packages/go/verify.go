@@ -0,0 +1,293 @@
+package aikit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// VerificationStatus is the outcome of probing one model.
+type VerificationStatus string
+
+const (
+	VerificationEntitled    VerificationStatus = "entitled"
+	VerificationUnavailable VerificationStatus = "unavailable"
+	// VerificationInconclusive means the probe failed for a reason that says
+	// nothing about entitlement: rate limits, timeouts, outages, or a
+	// minimal request the model rejects. Nothing is recorded.
+	VerificationInconclusive VerificationStatus = "inconclusive"
+	// VerificationSkipped means the model was not probed: it is not known to
+	// be a text model, it has no pricing to hold against the cost cap, or the
+	// run's probe or cost budget ran out.
+	VerificationSkipped VerificationStatus = "skipped"
+)
+
+const (
+	defaultProbeInterval = 500 * time.Millisecond
+	defaultProbeTimeout  = 30 * time.Second
+	// probeInputTokens over-estimates the probe prompt when checking a
+	// probe against the cost cap.
+	probeInputTokens = 16
+)
+
+// VerifyModelsOptions configures Kit.VerifyModels. Every probe is a real
+// one-token generation billed to the key it runs with; MaxProbes and
+// MaxCostUSD bound what a run may spend.
+type VerifyModelsOptions struct {
+	// Providers limits the run; empty means every configured provider.
+	Providers []Provider
+	// Models limits the run to these model ids, bare or "provider:model".
+	// Models whose text capability is unknown are only probed when named
+	// here, so a full run leaves embedding, speech and image models alone.
+	Models []string
+	// Entitlements are verified one after another with their own keys.
+	// Empty verifies the Kit's own configuration.
+	Entitlements []*EntitlementContext
+	// Interval is the minimum gap between probes (500ms when zero; negative
+	// disables the limit).
+	Interval time.Duration
+	// Timeout bounds each probe (30s when zero).
+	Timeout time.Duration
+	// MaxProbes stops probing after this many requests; zero is unlimited.
+	MaxProbes int
+	// MaxCostUSD skips any probe whose estimated cost would take the run
+	// over it. Models without catalog pricing are skipped while it is set,
+	// since nothing bounds what they cost.
+	MaxCostUSD float64
+}
+
+// ModelVerification is the outcome of one model under one key.
+type ModelVerification struct {
+	Key        RegistryKey        `json:"key"`
+	Model      string             `json:"model"`
+	Status     VerificationStatus `json:"status"`
+	Reason     string             `json:"reason,omitempty"`
+	CostUSD    float64            `json:"costUsd,omitempty"`
+	VerifiedAt time.Time          `json:"verifiedAt"`
+}
+
+type VerifyModelsResult struct {
+	Results []ModelVerification `json:"results"`
+	Probes  int                 `json:"probes"`
+	CostUSD float64             `json:"costUsd"`
+	// Errors lists providers whose model listing failed, so none of their
+	// models were probed.
+	Errors []ProviderListError `json:"errors,omitempty"`
+}
+
+// VerifyModels probes every listed model with a minimal request (one output
+// token) and records which ones each key can actually use. Entitled and
+// unavailable results show up in ListModelRecords with the "verified"
+// confidence and their LastVerifiedAt, so routing stops offering models
+// that fail, until a later probe or real request says otherwise. Probes run
+// sequentially; a cancelled ctx returns what was verified so far.
+func (h *Kit) VerifyModels(ctx context.Context, opts *VerifyModelsOptions) (VerifyModelsResult, error) {
+	if opts == nil {
+		opts = &VerifyModelsOptions{}
+	}
+	run := &verificationRun{kit: h, opts: *opts}
+	if run.opts.Interval == 0 {
+		run.opts.Interval = defaultProbeInterval
+	}
+	if run.opts.Timeout <= 0 {
+		run.opts.Timeout = defaultProbeTimeout
+	}
+	entitlements := opts.Entitlements
+	if len(entitlements) == 0 {
+		entitlements = []*EntitlementContext{nil}
+	}
+	for _, entitlement := range entitlements {
+		if err := run.verify(ctx, entitlement); err != nil {
+			return run.result, err
+		}
+	}
+	return run.result, nil
+}
+
+// ScheduleModelVerification runs VerifyModels immediately and then every
+// interval until ctx is done. Each run gets the full probe and cost budget
+// in opts.
+func (h *Kit) ScheduleModelVerification(ctx context.Context, interval time.Duration, opts *VerifyModelsOptions) error {
+	if interval <= 0 {
+		return fmt.Errorf("model verification interval must be positive")
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		h.VerifyModels(ctx, opts)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+type verificationRun struct {
+	kit       *Kit
+	opts      VerifyModelsOptions
+	result    VerifyModelsResult
+	lastProbe time.Time
+}
+
+func (v *verificationRun) verify(ctx context.Context, entitlement *EntitlementContext) error {
+	registry := v.kit.registry
+	entries, failures, err := registry.entriesForProviders(ctx, &ListModelsOptions{
+		Providers:   v.opts.Providers,
+		Entitlement: entitlement,
+		Partial:     true,
+	})
+	if err != nil {
+		return err
+	}
+	v.result.Errors = append(v.result.Errors, failures...)
+	providers := make([]Provider, 0, len(entries))
+	for provider := range entries {
+		providers = append(providers, provider)
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i] < providers[j] })
+	for _, provider := range providers {
+		for _, model := range entries[provider].Models {
+			if !v.wants(provider, model.ID) {
+				continue
+			}
+			verification := v.probe(ctx, provider, entitlement, registry.catalog.apply(model))
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			v.result.Results = append(v.result.Results, verification)
+		}
+	}
+	return nil
+}
+
+func (v *verificationRun) wants(provider Provider, model string) bool {
+	return len(v.opts.Models) == 0 || v.named(provider, model)
+}
+
+// named reports whether Models asks for model explicitly.
+func (v *verificationRun) named(provider Provider, model string) bool {
+	for _, want := range v.opts.Models {
+		if want == model || want == canonicalID(provider, model) {
+			return true
+		}
+	}
+	return false
+}
+
+// probe checks the budget, waits out the rate limit and sends one minimal
+// request, recording conclusive outcomes in the registry.
+func (v *verificationRun) probe(ctx context.Context, provider Provider, entitlement *EntitlementContext, model ModelMetadata) ModelVerification {
+	registry := v.kit.registry
+	key := registry.learnedKey(provider, entitlement, model.ID)
+	verification := ModelVerification{Key: key.RegistryKey, Model: model.ID, Status: VerificationSkipped}
+	text := model.Capabilities.Text
+	if text != nil && !*text {
+		verification.Reason = "not a text generation model"
+		return verification
+	}
+	if text == nil && !v.named(provider, model.ID) {
+		verification.Reason = "text generation unknown; name the model in Models to probe it"
+		return verification
+	}
+	if v.opts.MaxProbes > 0 && v.result.Probes >= v.opts.MaxProbes {
+		verification.Reason = "probe limit reached"
+		return verification
+	}
+	var estimate float64
+	cost := v.kit.catalog.estimateCost(provider, model.ID, &Usage{InputTokens: probeInputTokens, OutputTokens: 1})
+	if cost != nil {
+		estimate = cost.TotalCostUSD
+	}
+	if v.opts.MaxCostUSD > 0 {
+		if cost == nil {
+			verification.Reason = "no catalog pricing to check against the cost cap"
+			return verification
+		}
+		if v.result.CostUSD+estimate > v.opts.MaxCostUSD {
+			verification.Reason = "cost cap reached"
+			return verification
+		}
+	}
+	adapter, err := registry.adapterFor(provider, entitlement)
+	if err != nil {
+		verification.Status = VerificationInconclusive
+		verification.Reason = err.Error()
+		return verification
+	}
+	if err := v.wait(ctx); err != nil {
+		return verification
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, v.opts.Timeout)
+	defer cancel()
+	maxTokens := 1
+	output, err := adapter.Generate(probeCtx, GenerateInput{
+		Provider:  provider,
+		Model:     model.ID,
+		Messages:  []Message{{Role: "user", Content: []ContentPart{{Type: "text", Text: "hi"}}}},
+		MaxTokens: &maxTokens,
+	})
+	v.lastProbe = time.Now()
+	v.result.Probes++
+	verification.VerifiedAt = v.lastProbe
+	if err != nil {
+		reason, unavailable := probeUnavailable(err)
+		verification.Reason = reason
+		verification.Status = VerificationInconclusive
+		if unavailable {
+			verification.Status = VerificationUnavailable
+			registry.recordVerification(key, verifiedEntry{at: v.lastProbe, reason: reason})
+		}
+		return verification
+	}
+	verification.Status = VerificationEntitled
+	verification.CostUSD = estimate
+	if cost := v.kit.catalog.estimateCost(provider, model.ID, output.Usage); cost != nil {
+		verification.CostUSD = cost.TotalCostUSD
+	}
+	v.result.CostUSD += verification.CostUSD
+	registry.recordVerification(key, verifiedEntry{at: v.lastProbe, entitled: true})
+	return verification
+}
+
+// wait blocks until Interval has passed since the previous probe.
+func (v *verificationRun) wait(ctx context.Context) error {
+	if v.opts.Interval <= 0 || v.lastProbe.IsZero() {
+		return ctx.Err()
+	}
+	delay := time.Until(v.lastProbe.Add(v.opts.Interval))
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// probeUnavailable is stricter than learnReason: a probe's 400 more often
+// means the minimal request itself was rejected (a reasoning model wanting
+// more output tokens, say) than that the key cannot use the model, so only
+// not-found and permission errors count. OpenAI answers chat requests for
+// completion-only models with a 404 too, which says nothing about access.
+func probeUnavailable(err error) (string, bool) {
+	var kitErr *KitError
+	if !errors.As(err, &kitErr) {
+		return err.Error(), false
+	}
+	if strings.Contains(strings.ToLower(kitErr.Message), "not a chat model") {
+		return kitErr.Message, false
+	}
+	if kitErr.Kind == ErrorProviderNotFound || kitErr.UpstreamStatus == 403 || kitErr.UpstreamStatus == 404 {
+		return kitErr.Message, true
+	}
+	return kitErr.Message, false
+}
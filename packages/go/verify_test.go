@@ -0,0 +1,146 @@
+package aikit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+)
+
+// probeServer lists gpt-4, a model the key cannot use, a rate-limited one
+// and a completion-only one, and answers chat completions accordingly.
+func probeServer(t *testing.T, probes *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/models" {
+			w.Write([]byte(`{"data":[{"id":"gpt-4"},{"id":"gpt-secret"},{"id":"gpt-busy"},{"id":"davinci-002"}]}`))
+			return
+		}
+		probes.Add(1)
+		var body struct {
+			Model     string `json:"model"`
+			MaxTokens int    `json:"max_tokens"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.MaxTokens != 1 {
+			t.Errorf("expected a one-token probe, got max_tokens %d", body.MaxTokens)
+		}
+		switch body.Model {
+		case "gpt-secret":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"message":"The model does not exist or you do not have access to it.","code":"model_not_found"}}`))
+		case "gpt-busy":
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":{"message":"Rate limit reached"}}`))
+		case "davinci-002":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"message":"This is not a chat model and thus not supported in the v1/chat/completions endpoint. Did you mean to use v1/completions?"}}`))
+		default:
+			w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"H"},"finish_reason":"length"}],"usage":{"prompt_tokens":8,"completion_tokens":1}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVerifyModelsRecordsEntitlement(t *testing.T) {
+	var probes atomic.Int32
+	server := probeServer(t, &probes)
+	prices := fstest.MapFS{"openai/scraped_models.json": {Data: []byte(`[{"id": "gpt-4", "tokenPrices": {"input": 1000, "output": 1000}}]`)}}
+	kit, err := New(Config{OpenAI: &OpenAIConfig{APIKey: "k", BaseURL: server.URL}, Catalog: prices})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	result, err := kit.VerifyModels(context.Background(), &VerifyModelsOptions{Interval: -1})
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	statuses := map[string]VerificationStatus{}
+	for _, verification := range result.Results {
+		statuses[verification.Model] = verification.Status
+	}
+	if statuses["gpt-4"] != VerificationEntitled || statuses["gpt-secret"] != VerificationSkipped || statuses["davinci-002"] != VerificationSkipped {
+		t.Fatalf("expected only the known text model probed, got %+v", result.Results)
+	}
+	if result.Probes != 1 || result.CostUSD != 0.009 {
+		t.Fatalf("expected one probe with a priced cost, got %+v", result)
+	}
+
+	result, err = kit.VerifyModels(context.Background(), &VerifyModelsOptions{Interval: -1, Models: []string{"gpt-secret", "gpt-busy", "davinci-002"}})
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	for _, verification := range result.Results {
+		statuses[verification.Model] = verification.Status
+	}
+	if statuses["gpt-secret"] != VerificationUnavailable || statuses["gpt-busy"] != VerificationInconclusive || statuses["davinci-002"] != VerificationInconclusive {
+		t.Fatalf("unexpected outcomes for named models: %+v", result.Results)
+	}
+
+	records, err := kit.ListModelRecords(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("records: %v", err)
+	}
+	for _, record := range records {
+		availability := record.Availability
+		switch record.ProviderModelID {
+		case "gpt-4":
+			if !availability.Entitled || availability.Confidence != AvailabilityVerified || availability.LastVerifiedAt == "" {
+				t.Fatalf("expected gpt-4 verified, got %+v", availability)
+			}
+		case "gpt-secret":
+			if availability.Entitled || availability.Confidence != AvailabilityVerified || availability.Reason == "" {
+				t.Fatalf("expected gpt-secret verified unavailable, got %+v", availability)
+			}
+		case "gpt-busy", "davinci-002":
+			if !availability.Entitled || availability.Confidence != AvailabilityListed {
+				t.Fatalf("an inconclusive probe should record nothing, got %+v", availability)
+			}
+		}
+	}
+	resolved, err := (&ModelRouter{}).Resolve(records, ModelResolutionRequest{})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	for _, model := range append(resolved.Fallback, resolved.Primary) {
+		if model.ProviderModelID == "gpt-secret" {
+			t.Fatalf("router offered a model that failed verification")
+		}
+	}
+}
+
+func TestVerifyModelsBudgets(t *testing.T) {
+	var probes atomic.Int32
+	server := probeServer(t, &probes)
+	kit, err := New(Config{OpenAI: &OpenAIConfig{APIKey: "k", BaseURL: server.URL}})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	result, err := kit.VerifyModels(context.Background(), &VerifyModelsOptions{Interval: -1, MaxProbes: 1, Models: []string{"openai:gpt-secret", "gpt-busy"}})
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if probes.Load() != 1 || len(result.Results) != 2 || result.Results[1].Status != VerificationSkipped {
+		t.Fatalf("expected one probe and one skipped model, got %d probes: %+v", probes.Load(), result.Results)
+	}
+
+	result, err = kit.VerifyModels(context.Background(), &VerifyModelsOptions{Interval: -1, MaxCostUSD: 1e-9, Models: []string{"gpt-4"}})
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if probes.Load() != 1 || result.Results[0].Reason != "cost cap reached" {
+		t.Fatalf("expected the cost cap to skip gpt-4, got %+v", result.Results)
+	}
+
+	result, err = kit.VerifyModels(context.Background(), &VerifyModelsOptions{Interval: -1, MaxCostUSD: 1, Models: []string{"gpt-busy"}})
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if probes.Load() != 1 || result.Results[0].Status != VerificationSkipped {
+		t.Fatalf("expected an unpriced model skipped under a cost cap, got %+v", result.Results)
+	}
+}
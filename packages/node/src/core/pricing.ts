@@ -41,23 +41,26 @@ function loadCuratedModels(): CuratedModel[] {
         continue;
       }
       const provider = entry.name;
-      const filePath = path.join(modelsRoot(), provider, "scraped_models.json");
-      if (!fs.existsSync(filePath)) {
-        continue;
-      }
-      const raw = fs.readFileSync(filePath, "utf-8");
-      const parsed = JSON.parse(raw);
-      if (!Array.isArray(parsed)) {
-        continue;
-      }
-      for (const item of parsed) {
-        if (!item || typeof item !== "object") {
+      // curated_models.json is hand-maintained and overrides the scrape by id.
+      for (const fileName of ["scraped_models.json", "curated_models.json"]) {
+        const filePath = path.join(modelsRoot(), provider, fileName);
+        if (!fs.existsSync(filePath)) {
+          continue;
+        }
+        const raw = fs.readFileSync(filePath, "utf-8");
+        const parsed = JSON.parse(raw);
+        if (!Array.isArray(parsed)) {
           continue;
         }
-        if (!("provider" in item)) {
-          (item as CuratedModel).provider = provider as Provider;
+        for (const item of parsed) {
+          if (!item || typeof item !== "object") {
+            continue;
+          }
+          if (!("provider" in item)) {
+            (item as CuratedModel).provider = provider as Provider;
+          }
+          mergeCuratedModel(collected, item as CuratedModel);
         }
-        collected.push(item as CuratedModel);
       }
     }
   } catch {
@@ -67,6 +70,27 @@ function loadCuratedModels(): CuratedModel[] {
   return cachedCuratedModels;
 }
 
+function mergeCuratedModel(collected: CuratedModel[], item: CuratedModel): void {
+  const index = collected.findIndex(
+    (model) => model.provider === item.provider && model.id === item.id,
+  );
+  if (index < 0) {
+    collected.push(item);
+    return;
+  }
+  const existing = collected[index];
+  collected[index] = {
+    ...existing,
+    ...item,
+    capabilities: item.capabilities
+      ? { ...(existing.capabilities ?? {}), ...item.capabilities }
+      : existing.capabilities,
+    tokenPrices: item.tokenPrices
+      ? { ...(existing.tokenPrices ?? {}), ...item.tokenPrices }
+      : existing.tokenPrices,
+  };
+}
+
 function normalizeModelId(provider: Provider, modelId: string): string {
   const prefix = `${provider}/`;
   if (modelId.startsWith(prefix)) {
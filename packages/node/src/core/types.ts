@@ -11,12 +11,22 @@ export enum Provider {
 
 export type ProviderMap<T> = Partial<Record<Provider, T>>;
 
+/**
+ * A capability is omitted when neither the provider listing nor the curated
+ * catalog reports it: unknown, not unsupported. The Go server omits them.
+ */
 export interface ModelCapabilities {
-  text: boolean;
-  vision: boolean;
-  tool_use: boolean;
-  structured_output: boolean;
-  reasoning: boolean;
+  text?: boolean;
+  vision?: boolean;
+  image?: boolean;
+  audio_in?: boolean;
+  audio_out?: boolean;
+  tool_use?: boolean;
+  json_mode?: boolean;
+  structured_output?: boolean;
+  streaming?: boolean;
+  batch?: boolean;
+  reasoning?: boolean;
 }
 
 export interface TokenPrices {
@@ -48,7 +58,7 @@ export interface EntitlementContext {
 }
 
 export interface ModelModalities {
-  text: boolean;
+  text?: boolean;
   vision?: boolean;
   audioIn?: boolean;
   audioOut?: boolean;
@@ -26,26 +26,40 @@ def _load_scraped_from_dir(base_dir: Path) -> List[Dict[str, Any]]:
     for provider_dir in base_dir.iterdir():
         if not provider_dir.is_dir():
             continue
-        path = provider_dir / "scraped_models.json"
-        if not path.exists():
-            continue
-        try:
-            raw = path.read_text(encoding="utf-8")
-            data = json.loads(raw)
-        except Exception:
-            continue
-        if not isinstance(data, list):
-            continue
-        provider = provider_dir.name
-        for entry in data:
-            if not isinstance(entry, dict):
+        # curated_models.json is hand-maintained and overrides the scrape by id.
+        for name in ("scraped_models.json", "curated_models.json"):
+            path = provider_dir / name
+            if not path.exists():
+                continue
+            try:
+                raw = path.read_text(encoding="utf-8")
+                data = json.loads(raw)
+            except Exception:
                 continue
-            if "provider" not in entry:
-                entry = {**entry, "provider": provider}
-            models.append(entry)
+            if not isinstance(data, list):
+                continue
+            provider = provider_dir.name
+            for entry in data:
+                if not isinstance(entry, dict):
+                    continue
+                if "provider" not in entry:
+                    entry = {**entry, "provider": provider}
+                _merge_entry(models, entry)
     return models
 
 
+def _merge_entry(models: List[Dict[str, Any]], entry: Dict[str, Any]) -> None:
+    for index, existing in enumerate(models):
+        if existing.get("provider") == entry.get("provider") and existing.get("id") == entry.get("id"):
+            merged = {**existing, **entry}
+            for key in ("capabilities", "tokenPrices"):
+                if isinstance(existing.get(key), dict) and isinstance(entry.get(key), dict):
+                    merged[key] = {**existing[key], **entry[key]}
+            models[index] = merged
+            return
+    models.append(entry)
+
+
 def load_scraped_models() -> List[Dict[str, Any]]:
     global _scraped_cache
     if _scraped_cache is not None:
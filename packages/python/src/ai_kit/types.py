@@ -9,12 +9,15 @@ Provider = str
 
 @dataclass
 class ModelCapabilities:
-    text: bool
-    vision: bool
-    image: bool
-    tool_use: bool
-    structured_output: bool
-    reasoning: bool
+    """None means unknown: neither the provider listing nor the curated
+    catalog reports the capability. The Go server omits unknown ones."""
+
+    text: Optional[bool] = None
+    vision: Optional[bool] = None
+    image: Optional[bool] = None
+    tool_use: Optional[bool] = None
+    structured_output: Optional[bool] = None
+    reasoning: Optional[bool] = None
 
 
 @dataclass
@@ -51,7 +54,7 @@ class EntitlementContext:
 
 @dataclass
 class ModelModalities:
-    text: bool
+    text: Optional[bool] = None
     vision: Optional[bool] = None
     audioIn: Optional[bool] = None
     audioOut: Optional[bool] = None